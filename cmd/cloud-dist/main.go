@@ -8,6 +8,7 @@ import (
 	"net/http"
 	"time"
 
+	"cloud-dist/core/jobs"
 	"cloud-dist/core/router"
 	"cloud-dist/core/svc"
 	cfg "cloud-dist/internal/config"
@@ -33,10 +34,12 @@ func main() {
 			newLogger,
 			provideServiceContext,
 			provideGinEngine,
+			jobs.NewRunner,
 		),
 		fx.Invoke(
 			registerLoggerSync,
 			registerServiceShutdown,
+			registerJobs,
 			registerHTTPServer,
 		),
 	).Run()
@@ -53,11 +56,14 @@ func provideServiceContext(cfg cfg.Config) (*svc.ServiceContext, error) {
 	return svc.NewServiceContext(cfg)
 }
 
-func provideGinEngine(cfg cfg.Config, svcCtx *svc.ServiceContext) *gin.Engine {
+func provideGinEngine(cfg cfg.Config, svcCtx *svc.ServiceContext) (*gin.Engine, error) {
 	if cfg.Log.Mode != "console" {
 		gin.SetMode(gin.ReleaseMode)
 	}
-	engine := gin.New()
+	engine, err := router.NewEngine(cfg.HTTP.TrustedProxies)
+	if err != nil {
+		return nil, fmt.Errorf("trusted proxies: %w", err)
+	}
 
 	// Add logging middleware to log all requests
 	engine.Use(func(c *gin.Context) {
@@ -69,7 +75,7 @@ func provideGinEngine(cfg cfg.Config, svcCtx *svc.ServiceContext) *gin.Engine {
 	// Expose test static assets under /test for quick manual verification.
 	engine.Static("/test", "./test")
 	router.Register(engine, cfg.Name, svcCtx)
-	return engine
+	return engine, nil
 }
 
 func registerLoggerSync(lc fx.Lifecycle, logger *zap.Logger) {
@@ -88,6 +94,18 @@ func registerServiceShutdown(lc fx.Lifecycle, svcCtx *svc.ServiceContext) {
 	})
 }
 
+func registerJobs(lc fx.Lifecycle, runner *jobs.Runner) {
+	lc.Append(fx.Hook{
+		OnStart: func(ctx context.Context) error {
+			runner.Start()
+			return nil
+		},
+		OnStop: func(ctx context.Context) error {
+			return runner.Stop(ctx)
+		},
+	})
+}
+
 type serverParams struct {
 	fx.In
 
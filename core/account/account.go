@@ -0,0 +1,61 @@
+// Package account implements leaving the service: a full export of a user's
+// data and the deletion of their account.
+//
+// Both run from background jobs. They take a function to reach object storage
+// rather than calling S3 directly, so they can run against a fake in tests.
+package account
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strings"
+
+	"cloud-dist/core/models"
+
+	"gorm.io/gorm"
+)
+
+// ReleaseBlobs drops stored files that nothing references any more. A blob in
+// repository_pool is shared by every user_repository row with its identity,
+// through deduplicated uploads and saved shares, so it may only go when the
+// last of those rows is gone. remove deletes the object from storage.
+func ReleaseBlobs(ctx context.Context, db *gorm.DB, repositoryIdentities []string, remove func(key string) error) error {
+	seen := make(map[string]bool, len(repositoryIdentities))
+	for _, id := range repositoryIdentities {
+		if id == "" || seen[id] {
+			continue
+		}
+		seen[id] = true
+
+		var refs int64
+		if err := db.WithContext(ctx).Model(&models.UserRepository{}).
+			Where("repository_identity = ?", id).Count(&refs).Error; err != nil {
+			return err
+		}
+		if refs > 0 {
+			continue
+		}
+
+		rp := new(models.RepositoryPool)
+		err := db.WithContext(ctx).Where("identity = ?", id).First(rp).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if err = db.WithContext(ctx).Delete(rp).Error; err != nil {
+			return err
+		}
+		// Old rows stored a URL instead of a key; those objects cannot be removed
+		if rp.Path == "" || strings.HasPrefix(rp.Path, "http://") || strings.HasPrefix(rp.Path, "https://") {
+			continue
+		}
+		if err = remove(rp.Path); err != nil {
+			// The row is gone either way; an orphaned object only costs storage
+			log.Printf("[Account] Failed to delete object %s: %v", rp.Path, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,178 @@
+package account
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"log"
+
+	"cloud-dist/core/define"
+	"cloud-dist/core/models"
+
+	"gorm.io/gorm"
+)
+
+// Pseudonym replaces the user identity on records kept for bookkeeping. It is
+// stable, so one customer's orders still group together. It is keyed with the
+// server's JWT key, so someone who knows the old identity but not the key
+// cannot link the records back to it.
+func Pseudonym(userIdentity string) string {
+	mac := hmac.New(sha256.New, []byte(define.JwtKey))
+	mac.Write([]byte("deleted-user:" + userIdentity))
+	return "deleted:" + hex.EncodeToString(mac.Sum(nil)[:8])
+}
+
+// Delete removes a user and everything that belongs to them: files and the
+// spaces they own, share links, friends, friend requests and friend shares in
+// both directions, and their sign-in credentials. Storage orders are kept for
+// bookkeeping under a pseudonym. Database rows go in one transaction; blobs
+// are released afterwards, following ReleaseBlobs.
+func Delete(ctx context.Context, db *gorm.DB, userIdentity string, remove func(key string) error) error {
+	var blobs []string
+	var exports []string
+	err := db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// A new session, so each step below starts from a clean statement
+		tx = tx.Unscoped().Session(&gorm.Session{})
+
+		// Spaces the user owns go with them; their files are billed to the owner
+		var spaces []string
+		if err := tx.Model(&models.Space{}).Where("owner_identity = ?", userIdentity).
+			Pluck("identity", &spaces).Error; err != nil {
+			return err
+		}
+		spaceBlobs, err := deleteSpaces(tx, spaces)
+		if err != nil {
+			return err
+		}
+
+		if err = tx.Model(&models.UserRepository{}).Where("user_identity = ? AND repository_identity <> ''", userIdentity).
+			Pluck("repository_identity", &blobs).Error; err != nil {
+			return err
+		}
+		blobs = append(blobs, spaceBlobs...)
+		if err := tx.Model(&models.AccountExport{}).Where("user_identity = ? AND path <> ''", userIdentity).
+			Pluck("path", &exports).Error; err != nil {
+			return err
+		}
+
+		var shareLinks []string
+		if err := tx.Model(&models.ShareBasic{}).Where("user_identity = ?", userIdentity).
+			Pluck("identity", &shareLinks).Error; err != nil {
+			return err
+		}
+		if len(shareLinks) > 0 {
+			if err := tx.Where("share_identity IN ?", shareLinks).Delete(&models.ShareAccessLog{}).Error; err != nil {
+				return err
+			}
+		}
+
+		steps := []struct {
+			model any
+			query string
+			args  []any
+		}{
+			{&models.UserRepository{}, "user_identity = ?", []any{userIdentity}},
+			{&models.ShareBasic{}, "user_identity = ?", []any{userIdentity}},
+			{&models.Friend{}, "user_identity = ? OR friend_identity = ?", []any{userIdentity, userIdentity}},
+			{&models.FriendRequest{}, "from_user_identity = ? OR to_user_identity = ?", []any{userIdentity, userIdentity}},
+			{&models.FriendShare{}, "from_user_identity = ? OR to_user_identity = ?", []any{userIdentity, userIdentity}},
+			{&models.ShareInvite{}, "from_user_identity = ?", []any{userIdentity}},
+			{&models.SpaceMember{}, "user_identity = ?", []any{userIdentity}},
+			{&models.SpaceInvite{}, "user_identity = ? OR inviter_identity = ?", []any{userIdentity, userIdentity}},
+			{&models.Notification{}, "user_identity = ?", []any{userIdentity}},
+			{&models.NotificationPreference{}, "user_identity = ?", []any{userIdentity}},
+			{&models.UserSession{}, "user_identity = ?", []any{userIdentity}},
+			{&models.PersonalAccessToken{}, "user_identity = ?", []any{userIdentity}},
+			{&models.WebAuthnCredential{}, "user_identity = ?", []any{userIdentity}},
+			{&models.UserRecoveryCode{}, "user_identity = ?", []any{userIdentity}},
+			{&models.UserExternalIdentity{}, "user_identity = ?", []any{userIdentity}},
+			{&models.EmailChange{}, "user_identity = ?", []any{userIdentity}},
+			{&models.LoginLockout{}, "user_identity = ?", []any{userIdentity}},
+			{&models.AccountExport{}, "user_identity = ?", []any{userIdentity}},
+		}
+		for _, s := range steps {
+			if err := tx.Where(s.query, s.args...).Delete(s.model).Error; err != nil {
+				return err
+			}
+		}
+
+		if err := tx.Model(&models.StorageOrder{}).Where("user_identity = ?", userIdentity).
+			Update("user_identity", Pseudonym(userIdentity)).Error; err != nil {
+			return err
+		}
+		return tx.Where("identity = ?", userIdentity).Delete(&models.UserBasic{}).Error
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, key := range exports {
+		if err := remove(key); err != nil {
+			log.Printf("[Account] Failed to delete export %s: %v", key, err)
+		}
+	}
+	return ReleaseBlobs(ctx, db, blobs, remove)
+}
+
+// DeleteSpace removes a team space with its members, pending invites, files,
+// and every share link and friend share made from those files. Blobs are
+// released afterwards, following ReleaseBlobs.
+func DeleteSpace(ctx context.Context, db *gorm.DB, spaceIdentity string, remove func(key string) error) error {
+	var blobs []string
+	err := db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var err error
+		blobs, err = deleteSpaces(tx.Unscoped().Session(&gorm.Session{}), []string{spaceIdentity})
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	return ReleaseBlobs(ctx, db, blobs, remove)
+}
+
+// deleteSpaces deletes the spaces' rows inside tx and returns the blobs their
+// files referenced
+func deleteSpaces(tx *gorm.DB, spaces []string) ([]string, error) {
+	if len(spaces) == 0 {
+		return nil, nil
+	}
+	var blobs []string
+	if err := tx.Model(&models.UserRepository{}).Where("user_identity IN ? AND repository_identity <> ''", spaces).
+		Pluck("repository_identity", &blobs).Error; err != nil {
+		return nil, err
+	}
+
+	// Share links are made by members, so find them through the files
+	files := tx.Model(&models.UserRepository{}).Select("identity").Where("user_identity IN ?", spaces)
+	var shareLinks []string
+	if err := tx.Model(&models.ShareBasic{}).Where("user_repository_identity IN (?)", files).
+		Pluck("identity", &shareLinks).Error; err != nil {
+		return nil, err
+	}
+	if len(shareLinks) > 0 {
+		if err := tx.Where("share_identity IN ?", shareLinks).Delete(&models.ShareAccessLog{}).Error; err != nil {
+			return nil, err
+		}
+		if err := tx.Where("identity IN ?", shareLinks).Delete(&models.ShareBasic{}).Error; err != nil {
+			return nil, err
+		}
+	}
+
+	steps := []struct {
+		model any
+		query string
+	}{
+		{&models.FriendShare{}, "space_identity IN ?"},
+		{&models.SpaceInvite{}, "space_identity IN ?"},
+		{&models.SpaceMember{}, "space_identity IN ?"},
+		{&models.UserRepository{}, "user_identity IN ?"},
+		{&models.Space{}, "identity IN ?"},
+	}
+	for _, s := range steps {
+		if err := tx.Where(s.query, spaces).Delete(s.model).Error; err != nil {
+			return nil, err
+		}
+	}
+	return blobs, nil
+}
@@ -0,0 +1,372 @@
+package account
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+	"time"
+
+	"cloud-dist/core/models"
+
+	"gorm.io/gorm"
+)
+
+// Exported records carry their own JSON shape, so secrets such as the password
+// hash or TOTP secret can never leak into an archive by accident.
+
+type exportProfile struct {
+	Identity            string    `json:"identity"`
+	Name                string    `json:"name"`
+	Email               string    `json:"email"`
+	UsedBytes           int64     `json:"used_bytes"`
+	TotalBytes          int64     `json:"total_bytes"`
+	DiscoverableByName  bool      `json:"discoverable_by_name"`
+	DiscoverableByEmail bool      `json:"discoverable_by_email"`
+	TwoFactorEnabled    bool      `json:"two_factor_enabled"`
+	CreatedAt           time.Time `json:"created_at"`
+}
+
+type exportShareLink struct {
+	Identity      string    `json:"identity"`
+	File          string    `json:"file"`
+	ExpiredTime   int       `json:"expired_time"`
+	ClickNum      int       `json:"click_num"`
+	DownloadLimit int       `json:"download_limit"`
+	DownloadNum   int       `json:"download_num"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+type exportFriendShare struct {
+	Identity   string     `json:"identity"`
+	From       string     `json:"from"`
+	To         string     `json:"to"`
+	File       string     `json:"file,omitempty"` // Path in this archive, for shares sent
+	Message    string     `json:"message"`
+	Permission string     `json:"permission"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+type exportShares struct {
+	Links    []exportShareLink   `json:"links"`
+	Sent     []exportFriendShare `json:"sent"`
+	Received []exportFriendShare `json:"received"`
+}
+
+type exportFriend struct {
+	Identity  string    `json:"identity"`
+	Name      string    `json:"name"`
+	Status    string    `json:"status"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type exportFriendRequest struct {
+	From      string    `json:"from"`
+	To        string    `json:"to"`
+	Status    string    `json:"status"`
+	Message   string    `json:"message"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type exportFriends struct {
+	Friends  []exportFriend        `json:"friends"`
+	Requests []exportFriendRequest `json:"requests"`
+}
+
+type exportOrder struct {
+	Identity      string    `json:"identity"`
+	StorageAmount int64     `json:"storage_bytes"`
+	PriceAmount   int64     `json:"price_cents"`
+	Currency      string    `json:"currency"`
+	Status        string    `json:"status"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// WriteArchive writes a zip of the user's files under files/, in their
+// folder structure, plus profile.json, shares.json, friends.json and
+// orders.json. open reads a stored object by key.
+func WriteArchive(ctx context.Context, db *gorm.DB, userIdentity string, w io.Writer, open func(key string) (io.ReadCloser, error)) error {
+	db = db.WithContext(ctx)
+	user := new(models.UserBasic)
+	if err := db.Where("identity = ?", userIdentity).First(user).Error; err != nil {
+		return err
+	}
+
+	zw := zip.NewWriter(w)
+	paths, err := writeFiles(ctx, db, zw, userIdentity, open)
+	if err != nil {
+		return err
+	}
+
+	profile := exportProfile{
+		Identity:            user.Identity,
+		Name:                user.Name,
+		Email:               user.Email,
+		UsedBytes:           user.NowVolume,
+		TotalBytes:          user.TotalVolume,
+		DiscoverableByName:  user.DiscoverableByName,
+		DiscoverableByEmail: user.DiscoverableByEmail,
+		TwoFactorEnabled:    user.TOTPEnabled,
+		CreatedAt:           user.CreatedAt,
+	}
+	if err = writeJSON(zw, "profile.json", profile); err != nil {
+		return err
+	}
+
+	names, err := userNames(db, userIdentity)
+	if err != nil {
+		return err
+	}
+
+	shares := exportShares{Links: []exportShareLink{}, Sent: []exportFriendShare{}, Received: []exportFriendShare{}}
+	var links []*models.ShareBasic
+	if err = db.Where("user_identity = ?", userIdentity).Order("id").Find(&links).Error; err != nil {
+		return err
+	}
+	for _, s := range links {
+		shares.Links = append(shares.Links, exportShareLink{
+			Identity:      s.Identity,
+			File:          paths[s.UserRepositoryIdentity],
+			ExpiredTime:   s.ExpiredTime,
+			ClickNum:      s.ClickNum,
+			DownloadLimit: s.DownloadLimit,
+			DownloadNum:   s.DownloadNum,
+			CreatedAt:     s.CreatedAt,
+		})
+	}
+	var friendShares []*models.FriendShare
+	if err = db.Where("from_user_identity = ? OR to_user_identity = ?", userIdentity, userIdentity).
+		Order("id").Find(&friendShares).Error; err != nil {
+		return err
+	}
+	for _, s := range friendShares {
+		item := exportFriendShare{
+			Identity:   s.Identity,
+			From:       names.of(s.FromUserIdentity),
+			To:         names.of(s.ToUserIdentity),
+			Message:    s.Message,
+			Permission: s.Permission,
+			ExpiresAt:  s.ExpiresAt,
+			CreatedAt:  s.CreatedAt,
+		}
+		if s.FromUserIdentity == userIdentity {
+			item.File = paths[s.UserRepositoryIdentity]
+			shares.Sent = append(shares.Sent, item)
+		} else {
+			shares.Received = append(shares.Received, item)
+		}
+	}
+	if err = writeJSON(zw, "shares.json", shares); err != nil {
+		return err
+	}
+
+	friends := exportFriends{Friends: []exportFriend{}, Requests: []exportFriendRequest{}}
+	var rows []*models.Friend
+	if err = db.Where("user_identity = ?", userIdentity).Order("id").Find(&rows).Error; err != nil {
+		return err
+	}
+	for _, f := range rows {
+		friends.Friends = append(friends.Friends, exportFriend{
+			Identity:  f.FriendIdentity,
+			Name:      names.of(f.FriendIdentity),
+			Status:    f.Status,
+			CreatedAt: f.CreatedAt,
+		})
+	}
+	var requests []*models.FriendRequest
+	if err = db.Where("from_user_identity = ? OR to_user_identity = ?", userIdentity, userIdentity).
+		Order("id").Find(&requests).Error; err != nil {
+		return err
+	}
+	for _, r := range requests {
+		friends.Requests = append(friends.Requests, exportFriendRequest{
+			From:      names.of(r.FromUserIdentity),
+			To:        names.of(r.ToUserIdentity),
+			Status:    r.Status,
+			Message:   r.Message,
+			CreatedAt: r.CreatedAt,
+		})
+	}
+	if err = writeJSON(zw, "friends.json", friends); err != nil {
+		return err
+	}
+
+	orders := []exportOrder{}
+	var orderRows []*models.StorageOrder
+	if err = db.Where("user_identity = ?", userIdentity).Order("id").Find(&orderRows).Error; err != nil {
+		return err
+	}
+	for _, o := range orderRows {
+		orders = append(orders, exportOrder{
+			Identity:      o.Identity,
+			StorageAmount: o.StorageAmount,
+			PriceAmount:   o.PriceAmount,
+			Currency:      o.Currency,
+			Status:        o.Status,
+			CreatedAt:     o.CreatedAt,
+		})
+	}
+	if err = writeJSON(zw, "orders.json", orders); err != nil {
+		return err
+	}
+	return zw.Close()
+}
+
+// writeFiles adds every file and folder under files/ and returns the archive
+// path of each user_repository identity
+func writeFiles(ctx context.Context, db *gorm.DB, zw *zip.Writer, userIdentity string, open func(key string) (io.ReadCloser, error)) (map[string]string, error) {
+	var rows []*models.UserRepository
+	if err := db.Where("user_identity = ?", userIdentity).Order("id").Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	byID := make(map[int64]*models.UserRepository, len(rows))
+	for _, r := range rows {
+		byID[r.ID] = r
+	}
+
+	// Resolve paths parent first; a sibling with the same name gets a suffix
+	paths := make(map[string]string, len(rows))
+	taken := make(map[string]bool, len(rows))
+	var resolve func(r *models.UserRepository, depth int) string
+	resolve = func(r *models.UserRepository, depth int) string {
+		if p, ok := paths[r.Identity]; ok {
+			return p
+		}
+		dir := "files"
+		if parent, ok := byID[r.ParentId]; ok && depth < 64 {
+			dir = resolve(parent, depth+1)
+		}
+		p := uniquePath(dir, safeName(r.Name), taken)
+		taken[p] = true
+		paths[r.Identity] = p
+		return p
+	}
+
+	var blobs []string
+	for _, r := range rows {
+		resolve(r, 0)
+		if r.RepositoryIdentity != "" {
+			blobs = append(blobs, r.RepositoryIdentity)
+		}
+	}
+	pool := make(map[string]*models.RepositoryPool)
+	if len(blobs) > 0 {
+		var rps []*models.RepositoryPool
+		if err := db.Where("identity IN ?", blobs).Find(&rps).Error; err != nil {
+			return nil, err
+		}
+		for _, rp := range rps {
+			pool[rp.Identity] = rp
+		}
+	}
+
+	for _, r := range rows {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		p := paths[r.Identity]
+		if r.RepositoryIdentity == "" {
+			if _, err := zw.CreateHeader(&zip.FileHeader{Name: p + "/", Modified: r.UpdatedAt}); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		rp, ok := pool[r.RepositoryIdentity]
+		if !ok || rp.Path == "" {
+			continue
+		}
+		if err := copyObject(zw, p, r.UpdatedAt, rp.Path, open); err != nil {
+			return nil, fmt.Errorf("export %s: %w", p, err)
+		}
+	}
+	return paths, nil
+}
+
+func copyObject(zw *zip.Writer, name string, modified time.Time, key string, open func(key string) (io.ReadCloser, error)) error {
+	body, err := open(key)
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+	fw, err := zw.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Deflate, Modified: modified})
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(fw, body)
+	return err
+}
+
+func writeJSON(zw *zip.Writer, name string, v any) error {
+	fw, err := zw.Create(name)
+	if err != nil {
+		return err
+	}
+	enc := json.NewEncoder(fw)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+// safeName keeps a stored name from escaping its folder in the archive
+func safeName(name string) string {
+	name = strings.Map(func(r rune) rune {
+		if r == '/' || r == '\\' || r < 0x20 {
+			return '_'
+		}
+		return r
+	}, name)
+	if name == "" || name == "." || name == ".." {
+		return "_"
+	}
+	return name
+}
+
+func uniquePath(dir, name string, taken map[string]bool) string {
+	p := path.Join(dir, name)
+	if !taken[p] {
+		return p
+	}
+	ext := path.Ext(name)
+	base := strings.TrimSuffix(name, ext)
+	for i := 2; ; i++ {
+		p = path.Join(dir, fmt.Sprintf("%s (%d)%s", base, i, ext))
+		if !taken[p] {
+			return p
+		}
+	}
+}
+
+// nameBook maps user identities to names for readable JSON
+type nameBook map[string]string
+
+func (b nameBook) of(identity string) string {
+	if n, ok := b[identity]; ok {
+		return n
+	}
+	return identity
+}
+
+// userNames loads the names of everyone the user has a friend, request or
+// share in common with
+func userNames(db *gorm.DB, userIdentity string) (nameBook, error) {
+	var users []*models.UserBasic
+	err := db.Select("identity", "name").Where(
+		"identity IN (?) OR identity IN (?) OR identity IN (?) OR identity IN (?) OR identity IN (?)",
+		db.Model(&models.Friend{}).Select("friend_identity").Where("user_identity = ?", userIdentity),
+		db.Model(&models.FriendRequest{}).Select("from_user_identity").Where("to_user_identity = ?", userIdentity),
+		db.Model(&models.FriendRequest{}).Select("to_user_identity").Where("from_user_identity = ?", userIdentity),
+		db.Model(&models.FriendShare{}).Select("from_user_identity").Where("to_user_identity = ?", userIdentity),
+		db.Model(&models.FriendShare{}).Select("to_user_identity").Where("from_user_identity = ?", userIdentity),
+	).Or("identity = ?", userIdentity).Find(&users).Error
+	if err != nil {
+		return nil, err
+	}
+	book := make(nameBook, len(users))
+	for _, u := range users {
+		book[u.Identity] = u.Name
+	}
+	return book, nil
+}
@@ -0,0 +1,129 @@
+// Package audit records security and data events in the append-only
+// audit_log table and exports them as CSV.
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+
+	"cloud-dist/core/helper"
+	"cloud-dist/core/models"
+
+	"gorm.io/gorm"
+)
+
+// Actions
+const (
+	ActionLogin          = "login"
+	ActionLoginFailed    = "login.failed"
+	ActionTokenRefresh   = "token.refresh"
+	ActionPasswordChange = "password.change"
+	ActionPasswordReset  = "password.reset"
+	ActionEmailChange    = "email.change"
+	ActionEmailRestore   = "email.restore"
+
+	ActionTwoFactorEnable    = "2fa.enable"
+	ActionTwoFactorDisable   = "2fa.disable"
+	ActionRecoveryCodesRenew = "2fa.recovery_codes"
+	ActionPasskeyAdd         = "passkey.add"
+	ActionPasskeyRemove      = "passkey.remove"
+
+	ActionAccessTokenCreate = "access_token.create"
+	ActionAccessTokenRevoke = "access_token.revoke"
+
+	ActionAccountDelete       = "account.delete"
+	ActionAccountDeleteCancel = "account.delete_cancel"
+
+	ActionFileCreate = "file.create"
+	ActionFileRename = "file.rename"
+	ActionFileMove   = "file.move"
+	ActionFileDelete = "file.delete"
+
+	ActionShareCreate = "share.create"
+	ActionShareAccess = "share.access"
+
+	ActionFriendRequest = "friend.request"
+	ActionFriendAccept  = "friend.accept"
+	ActionFriendDecline = "friend.decline"
+	ActionFriendRemove  = "friend.remove"
+	ActionFriendBlock   = "friend.block"
+	ActionFriendUnblock = "friend.unblock"
+
+	ActionPayment = "payment"
+)
+
+// Target types
+const (
+	TargetUser    = "user"
+	TargetFile    = "file"
+	TargetShare   = "share"
+	TargetSession = "session"
+	TargetPasskey = "passkey"
+	TargetToken   = "access_token"
+	TargetOrder   = "order"
+)
+
+// Entry is one event. Actor defaults to the signed-in caller of the request.
+// Subject defaults to Actor; set it when the event concerns another account,
+// such as a visit to someone's share link or a file in a team space.
+// A non-empty Reason marks a failed attempt.
+type Entry struct {
+	Actor      string
+	Subject    string
+	Action     string
+	TargetType string
+	Target     string
+	Reason     string
+	Detail     map[string]interface{}
+}
+
+type detailKey struct{}
+
+// WithDetail returns a context whose recorded entries all carry detail, for
+// logic that runs on behalf of another flow, such as a friend changing a
+// shared folder through the owner's file logic
+func WithDetail(ctx context.Context, detail map[string]interface{}) context.Context {
+	return context.WithValue(ctx, detailKey{}, detail)
+}
+
+// Record appends e to the audit log, with the IP and user agent of the request
+// in ctx. Failures are logged only, so that auditing never blocks the user.
+func Record(ctx context.Context, db *gorm.DB, e Entry) {
+	meta := helper.ClientMetaFromContext(ctx)
+	row := &models.AuditLog{
+		ActorIdentity:   e.Actor,
+		SubjectIdentity: e.Subject,
+		Action:          e.Action,
+		TargetType:      e.TargetType,
+		TargetIdentity:  e.Target,
+		Success:         e.Reason == "",
+		Reason:          e.Reason,
+		IP:              meta.IP,
+		UserAgent:       meta.UserAgent,
+	}
+	if row.ActorIdentity == "" {
+		row.ActorIdentity = meta.UserIdentity
+	}
+	if row.SubjectIdentity == "" {
+		row.SubjectIdentity = row.ActorIdentity
+	}
+	detail := e.Detail
+	if extra, ok := ctx.Value(detailKey{}).(map[string]interface{}); ok && len(extra) > 0 {
+		detail = make(map[string]interface{}, len(extra)+len(e.Detail))
+		for k, v := range extra {
+			detail[k] = v
+		}
+		for k, v := range e.Detail {
+			detail[k] = v
+		}
+	}
+	if len(detail) > 0 {
+		if raw, err := json.Marshal(detail); err == nil {
+			row.Detail = string(raw)
+		}
+	}
+	if err := db.WithContext(ctx).Create(row).Error; err != nil {
+		log.Printf("[Audit] Failed to record %s by %q on %s %s: %v", e.Action, e.Actor, e.TargetType, e.Target, err)
+	}
+}
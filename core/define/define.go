@@ -1,32 +1,125 @@
 package define
 
 import (
+	"errors"
 	"log"
 	"os"
+	"strings"
+	"time"
 
 	"github.com/golang-jwt/jwt/v5"
 )
 
 type UserClaim struct {
-	Id       int
-	Identity string
-	Name     string
+	Id              int
+	Identity        string
+	Name            string
+	SessionIdentity string `json:",omitempty"` // UserSession the token belongs to
+	TokenType       string `json:",omitempty"` // access, refresh
+	TokenVersion    int64  `json:",omitempty"` // User's token generation when the token was issued
 	jwt.RegisteredClaims
 }
 
+// Token types carried in UserClaim.TokenType
+const (
+	TokenTypeAccess  = "access"
+	TokenTypeRefresh = "refresh"
+)
+
+// SessionRevokedPrefix marks revoked sessions in Redis, so AuthMiddleware can
+// reject their access tokens before they expire
+var SessionRevokedPrefix = "session:revoked:"
+
+// EventTicketPrefix stores the single-use tickets that open an event stream,
+// so that browsers need not put an access token in the URL
+var EventTicketPrefix = "events:ticket:"
+
+// EventTicketTTL is how long a stream ticket can be redeemed
+const EventTicketTTL = 30 * time.Second
+
 var JwtKey = os.Getenv("JWT_KEY")
 var SendGridAPIKey = os.Getenv("SendGridAPIKey")
 var SendGridFromEmail = os.Getenv("SendGridFromEmail")
 
-// InitJWTConfig initializes JWT key from config struct.
+// DevJwtKey is the JWT secret used when none is configured in development mode
+const DevJwtKey = "cloud-dist-key"
+
+// JwtKeyRotation is how long a JWT signing key signs before a new one replaces it
+var JwtKeyRotation = 30 * 24 * time.Hour
+
+// InitJWTConfig initializes the JWT secret from config struct. The secret
+// encrypts the signing keys at rest and is the default share token secret.
 // Environment variables take precedence over config file values.
-func InitJWTConfig(jwtKey string) {
+// Outside development mode a missing or default secret is an error.
+func InitJWTConfig(jwtKey string, rotationDays int, development bool) error {
 	if JwtKey == "" && jwtKey != "" {
 		JwtKey = jwtKey
 	}
-	// Fallback to default if still empty (for development only)
-	if JwtKey == "" {
-		JwtKey = "cloud-dist-key" // Default for development
+	if JwtKey == "" || JwtKey == DevJwtKey {
+		if !development {
+			return errors.New("JWT key is not set or is the development default; set JWT_KEY or JWT.Key")
+		}
+		JwtKey = DevJwtKey
+		log.Printf("[InitJWTConfig] Using the development JWT key, do not use this in production")
+	}
+	if rotationDays > 0 {
+		JwtKeyRotation = time.Duration(rotationDays) * 24 * time.Hour
+	}
+	return nil
+}
+
+// FrontendBaseURL is the web app origin used in links sent by email
+var FrontendBaseURL = os.Getenv("FRONTEND_BASE_URL")
+
+// InitFrontendConfig initializes the frontend base URL from config struct.
+// Environment variables take precedence over config file values.
+func InitFrontendConfig(baseURL string) {
+	if FrontendBaseURL == "" {
+		FrontendBaseURL = baseURL
+	}
+	if FrontendBaseURL == "" {
+		FrontendBaseURL = "http://localhost:3000"
+	}
+	FrontendBaseURL = strings.TrimRight(FrontendBaseURL, "/")
+}
+
+// ShareInviteExpireDays default lifetime of an email share invite
+var ShareInviteExpireDays = 7
+
+// Share download configuration
+var ShareDownloadMode = os.Getenv("SHARE_DOWNLOAD_MODE")
+var ShareDownloadTokenSecret = os.Getenv("SHARE_DOWNLOAD_TOKEN_SECRET")
+var ShareDownloadTokenTTL = 900 // seconds
+
+// SharePresignTTL bounds how long a redirect URL keeps working once the share
+// is revoked or taken down. The client follows the redirect immediately, and
+// S3 only checks expiry when a request starts.
+const SharePresignTTL = 60 * time.Second
+
+// Share download modes
+const (
+	ShareDownloadModeProxy     = "proxy"     // Stream the object through the backend
+	ShareDownloadModePresigned = "presigned" // Redirect to a short-lived presigned S3 URL
+)
+
+// InitShareConfig initializes share download settings from config struct.
+// Environment variables take precedence over config file values.
+// Must be called after InitJWTConfig, whose key is the default token secret.
+func InitShareConfig(mode, tokenSecret string, tokenTTLSeconds int) {
+	if ShareDownloadMode == "" {
+		ShareDownloadMode = mode
+	}
+	if ShareDownloadMode != ShareDownloadModePresigned {
+		ShareDownloadMode = ShareDownloadModeProxy
+	}
+	if ShareDownloadTokenSecret == "" && tokenSecret != "" {
+		ShareDownloadTokenSecret = tokenSecret
+	}
+	if ShareDownloadTokenSecret == "" {
+		ShareDownloadTokenSecret = JwtKey
+	}
+	if tokenTTLSeconds > 0 {
+		ShareDownloadTokenTTL = tokenTTLSeconds
 	}
 }
 
@@ -41,8 +134,8 @@ var AWSAccessKeyID = os.Getenv("AWSAccessKeyID")
 var AWSSecretAccessKey = os.Getenv("AWSSecretAccessKey")
 var S3Bucket = os.Getenv("S3Bucket")
 var S3Region = os.Getenv("AWSRegion")
-var S3Endpoint = os.Getenv("S3Endpoint")                             // Optional custom endpoint
-var S3UseAcceleration = os.Getenv("S3UseAcceleration") == "true"     // Enable S3 Transfer Acceleration
+var S3Endpoint = os.Getenv("S3Endpoint")                         // Optional custom endpoint
+var S3UseAcceleration = os.Getenv("S3UseAcceleration") == "true" // Enable S3 Transfer Acceleration
 
 // InitS3Config initializes S3 configuration from config struct.
 // Environment variables take precedence over config file values.
@@ -149,3 +242,72 @@ func GetStoragePrice(storageBytes int64) int64 {
 		return price
 	}
 }
+
+// Team space member roles, from least to most privileged
+const (
+	SpaceRoleViewer = "viewer"
+	SpaceRoleEditor = "editor"
+	SpaceRoleOwner  = "owner"
+)
+
+// SpaceInviteExpireDays lifetime of an invitation to join a team space
+var SpaceInviteExpireDays = 7
+
+// SpaceRoleAllows reports whether role grants at least the permissions of required
+func SpaceRoleAllows(role, required string) bool {
+	rank := map[string]int{SpaceRoleViewer: 1, SpaceRoleEditor: 2, SpaceRoleOwner: 3}
+	return rank[role] > 0 && rank[role] >= rank[required]
+}
+
+// Account roles for the admin API, from least to most privileged. Support
+// staff can look things up and log users out; admins can change accounts.
+// The first admin is promoted directly in user_basic.role.
+const (
+	UserRoleUser    = "user"
+	UserRoleSupport = "support"
+	UserRoleAdmin   = "admin"
+)
+
+// UserRoleAllows reports whether role grants at least the permissions of required
+func UserRoleAllows(role, required string) bool {
+	rank := map[string]int{UserRoleUser: 1, UserRoleSupport: 2, UserRoleAdmin: 3}
+	return rank[role] > 0 && rank[role] >= rank[required]
+}
+
+// Personal access token scopes
+const (
+	ScopeFilesRead    = "files:read"
+	ScopeFilesWrite   = "files:write"
+	ScopeSharesManage = "shares:manage"
+	ScopeFriends      = "friends"
+)
+
+// AccessTokenScopes lists every scope a personal access token may carry
+var AccessTokenScopes = []string{ScopeFilesRead, ScopeFilesWrite, ScopeSharesManage, ScopeFriends}
+
+// AccessTokenPrefix starts every personal access token, so AuthMiddleware can
+// tell them from JWTs and secret scanners can find leaked ones
+const AccessTokenPrefix = "cdp_"
+
+// Account export states
+const (
+	ExportStatusPending = "pending"
+	ExportStatusRunning = "running"
+	ExportStatusReady   = "ready"
+	ExportStatusFailed  = "failed"
+	ExportStatusExpired = "expired"
+)
+
+// ExportRetention is how long a finished export can be downloaded
+const ExportRetention = 7 * 24 * time.Hour
+
+// AccountDeletionGrace is how long a deletion request can still be cancelled
+const AccountDeletionGrace = 14 * 24 * time.Hour
+
+// Rate limit plans. Anonymous callers are keyed by IP; a signed-in user is on
+// the paid plan once they have bought storage.
+const (
+	RatePlanAnonymous = "anonymous"
+	RatePlanFree      = "free"
+	RatePlanPaid      = "paid"
+)
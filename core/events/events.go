@@ -0,0 +1,160 @@
+// Package events delivers typed, per-user events to connected clients.
+//
+// Every event is appended to a capped Redis stream per user, which provides the
+// event ID and replay after a reconnect, and then published on a per-user
+// channel so that whichever server instance holds the user's connection can
+// push it immediately.
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// Event types. All but UploadCompleted are also stored as notifications.
+const (
+	FriendRequestReceived  = "friend_request.received"
+	FriendRequestResponded = "friend_request.responded"
+	FriendShareReceived    = "friend_share.received"
+	ShareInviteReceived    = "share_invite.received"
+	SpaceInviteReceived    = "space_invite.received"
+	StorageOrderPaid       = "storage_order.paid"
+	StorageOrderFailed     = "storage_order.failed"
+	QuotaThreshold         = "quota.threshold"
+	ShareLinkActivity      = "share_link.activity"
+	UploadCompleted        = "upload.completed"
+)
+
+const (
+	streamMaxLen = 500                // Events kept per user for replay
+	streamTTL    = 7 * 24 * time.Hour // Idle streams expire
+	keyPrefix    = "events:user:"
+)
+
+// Event is a single notification for one user
+type Event struct {
+	ID        string          `json:"id"`
+	Type      string          `json:"type"`
+	Data      json.RawMessage `json:"data"`
+	CreatedAt int64           `json:"created_at"` // Unix milliseconds
+}
+
+type Bus struct {
+	rdb *redis.Client
+}
+
+func NewBus(rdb *redis.Client) *Bus {
+	return &Bus{rdb: rdb}
+}
+
+func key(userIdentity string) string {
+	return keyPrefix + userIdentity
+}
+
+// Publish stores the event for replay and fans it out to live subscribers
+func (b *Bus) Publish(ctx context.Context, userIdentity, eventType string, data interface{}) error {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	now := time.Now().UnixMilli()
+	id, err := b.rdb.XAdd(ctx, &redis.XAddArgs{
+		Stream: key(userIdentity),
+		MaxLen: streamMaxLen,
+		Approx: true,
+		Values: map[string]interface{}{
+			"type":       eventType,
+			"data":       string(payload),
+			"created_at": now,
+		},
+	}).Result()
+	if err != nil {
+		return err
+	}
+	b.rdb.Expire(ctx, key(userIdentity), streamTTL)
+
+	msg, err := json.Marshal(&Event{ID: id, Type: eventType, Data: payload, CreatedAt: now})
+	if err != nil {
+		return err
+	}
+	return b.rdb.Publish(ctx, key(userIdentity), msg).Err()
+}
+
+// Emit publishes an event and only logs failures, for callers where the
+// notification must never fail the main operation
+func (b *Bus) Emit(ctx context.Context, userIdentity, eventType string, data interface{}) {
+	if err := b.Publish(ctx, userIdentity, eventType, data); err != nil {
+		log.Printf("[Events] Failed to publish %s for %s: %v", eventType, userIdentity, err)
+	}
+}
+
+// Replay returns the stored events that came after lastID, oldest first
+func (b *Bus) Replay(ctx context.Context, userIdentity, lastID string) ([]*Event, error) {
+	msgs, err := b.rdb.XRange(ctx, key(userIdentity), lastID, "+").Result()
+	if err != nil {
+		return nil, err
+	}
+	list := make([]*Event, 0, len(msgs))
+	for _, m := range msgs {
+		if m.ID == lastID {
+			continue
+		}
+		e := &Event{ID: m.ID}
+		e.Type, _ = m.Values["type"].(string)
+		if data, ok := m.Values["data"].(string); ok {
+			e.Data = json.RawMessage(data)
+		}
+		if ts, ok := m.Values["created_at"].(string); ok {
+			e.CreatedAt, _ = strconv.ParseInt(ts, 10, 64)
+		}
+		list = append(list, e)
+	}
+	return list, nil
+}
+
+// Subscribe opens a live subscription to the user's events. The caller must
+// close the returned PubSub.
+func (b *Bus) Subscribe(ctx context.Context, userIdentity string) (*redis.PubSub, error) {
+	sub := b.rdb.Subscribe(ctx, key(userIdentity))
+	// Wait for the subscription to be confirmed so no event published after
+	// this call returns can be missed
+	if _, err := sub.Receive(ctx); err != nil {
+		sub.Close()
+		return nil, err
+	}
+	return sub, nil
+}
+
+// ValidID reports whether id looks like a stream ID ("<ms>-<seq>")
+func ValidID(id string) bool {
+	ms, seq, ok := strings.Cut(id, "-")
+	if !ok {
+		return false
+	}
+	_, err1 := strconv.ParseUint(ms, 10, 64)
+	_, err2 := strconv.ParseUint(seq, 10, 64)
+	return err1 == nil && err2 == nil
+}
+
+// After reports whether stream ID a comes after stream ID b
+func After(a, b string) bool {
+	if b == "" {
+		return true
+	}
+	ams, aseq, _ := strings.Cut(a, "-")
+	bms, bseq, _ := strings.Cut(b, "-")
+	am, _ := strconv.ParseUint(ams, 10, 64)
+	bm, _ := strconv.ParseUint(bms, 10, 64)
+	if am != bm {
+		return am > bm
+	}
+	as, _ := strconv.ParseUint(aseq, 10, 64)
+	bs, _ := strconv.ParseUint(bseq, 10, 64)
+	return as > bs
+}
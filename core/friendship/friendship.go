@@ -0,0 +1,27 @@
+// Package friendship holds the rules for rows of the friend table that the
+// friend endpoints share.
+package friendship
+
+import (
+	"cloud-dist/core/models"
+
+	"gorm.io/gorm"
+)
+
+// Friend row states. Each user has their own row towards the other.
+const (
+	StatusActive  = "active"
+	StatusBlocked = "blocked"
+)
+
+// Sever ends what userIdentity has with otherIdentity. The caller's own row
+// goes in any state; the other user's row goes unless it is a block, which
+// only its owner may lift.
+func Sever(tx *gorm.DB, userIdentity, otherIdentity string) error {
+	if err := tx.Where("user_identity = ? AND friend_identity = ?", userIdentity, otherIdentity).
+		Delete(&models.Friend{}).Error; err != nil {
+		return err
+	}
+	return tx.Where("user_identity = ? AND friend_identity = ? AND status <> ?", otherIdentity, userIdentity, StatusBlocked).
+		Delete(&models.Friend{}).Error
+}
@@ -0,0 +1,23 @@
+package helper
+
+import "context"
+
+// ClientMeta describes the HTTP client behind a request.
+type ClientMeta struct {
+	IP           string
+	UserAgent    string
+	UserIdentity string // Authenticated caller, filled in by the auth middleware
+}
+
+type clientMetaKey struct{}
+
+// WithClientMeta stores client details on the request context.
+func WithClientMeta(ctx context.Context, meta ClientMeta) context.Context {
+	return context.WithValue(ctx, clientMetaKey{}, meta)
+}
+
+// ClientMetaFromContext returns the client details stored by WithClientMeta.
+func ClientMetaFromContext(ctx context.Context) ClientMeta {
+	meta, _ := ctx.Value(clientMetaKey{}).(ClientMeta)
+	return meta
+}
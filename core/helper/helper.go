@@ -3,13 +3,17 @@ package helper
 import (
 	"bytes"
 	"cloud-dist/core/define"
+	"cloud-dist/core/keyring"
 	"context"
 	"crypto/md5"
+	"crypto/rand"
 	"errors"
 	"fmt"
+	"html"
 	"io"
 	"log"
-	"math/rand"
+	"math/big"
+	"mime"
 	"net/http"
 	"path"
 	"strconv"
@@ -46,29 +50,31 @@ func CheckPasswordHash(password, hash string) bool {
 }
 
 func GenerateToken(id int, identity, name string, second int) (string, error) {
-	uc := define.UserClaim{
-		Id:       id,
-		Identity: identity,
-		Name:     name,
-		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Second * time.Duration(second))),
-		},
-	}
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, uc)
-	tokenString, err := token.SignedString([]byte(define.JwtKey))
-	if err != nil {
-		return "", err
-	}
-	return tokenString, nil
+	return GenerateClaimsToken(define.UserClaim{Id: id, Identity: identity, Name: name}, second)
+}
+
+// GenerateClaimsToken signs uc with the key ring's active key after setting its
+// expiry. Session tokens put their jti in uc.ID, which refresh rotation uses to
+// spot reused refresh tokens.
+func GenerateClaimsToken(uc define.UserClaim, second int) (string, error) {
+	ring := keyring.Default()
+	if ring == nil {
+		return "", errors.New("JWT key ring is not initialized")
+	}
+	uc.IssuedAt = jwt.NewNumericDate(time.Now())
+	uc.ExpiresAt = jwt.NewNumericDate(time.Now().Add(time.Second * time.Duration(second)))
+	return ring.Sign(uc)
 }
 
 // AnalyzeToken
 // Token parsing
 func AnalyzeToken(token string) (*define.UserClaim, error) {
+	ring := keyring.Default()
+	if ring == nil {
+		return nil, errors.New("JWT key ring is not initialized")
+	}
 	uc := new(define.UserClaim)
-	claims, err := jwt.ParseWithClaims(token, uc, func(token *jwt.Token) (interface{}, error) {
-		return []byte(define.JwtKey), nil
-	})
+	claims, err := jwt.ParseWithClaims(token, uc, ring.Keyfunc, jwt.WithValidMethods([]string{keyring.Algorithm}))
 	if err != nil {
 		return nil, err
 	}
@@ -81,6 +87,96 @@ func AnalyzeToken(token string) (*define.UserClaim, error) {
 // MailSendCode
 // Send email verification code
 func MailSendCode(emailAddr, code string) error {
+	subject := "CloudDist Verification Code"
+	plain := "Your verification code is: " + code
+	html := fmt.Sprintf("Your verification code is: <h1>%s</h1>", code)
+	if err := MailSend(emailAddr, subject, plain, html); err != nil {
+		return err
+	}
+	log.Printf("[MailCodeSend] Verification code sent successfully to: %s", emailAddr)
+	return nil
+}
+
+// MailSendShareInvite tells a non-user that a file was shared with them and links to registration
+func MailSendShareInvite(emailAddr, fromName, fileName, message, link string) error {
+	subject := fromName + " shared a file with you on CloudDist"
+	plain := fmt.Sprintf("%s shared \"%s\" with you.\n", fromName, fileName)
+	if message != "" {
+		plain += message + "\n"
+	}
+	plain += "Create your account to open it: " + link
+	body := fmt.Sprintf("<p>%s shared <b>%s</b> with you.</p>", html.EscapeString(fromName), html.EscapeString(fileName))
+	if message != "" {
+		body += fmt.Sprintf("<p>%s</p>", html.EscapeString(message))
+	}
+	body += fmt.Sprintf("<p><a href=\"%s\">Create your account</a> to open it.</p>", html.EscapeString(link))
+	if err := MailSend(emailAddr, subject, plain, body); err != nil {
+		return err
+	}
+	log.Printf("[MailShareInvite] Invite sent successfully to: %s", emailAddr)
+	return nil
+}
+
+// MailSendNotification sends a notification center entry by email
+func MailSendNotification(emailAddr, title, body string) error {
+	content := fmt.Sprintf("<h3>%s</h3><p>%s</p>", html.EscapeString(title), html.EscapeString(body))
+	return MailSend(emailAddr, "CloudDist: "+title, body, content)
+}
+
+// MailSendLoginLockout warns the owner that failed logins locked their account
+// and, when a link is given, lets them lift the lock early
+func MailSendLoginLockout(emailAddr, name, ip, link string, until time.Time) error {
+	subject := "CloudDist: your account was temporarily locked"
+	unlockPlain, unlockHTML := "", ""
+	if link != "" {
+		unlockPlain = fmt.Sprintf("If this was you, unlock your account now: %s\n", link)
+		unlockHTML = fmt.Sprintf("<p>If this was you, <a href=\"%s\">unlock your account now</a>.</p>", html.EscapeString(link))
+	}
+	plain := fmt.Sprintf("Hi %s,\nThere were too many failed sign-in attempts on your account from %s, "+
+		"so sign-in is blocked until %s.\n%sIf this was not you, change your password and turn on two-factor authentication.",
+		name, ip, until.Format(define.Datetime), unlockPlain)
+	body := fmt.Sprintf("<p>Hi %s,</p><p>There were too many failed sign-in attempts on your account from <b>%s</b>, "+
+		"so sign-in is blocked until %s.</p>%s<p>If this was not you, change your password and turn on two-factor authentication.</p>",
+		html.EscapeString(name), html.EscapeString(ip), until.Format(define.Datetime), unlockHTML)
+	return MailSend(emailAddr, subject, plain, body)
+}
+
+// MailSendLoginLink sends a one-time sign-in link together with a code for
+// signing in on another device
+func MailSendLoginLink(emailAddr, code, link string) error {
+	subject := "CloudDist sign-in link"
+	plain := fmt.Sprintf("Sign in to CloudDist: %s\nOr enter this code: %s\nIf you did not ask to sign in, ignore this email.", link, code)
+	body := fmt.Sprintf("<p><a href=\"%s\">Sign in to CloudDist</a></p><p>Or enter this code: <h1>%s</h1></p>"+
+		"<p>If you did not ask to sign in, ignore this email.</p>", html.EscapeString(link), code)
+	return MailSend(emailAddr, subject, plain, body)
+}
+
+// MailSendEmailChanged tells the old address that the account email changed
+// and links to undo it
+func MailSendEmailChanged(emailAddr, newEmail, link string, until time.Time) error {
+	subject := "CloudDist: your account email was changed"
+	plain := fmt.Sprintf("The email of your CloudDist account was changed to %s.\n"+
+		"If this was not you, undo the change before %s: %s", newEmail, until.Format(define.Datetime), link)
+	body := fmt.Sprintf("<p>The email of your CloudDist account was changed to <b>%s</b>.</p>"+
+		"<p>If this was not you, <a href=\"%s\">undo the change</a> before %s.</p>",
+		html.EscapeString(newEmail), html.EscapeString(link), until.Format(define.Datetime))
+	return MailSend(emailAddr, subject, plain, body)
+}
+
+// MailSendAccountDeletion confirms that the account will be deleted at until
+// unless the user signs in and cancels
+func MailSendAccountDeletion(emailAddr, name string, until time.Time) error {
+	subject := "CloudDist: your account is scheduled for deletion"
+	plain := fmt.Sprintf("Hi %s,\nyour CloudDist account and all its files will be deleted on %s.\n"+
+		"To keep it, sign in and cancel the deletion before then.", name, until.Format(define.Datetime))
+	body := fmt.Sprintf("<p>Hi %s,</p><p>your CloudDist account and all its files will be deleted on <b>%s</b>.</p>"+
+		"<p>To keep it, sign in and cancel the deletion before then.</p>",
+		html.EscapeString(name), until.Format(define.Datetime))
+	return MailSend(emailAddr, subject, plain, body)
+}
+
+// MailSend sends an email with plain text and HTML bodies through SendGrid
+func MailSend(emailAddr, subject, plain, html string) error {
 	apiKey := define.SendGridAPIKey
 	if apiKey == "" {
 		return errors.New("SendGrid API key is not configured (please set environment variable SendGridAPIKey)")
@@ -93,33 +189,34 @@ func MailSendCode(emailAddr, code string) error {
 
 	from := sgmail.NewEmail("CloudDist", fromEmail)
 	to := sgmail.NewEmail("", emailAddr)
-	subject := "CloudDist Verification Code"
-	plain := "Your verification code is: " + code
-	html := fmt.Sprintf("Your verification code is: <h1>%s</h1>", code)
 	message := sgmail.NewSingleEmail(from, subject, to, plain, html)
 
 	client := sendgrid.NewSendClient(apiKey)
 	resp, err := client.Send(message)
 	if err != nil {
-		log.Printf("[MailCodeSend] SendGrid send failed: %v", err)
+		log.Printf("[MailSend] SendGrid send failed: %v", err)
 		return fmt.Errorf("SendGrid send failed: %v", err)
 	}
 	if resp.StatusCode >= 400 {
-		log.Printf("[MailCodeSend] SendGrid API error: status=%d body=%s", resp.StatusCode, resp.Body)
+		log.Printf("[MailSend] SendGrid API error: status=%d body=%s", resp.StatusCode, resp.Body)
 		return fmt.Errorf("SendGrid API error: status=%d body=%s", resp.StatusCode, resp.Body)
 	}
-	log.Printf("[MailCodeSend] Verification code sent successfully to: %s", emailAddr)
 	return nil
 }
 
+// RandCode returns a numeric verification code from crypto/rand, so codes
+// cannot be predicted from the time they were sent
 func RandCode() string {
-	s := "1234567890"
-	code := ""
-	rand.Seed(time.Now().UnixNano())
-	for i := 0; i < define.CodeLength; i++ {
-		code += string(s[rand.Intn(len(s))])
+	ten := big.NewInt(10)
+	code := make([]byte, define.CodeLength)
+	for i := range code {
+		n, err := rand.Int(rand.Reader, ten)
+		if err != nil {
+			panic(err) // crypto/rand does not fail on supported platforms
+		}
+		code[i] = byte('0' + n.Int64())
 	}
-	return code
+	return string(code)
 }
 
 func UUID() string {
@@ -181,10 +278,26 @@ func S3PresignedURL(key string, expiresInHours int) string {
 	return S3PresignedURLWithDisposition(key, expiresInHours, "")
 }
 
+// ContentDisposition builds a Content-Disposition header value. The filename is
+// quoted or RFC 2231 encoded as needed, so names with quotes, line breaks or
+// non-ASCII characters cannot break the header.
+func ContentDisposition(disposition, filename string) string {
+	if v := mime.FormatMediaType(disposition, map[string]string{"filename": filename}); v != "" {
+		return v
+	}
+	return disposition
+}
+
 // S3PresignedURLWithDisposition generates a presigned URL with optional Content-Disposition header
 // expiresIn: expiration time in hours (e.g., 72 for 3 days)
 // filename: if provided, sets ResponseContentDisposition to force download
 func S3PresignedURLWithDisposition(key string, expiresInHours int, filename string) string {
+	return S3PresignedURLWithExpiry(key, time.Duration(expiresInHours)*time.Hour, filename)
+}
+
+// S3PresignedURLWithExpiry generates a presigned URL valid for the given duration
+// filename: if provided, sets ResponseContentDisposition to force download
+func S3PresignedURLWithExpiry(key string, expires time.Duration, filename string) string {
 	ctx := context.Background()
 	client, err := getS3Client(ctx)
 	if err != nil {
@@ -207,12 +320,11 @@ func S3PresignedURLWithDisposition(key string, expiresInHours int, filename stri
 
 	// Add ResponseContentDisposition if filename is provided (for force download)
 	if filename != "" {
-		disposition := fmt.Sprintf("attachment; filename=\"%s\"", filename)
-		getObjectInput.ResponseContentDisposition = aws.String(disposition)
+		getObjectInput.ResponseContentDisposition = aws.String(ContentDisposition("attachment", filename))
 	}
 
 	presignedURL, err := presignClient.PresignGetObject(ctx, getObjectInput, func(opts *s3.PresignOptions) {
-		opts.Expires = time.Duration(expiresInHours) * time.Hour
+		opts.Expires = expires
 	})
 
 	if err != nil {
@@ -225,7 +337,7 @@ func S3PresignedURLWithDisposition(key string, expiresInHours int, filename stri
 		return fmt.Sprintf("https://%s.s3.%s.amazonaws.com/%s", define.S3Bucket, region, key)
 	}
 
-	log.Printf("[S3PresignedURL] Successfully generated presigned URL: key=%s, expiresIn=%d hours, filename=%s", key, expiresInHours, filename)
+	log.Printf("[S3PresignedURL] Successfully generated presigned URL: key=%s, expiresIn=%s, filename=%s", key, expires, filename)
 	return presignedURL.URL
 }
 
@@ -408,6 +520,42 @@ func S3Download(key string) (io.ReadCloser, *s3.HeadObjectOutput, error) {
 	return getResp.Body, headResp, nil
 }
 
+// S3GetObject fetches an object from S3, optionally limited to an HTTP Range
+// (e.g., "bytes=0-1023"). The caller must close the returned body.
+func S3GetObject(key, byteRange string) (*s3.GetObjectOutput, error) {
+	ctx := context.Background()
+	client, err := getS3Client(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	input := &s3.GetObjectInput{
+		Bucket: aws.String(define.S3Bucket),
+		Key:    aws.String(key),
+	}
+	if byteRange != "" {
+		input.Range = aws.String(byteRange)
+	}
+	return client.GetObject(ctx, input)
+}
+
+// S3PutObject uploads body under key; used for files the server produces itself
+func S3PutObject(key string, body io.Reader, size int64, contentType string) error {
+	ctx := context.Background()
+	client, err := getS3Client(ctx)
+	if err != nil {
+		return err
+	}
+	_, err = client.PutObject(ctx, &s3.PutObjectInput{
+		Bucket:        aws.String(define.S3Bucket),
+		Key:           aws.String(key),
+		Body:          body,
+		ContentLength: aws.Int64(size),
+		ContentType:   aws.String(contentType),
+	})
+	return err
+}
+
 // S3Delete deletes a file from S3
 func S3Delete(key string) error {
 	ctx := context.Background()
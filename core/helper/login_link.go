@@ -0,0 +1,31 @@
+package helper
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+)
+
+// NewLinkToken returns a random token for one-time links sent by email
+func NewLinkToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// HashLinkToken hashes a link token; only the hash is stored
+func HashLinkToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// NewLoginLink returns a numeric code and a link token for passwordless login
+func NewLoginLink() (code, token string, err error) {
+	if token, err = NewLinkToken(); err != nil {
+		return "", "", err
+	}
+	return RandCode(), token, nil
+}
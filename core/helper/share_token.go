@@ -0,0 +1,46 @@
+package helper
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"cloud-dist/core/define"
+)
+
+// SignShareToken issues a download token for the given share that expires at expiresAt.
+// The token has the form "<unix expiry>.<signature>".
+func SignShareToken(shareIdentity string, expiresAt time.Time) string {
+	exp := strconv.FormatInt(expiresAt.Unix(), 10)
+	return exp + "." + shareTokenSignature(shareIdentity, exp)
+}
+
+// VerifyShareToken checks that token was issued for shareIdentity and has not expired.
+func VerifyShareToken(shareIdentity, token string) error {
+	exp, sig, ok := strings.Cut(token, ".")
+	if !ok {
+		return errors.New("invalid download token")
+	}
+	expected := shareTokenSignature(shareIdentity, exp)
+	if !hmac.Equal([]byte(sig), []byte(expected)) {
+		return errors.New("invalid download token")
+	}
+	unix, err := strconv.ParseInt(exp, 10, 64)
+	if err != nil {
+		return errors.New("invalid download token")
+	}
+	if time.Now().Unix() > unix {
+		return errors.New("download token has expired")
+	}
+	return nil
+}
+
+func shareTokenSignature(shareIdentity, exp string) string {
+	mac := hmac.New(sha256.New, []byte(define.ShareDownloadTokenSecret))
+	mac.Write([]byte("share:" + shareIdentity + ":" + exp))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
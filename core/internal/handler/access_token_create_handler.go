@@ -0,0 +1,29 @@
+package handler
+
+import (
+	"net/http"
+
+	"cloud-dist/core/internal/logic"
+	"cloud-dist/core/internal/types"
+	"cloud-dist/core/svc"
+
+	"github.com/gin-gonic/gin"
+)
+
+func AccessTokenCreateHandler(svcCtx *svc.ServiceContext) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req types.AccessTokenCreateRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		l := logic.NewAccessTokenCreateLogic(c.Request.Context(), svcCtx)
+		resp, err := l.AccessTokenCreate(&req, c.GetString("UserIdentity"))
+		if err != nil {
+			respondError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, resp)
+	}
+}
@@ -0,0 +1,29 @@
+package handler
+
+import (
+	"net/http"
+
+	"cloud-dist/core/internal/logic"
+	"cloud-dist/core/internal/types"
+	"cloud-dist/core/svc"
+
+	"github.com/gin-gonic/gin"
+)
+
+func AccountDeleteCancelHandler(svcCtx *svc.ServiceContext) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req types.AccountDeleteCancelRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		l := logic.NewAccountDeleteCancelLogic(c.Request.Context(), svcCtx)
+		resp, err := l.AccountDeleteCancel(&req, c.GetString("UserIdentity"))
+		if err != nil {
+			respondError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, resp)
+	}
+}
@@ -0,0 +1,37 @@
+package handler
+
+import (
+	"log"
+	"net/http"
+
+	"cloud-dist/core/internal/logic"
+	"cloud-dist/core/internal/types"
+	"cloud-dist/core/svc"
+
+	"github.com/gin-gonic/gin"
+)
+
+func AdminAuditLogExportHandler(svcCtx *svc.ServiceContext) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req types.AdminAuditLogExportRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		l := logic.NewAdminAuditLogExportLogic(c.Request.Context(), svcCtx)
+		result, err := l.AdminAuditLogExport(&req, c.GetString("UserIdentity"))
+		if err != nil {
+			respondError(c, err)
+			return
+		}
+
+		c.Header("Content-Disposition", "attachment; filename=\""+result.FileName+"\"")
+		c.Header("Content-Type", "text/csv; charset=utf-8")
+		c.Header("Cache-Control", "private, no-store")
+		c.Status(http.StatusOK)
+		if _, err = result.WriteCSV(c.Writer); err != nil {
+			log.Printf("[AdminAuditLogExportHandler] Failed to write export: %v", err)
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package handler
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"cloud-dist/core/define"
+	"cloud-dist/core/events"
+	"cloud-dist/core/internal/logic"
+	"cloud-dist/core/svc"
+
+	"github.com/gin-gonic/gin"
+)
+
+// EventStreamHandler serves the caller's events as Server-Sent Events.
+// Reconnecting clients resume from the Last-Event-ID header (or the
+// last_event_id query parameter); browsers fetch a new ticket first, as
+// each one opens a single stream.
+func EventStreamHandler(svcCtx *svc.ServiceContext) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userIdentity := c.GetString("UserIdentity")
+		value, _ := c.Get("TokenClaim")
+		claim, _ := value.(*define.UserClaim)
+		lastEventID := c.GetHeader("Last-Event-ID")
+		if lastEventID == "" {
+			lastEventID = c.Query("last_event_id")
+		}
+
+		// The stream outlives the server's write timeout
+		if err := http.NewResponseController(c.Writer).SetWriteDeadline(time.Time{}); err != nil {
+			log.Printf("[EventStream] Failed to clear write deadline: %v", err)
+		}
+
+		c.Header("Content-Type", "text/event-stream")
+		c.Header("Cache-Control", "no-cache")
+		c.Header("Connection", "keep-alive")
+		c.Header("X-Accel-Buffering", "no")
+		c.Status(http.StatusOK)
+		c.Writer.Flush()
+
+		send := func(e *events.Event) error {
+			var err error
+			if e == nil {
+				_, err = fmt.Fprint(c.Writer, ": ping\n\n")
+			} else {
+				_, err = fmt.Fprintf(c.Writer, "id: %s\nevent: %s\ndata: %s\n\n", e.ID, e.Type, e.Data)
+			}
+			if err != nil {
+				return err
+			}
+			c.Writer.Flush()
+			return nil
+		}
+
+		l := logic.NewEventStreamLogic(c.Request.Context(), svcCtx)
+		if err := l.EventStream(claim, lastEventID, send); err != nil {
+			log.Printf("[EventStream] Stream for %s ended: %v", userIdentity, err)
+		}
+	}
+}
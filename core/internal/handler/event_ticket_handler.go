@@ -0,0 +1,32 @@
+package handler
+
+import (
+	"net/http"
+
+	"cloud-dist/core/define"
+	"cloud-dist/core/internal/logic"
+	"cloud-dist/core/internal/types"
+	"cloud-dist/core/svc"
+
+	"github.com/gin-gonic/gin"
+)
+
+func EventTicketHandler(svcCtx *svc.ServiceContext) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req types.EventTicketRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		value, _ := c.Get("TokenClaim")
+		claim, _ := value.(*define.UserClaim)
+		l := logic.NewEventTicketLogic(c.Request.Context(), svcCtx)
+		resp, err := l.EventTicket(&req, claim)
+		if err != nil {
+			respondError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, resp)
+	}
+}
@@ -41,7 +41,7 @@ func FileDownloadHandler(svcCtx *svc.ServiceContext) gin.HandlerFunc {
 		log.Printf("[FileDownloadHandler] Requesting download for repository: %s", repositoryIdentity)
 
 		l := logic.NewFileDownloadLogic(c.Request.Context(), svcCtx)
-		fileData, fileName, contentType, err := l.FileDownload(repositoryIdentity, userIdentity)
+		fileData, fileName, contentType, err := l.FileDownload(repositoryIdentity, c.GetString("OwnerIdentity"))
 		if err != nil {
 			log.Printf("[FileDownloadHandler] Failed to download file: %v", err)
 			// Check if it's an access denied error
@@ -20,11 +20,11 @@ func FileUploadChunkCompleteHandler(svcCtx *svc.ServiceContext) gin.HandlerFunc
 			return
 		}
 
-		userIdentity := c.GetString("UserIdentity")
+		billingIdentity := c.GetString("BillingIdentity")
 		ub := new(models.UserBasic)
 		err := svcCtx.DB.WithContext(c.Request.Context()).
 			Select("now_volume", "total_volume").
-			Where("identity = ?", userIdentity).First(ub).Error
+			Where("identity = ?", billingIdentity).First(ub).Error
 		if err != nil {
 			respondError(c, err)
 			return
@@ -40,6 +40,7 @@ func FileUploadChunkCompleteHandler(svcCtx *svc.ServiceContext) gin.HandlerFunc
 			respondError(c, err)
 			return
 		}
+		emitUploadCompleted(c, svcCtx, resp.Identity, req.Name)
 		c.JSON(http.StatusOK, resp)
 	}
 }
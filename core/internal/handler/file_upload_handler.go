@@ -7,6 +7,7 @@ import (
 	"net/http"
 	"path"
 
+	"cloud-dist/core/events"
 	"cloud-dist/core/helper"
 	"cloud-dist/core/internal/logic"
 	"cloud-dist/core/svc"
@@ -30,13 +31,14 @@ func FileUploadHandler(svcCtx *svc.ServiceContext) gin.HandlerFunc {
 		}
 		log.Printf("[FileUpload] File info: filename=%s, size=%d bytes", fileHeader.Filename, fileHeader.Size)
 
-		userIdentity := c.GetString("UserIdentity")
-		log.Printf("[FileUpload] User identity: %s", userIdentity)
+		// Space uploads are billed to the space owner
+		billingIdentity := c.GetString("BillingIdentity")
+		log.Printf("[FileUpload] Billing identity: %s", billingIdentity)
 
 		ub := new(models.UserBasic)
 		err = svcCtx.DB.WithContext(c.Request.Context()).
 			Select("now_volume", "total_volume").
-			Where("identity = ?", userIdentity).First(ub).Error
+			Where("identity = ?", billingIdentity).First(ub).Error
 		if err != nil {
 			log.Printf("[FileUpload] Failed to query user capacity: %v", err)
 			respondError(c, err)
@@ -64,6 +66,7 @@ func FileUploadHandler(svcCtx *svc.ServiceContext) gin.HandlerFunc {
 		err = svcCtx.DB.WithContext(c.Request.Context()).Where("hash = ?", hash).First(rp).Error
 		if err == nil {
 			log.Printf("[FileUpload] File already exists (instant upload): identity=%s", rp.Identity)
+			emitUploadCompleted(c, svcCtx, rp.Identity, rp.Name)
 			c.JSON(http.StatusOK, &types.FileUploadReply{Identity: rp.Identity, Ext: rp.Ext, Name: rp.Name})
 			return
 		}
@@ -99,6 +102,7 @@ func FileUploadHandler(svcCtx *svc.ServiceContext) gin.HandlerFunc {
 			return
 		}
 		log.Printf("[FileUpload] File upload completed: identity=%s", resp.Identity)
+		emitUploadCompleted(c, svcCtx, resp.Identity, resp.Name)
 
 		// Don't automatically save to user repository
 		// Frontend will call /user/repository/save with the selected folder
@@ -107,3 +111,12 @@ func FileUploadHandler(svcCtx *svc.ServiceContext) gin.HandlerFunc {
 		c.JSON(http.StatusOK, resp)
 	}
 }
+
+// emitUploadCompleted tells the uploader's other sessions that a blob is ready
+// to be saved into a folder
+func emitUploadCompleted(c *gin.Context, svcCtx *svc.ServiceContext, repositoryIdentity, name string) {
+	svcCtx.Events.Emit(c.Request.Context(), c.GetString("UserIdentity"), events.UploadCompleted, map[string]string{
+		"repository_identity": repositoryIdentity,
+		"name":                name,
+	})
+}
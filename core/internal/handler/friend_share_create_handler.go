@@ -19,7 +19,7 @@ func FriendShareCreateHandler(svcCtx *svc.ServiceContext) gin.HandlerFunc {
 		}
 
 		l := logic.NewFriendShareCreateLogic(c.Request.Context(), svcCtx)
-		resp, err := l.FriendShareCreate(&req, c.GetString("UserIdentity"))
+		resp, err := l.FriendShareCreate(&req, c.GetString("UserIdentity"), c.GetString("OwnerIdentity"), c.GetString("SpaceIdentity"))
 		if err != nil {
 			respondError(c, err)
 			return
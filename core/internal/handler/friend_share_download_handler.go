@@ -36,6 +36,7 @@ func FriendShareDownloadHandler(svcCtx *svc.ServiceContext) gin.HandlerFunc {
 
 		req := &types.FriendShareDownloadRequest{
 			ShareIdentity: shareIdentity,
+			ItemIdentity:  c.Query("item"),
 		}
 
 		l := logic.NewFriendShareDownloadLogic(c.Request.Context(), svcCtx)
@@ -25,7 +25,7 @@ func FriendShareSaveHandler(svcCtx *svc.ServiceContext) gin.HandlerFunc {
 		}
 
 		l := logic.NewFriendShareSaveLogic(c.Request.Context(), svcCtx)
-		resp, err := l.FriendShareSave(&req, userIdentity)
+		resp, err := l.FriendShareSave(&req, userIdentity, c.GetString("OwnerIdentity"), c.GetString("BillingIdentity"))
 		if err != nil {
 			respondError(c, err)
 			return
@@ -0,0 +1,18 @@
+package handler
+
+import (
+	"net/http"
+
+	"cloud-dist/core/svc"
+
+	"github.com/gin-gonic/gin"
+)
+
+// JwksHandler publishes the JWT verification keys. New keys appear here hours
+// before they start signing, so clients may cache the set for a few minutes.
+func JwksHandler(svcCtx *svc.ServiceContext) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Header("Cache-Control", "public, max-age=300")
+		c.JSON(http.StatusOK, svcCtx.Keys.JWKS())
+	}
+}
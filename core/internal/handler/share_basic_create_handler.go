@@ -19,7 +19,7 @@ func ShareBasicCreateHandler(svcCtx *svc.ServiceContext) gin.HandlerFunc {
 		}
 
 		l := logic.NewShareBasicCreateLogic(c.Request.Context(), svcCtx)
-		resp, err := l.ShareBasicCreate(&req, c.GetString("UserIdentity"))
+		resp, err := l.ShareBasicCreate(&req, c.GetString("UserIdentity"), c.GetString("OwnerIdentity"))
 		if err != nil {
 			respondError(c, err)
 			return
@@ -17,7 +17,7 @@ func ShareBasicDetailHandler(svcCtx *svc.ServiceContext) gin.HandlerFunc {
 		}
 
 		l := logic.NewShareBasicDetailLogic(c.Request.Context(), svcCtx)
-		resp, err := l.ShareBasicDetail(&req)
+		resp, err := l.ShareBasicDetail(&req, c.GetString("UserIdentity"))
 		if err != nil {
 			respondError(c, err)
 			return
@@ -0,0 +1,64 @@
+package handler
+
+import (
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+
+	"cloud-dist/core/helper"
+	"cloud-dist/core/internal/logic"
+	"cloud-dist/core/internal/types"
+	"cloud-dist/core/svc"
+
+	"github.com/gin-gonic/gin"
+)
+
+func ShareBasicDownloadHandler(svcCtx *svc.ServiceContext) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		req := types.ShareBasicDownloadRequest{
+			Identity: c.Query("identity"),
+			Token:    c.Query("token"),
+			Inline:   c.Query("inline") == "1",
+			Range:    c.GetHeader("Range"),
+		}
+		if req.Identity == "" || req.Token == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "share identity and token are required"})
+			return
+		}
+
+		l := logic.NewShareBasicDownloadLogic(c.Request.Context(), svcCtx)
+		result, err := l.ShareBasicDownload(&req, c.GetString("UserIdentity"))
+		if err != nil {
+			respondError(c, err)
+			return
+		}
+		if result.RedirectURL != "" {
+			c.Redirect(http.StatusFound, result.RedirectURL)
+			return
+		}
+		defer result.Body.Close()
+
+		disposition := "attachment"
+		if req.Inline {
+			disposition = "inline"
+		}
+		c.Header("Content-Disposition", helper.ContentDisposition(disposition, result.FileName))
+		c.Header("Content-Type", result.ContentType)
+		c.Header("Accept-Ranges", "bytes")
+		c.Header("Cache-Control", "private, no-store")
+		if result.ContentLength > 0 {
+			c.Header("Content-Length", strconv.FormatInt(result.ContentLength, 10))
+		}
+		status := http.StatusOK
+		if result.ContentRange != "" {
+			c.Header("Content-Range", result.ContentRange)
+			status = http.StatusPartialContent
+		}
+		c.Status(status)
+
+		if _, err = io.Copy(c.Writer, result.Body); err != nil {
+			log.Printf("[ShareBasicDownloadHandler] Failed to stream file: %v", err)
+		}
+	}
+}
@@ -26,7 +26,7 @@ func ShareBasicSaveHandler(svcCtx *svc.ServiceContext) gin.HandlerFunc {
 		}
 
 		l := logic.NewShareBasicSaveLogic(c.Request.Context(), svcCtx)
-		resp, err := l.ShareBasicSave(&req, userIdentity)
+		resp, err := l.ShareBasicSave(&req, c.GetString("OwnerIdentity"), c.GetString("BillingIdentity"))
 		if err != nil {
 			respondError(c, err)
 			return
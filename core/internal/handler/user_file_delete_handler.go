@@ -19,7 +19,7 @@ func UserFileDeleteHandler(svcCtx *svc.ServiceContext) gin.HandlerFunc {
 		}
 
 		l := logic.NewUserFileDeleteLogic(c.Request.Context(), svcCtx)
-		resp, err := l.UserFileDelete(&req, c.GetString("UserIdentity"))
+		resp, err := l.UserFileDelete(&req, c.GetString("OwnerIdentity"), c.GetString("BillingIdentity"))
 		if err != nil {
 			respondError(c, err)
 			return
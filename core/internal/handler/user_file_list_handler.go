@@ -19,7 +19,7 @@ func UserFileListHandler(svcCtx *svc.ServiceContext) gin.HandlerFunc {
 		}
 
 		l := logic.NewUserFileListLogic(c.Request.Context(), svcCtx)
-		resp, err := l.UserFileList(&req, c.GetString("UserIdentity"))
+		resp, err := l.UserFileList(&req, c.GetString("OwnerIdentity"))
 		if err != nil {
 			respondError(c, err)
 			return
@@ -19,7 +19,7 @@ func UserFileMoveHandler(svcCtx *svc.ServiceContext) gin.HandlerFunc {
 		}
 
 		l := logic.NewUserFileMoveLogic(c.Request.Context(), svcCtx)
-		resp, err := l.UserFileMove(&req, c.GetString("UserIdentity"))
+		resp, err := l.UserFileMove(&req, c.GetString("OwnerIdentity"))
 		if err != nil {
 			respondError(c, err)
 			return
@@ -19,7 +19,7 @@ func UserFileNameUpdateHandler(svcCtx *svc.ServiceContext) gin.HandlerFunc {
 		}
 
 		l := logic.NewUserFileNameUpdateLogic(c.Request.Context(), svcCtx)
-		resp, err := l.UserFileNameUpdate(&req, c.GetString("UserIdentity"))
+		resp, err := l.UserFileNameUpdate(&req, c.GetString("OwnerIdentity"))
 		if err != nil {
 			respondError(c, err)
 			return
@@ -25,7 +25,7 @@ func UserFileSearchHandler(svcCtx *svc.ServiceContext) gin.HandlerFunc {
 		}
 
 		l := logic.NewUserFileSearchLogic(c.Request.Context(), svcCtx)
-		resp, err := l.UserFileSearch(&req, userIdentity)
+		resp, err := l.UserFileSearch(&req, c.GetString("OwnerIdentity"))
 		if err != nil {
 			respondError(c, err)
 			return
@@ -19,7 +19,7 @@ func UserFolderCreateHandler(svcCtx *svc.ServiceContext) gin.HandlerFunc {
 		}
 
 		l := logic.NewUserFolderCreateLogic(c.Request.Context(), svcCtx)
-		resp, err := l.UserFolderCreate(&req, c.GetString("UserIdentity"))
+		resp, err := l.UserFolderCreate(&req, c.GetString("OwnerIdentity"))
 		if err != nil {
 			respondError(c, err)
 			return
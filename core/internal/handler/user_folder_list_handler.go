@@ -19,7 +19,7 @@ func UserFolderListHandler(svcCtx *svc.ServiceContext) gin.HandlerFunc {
 		}
 
 		l := logic.NewUserFolderListLogic(c.Request.Context(), svcCtx)
-		resp, err := l.UserFolderList(&req)
+		resp, err := l.UserFolderList(&req, c.GetString("OwnerIdentity"))
 		if err != nil {
 			respondError(c, err)
 			return
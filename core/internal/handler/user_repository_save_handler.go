@@ -18,9 +18,8 @@ func UserRepositorySaveHandler(svcCtx *svc.ServiceContext) gin.HandlerFunc {
 			return
 		}
 
-		userIdentity := c.GetString("UserIdentity")
 		l := logic.NewUserRepositorySaveLogic(c.Request.Context(), svcCtx)
-		resp, err := l.UserRepositorySave(&req, userIdentity)
+		resp, err := l.UserRepositorySave(&req, c.GetString("OwnerIdentity"), c.GetString("BillingIdentity"))
 		if err != nil {
 			respondError(c, err)
 			return
@@ -0,0 +1,33 @@
+package logic
+
+import (
+	"strings"
+
+	"cloud-dist/core/define"
+	"cloud-dist/core/internal/types"
+	"cloud-dist/core/models"
+)
+
+const (
+	maxAccessTokensPerUser = 50
+	accessTokenPrefixLen   = 12 // cdp_ plus eight characters
+)
+
+// accessTokenItem converts a stored token for listing; the hash never leaves
+// the server
+func accessTokenItem(pat *models.PersonalAccessToken) *types.AccessTokenItem {
+	item := &types.AccessTokenItem{
+		Identity:  pat.Identity,
+		Name:      pat.Name,
+		Prefix:    pat.Prefix,
+		Scopes:    strings.Split(pat.Scopes, ","),
+		CreatedAt: pat.CreatedAt.Format(define.Datetime),
+	}
+	if pat.ExpiresAt != nil {
+		item.ExpiresAt = pat.ExpiresAt.Format(define.Datetime)
+	}
+	if pat.LastUsedAt != nil {
+		item.LastUsedAt = pat.LastUsedAt.Format(define.Datetime)
+	}
+	return item
+}
@@ -0,0 +1,100 @@
+package logic
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"cloud-dist/core/audit"
+	"cloud-dist/core/define"
+	"cloud-dist/core/helper"
+	"cloud-dist/core/internal/types"
+	"cloud-dist/core/models"
+	"cloud-dist/core/svc"
+)
+
+type AccessTokenCreateLogic struct {
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewAccessTokenCreateLogic(ctx context.Context, svcCtx *svc.ServiceContext) *AccessTokenCreateLogic {
+	return &AccessTokenCreateLogic{
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *AccessTokenCreateLogic) AccessTokenCreate(req *types.AccessTokenCreateRequest, userIdentity string) (resp *types.AccessTokenCreateReply, err error) {
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return nil, errors.New("token name is required")
+	}
+	if len(name) > 64 {
+		return nil, errors.New("token name must be at most 64 characters")
+	}
+	if req.ExpiresInDays < 0 {
+		return nil, errors.New("expires_in_days cannot be negative")
+	}
+
+	// Keep scopes in canonical order without duplicates
+	requested := make(map[string]bool, len(req.Scopes))
+	for _, scope := range req.Scopes {
+		requested[strings.TrimSpace(scope)] = true
+	}
+	scopes := make([]string, 0, len(requested))
+	for _, scope := range define.AccessTokenScopes {
+		if requested[scope] {
+			scopes = append(scopes, scope)
+			delete(requested, scope)
+		}
+	}
+	for scope := range requested {
+		return nil, fmt.Errorf("unknown scope %q", scope)
+	}
+	if len(scopes) == 0 {
+		return nil, errors.New("at least one scope is required")
+	}
+
+	var cnt int64
+	err = l.svcCtx.DB.WithContext(l.ctx).Model(&models.PersonalAccessToken{}).
+		Where("user_identity = ? AND revoked_at IS NULL", userIdentity).
+		Count(&cnt).Error
+	if err != nil {
+		return nil, err
+	}
+	if cnt >= maxAccessTokensPerUser {
+		return nil, fmt.Errorf("you can have at most %d access tokens", maxAccessTokensPerUser)
+	}
+
+	token, err := helper.NewAccessToken()
+	if err != nil {
+		return nil, err
+	}
+	pat := &models.PersonalAccessToken{
+		Identity:     helper.UUID(),
+		UserIdentity: userIdentity,
+		Name:         name,
+		TokenHash:    helper.HashAccessToken(token),
+		Prefix:       token[:accessTokenPrefixLen],
+		Scopes:       strings.Join(scopes, ","),
+		CreatedAt:    time.Now(),
+	}
+	if req.ExpiresInDays > 0 {
+		expiresAt := time.Now().AddDate(0, 0, req.ExpiresInDays)
+		pat.ExpiresAt = &expiresAt
+	}
+	if err = l.svcCtx.DB.WithContext(l.ctx).Create(pat).Error; err != nil {
+		return nil, err
+	}
+	audit.Record(l.ctx, l.svcCtx.DB, audit.Entry{Actor: userIdentity, Action: audit.ActionAccessTokenCreate,
+		TargetType: audit.TargetToken, Target: pat.Identity, Detail: map[string]interface{}{
+			"name":       pat.Name,
+			"scopes":     pat.Scopes,
+			"expires_in": req.ExpiresInDays,
+		}})
+
+	return &types.AccessTokenCreateReply{Token: token, Item: accessTokenItem(pat)}, nil
+}
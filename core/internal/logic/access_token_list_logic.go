@@ -0,0 +1,39 @@
+package logic
+
+import (
+	"context"
+
+	"cloud-dist/core/internal/types"
+	"cloud-dist/core/models"
+	"cloud-dist/core/svc"
+)
+
+type AccessTokenListLogic struct {
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewAccessTokenListLogic(ctx context.Context, svcCtx *svc.ServiceContext) *AccessTokenListLogic {
+	return &AccessTokenListLogic{
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *AccessTokenListLogic) AccessTokenList(req *types.AccessTokenListRequest, userIdentity string) (resp *types.AccessTokenListReply, err error) {
+	var tokens []*models.PersonalAccessToken
+	err = l.svcCtx.DB.WithContext(l.ctx).
+		Where("user_identity = ? AND revoked_at IS NULL", userIdentity).
+		Order("created_at DESC").
+		Find(&tokens).Error
+	if err != nil {
+		return nil, err
+	}
+
+	resp = new(types.AccessTokenListReply)
+	resp.List = make([]*types.AccessTokenItem, 0, len(tokens))
+	for _, pat := range tokens {
+		resp.List = append(resp.List, accessTokenItem(pat))
+	}
+	return resp, nil
+}
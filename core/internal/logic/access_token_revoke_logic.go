@@ -0,0 +1,39 @@
+package logic
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"cloud-dist/core/audit"
+	"cloud-dist/core/internal/types"
+	"cloud-dist/core/models"
+	"cloud-dist/core/svc"
+)
+
+type AccessTokenRevokeLogic struct {
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewAccessTokenRevokeLogic(ctx context.Context, svcCtx *svc.ServiceContext) *AccessTokenRevokeLogic {
+	return &AccessTokenRevokeLogic{
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *AccessTokenRevokeLogic) AccessTokenRevoke(req *types.AccessTokenRevokeRequest, userIdentity string) (resp *types.AccessTokenRevokeReply, err error) {
+	result := l.svcCtx.DB.WithContext(l.ctx).Model(&models.PersonalAccessToken{}).
+		Where("identity = ? AND user_identity = ? AND revoked_at IS NULL", req.Identity, userIdentity).
+		Update("revoked_at", time.Now())
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, errors.New("access token not found")
+	}
+	audit.Record(l.ctx, l.svcCtx.DB, audit.Entry{Actor: userIdentity, Action: audit.ActionAccessTokenRevoke,
+		TargetType: audit.TargetToken, Target: req.Identity})
+	return &types.AccessTokenRevokeReply{}, nil
+}
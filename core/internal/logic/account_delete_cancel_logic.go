@@ -0,0 +1,38 @@
+package logic
+
+import (
+	"context"
+	"errors"
+
+	"cloud-dist/core/audit"
+	"cloud-dist/core/internal/types"
+	"cloud-dist/core/models"
+	"cloud-dist/core/svc"
+)
+
+type AccountDeleteCancelLogic struct {
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewAccountDeleteCancelLogic(ctx context.Context, svcCtx *svc.ServiceContext) *AccountDeleteCancelLogic {
+	return &AccountDeleteCancelLogic{
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *AccountDeleteCancelLogic) AccountDeleteCancel(req *types.AccountDeleteCancelRequest, userIdentity string) (resp *types.AccountDeleteCancelReply, err error) {
+	res := l.svcCtx.DB.WithContext(l.ctx).Model(&models.UserBasic{}).
+		Where("identity = ? AND delete_after IS NOT NULL", userIdentity).
+		Update("delete_after", nil)
+	if res.Error != nil {
+		return nil, res.Error
+	}
+	if res.RowsAffected == 0 {
+		return nil, errors.New("account deletion is not scheduled")
+	}
+	audit.Record(l.ctx, l.svcCtx.DB, audit.Entry{Actor: userIdentity, Action: audit.ActionAccountDeleteCancel,
+		TargetType: audit.TargetUser, Target: userIdentity})
+	return &types.AccountDeleteCancelReply{}, nil
+}
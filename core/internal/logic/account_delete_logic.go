@@ -0,0 +1,59 @@
+package logic
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"cloud-dist/core/audit"
+	"cloud-dist/core/define"
+	"cloud-dist/core/helper"
+	"cloud-dist/core/internal/types"
+	"cloud-dist/core/models"
+	"cloud-dist/core/svc"
+)
+
+type AccountDeleteLogic struct {
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewAccountDeleteLogic(ctx context.Context, svcCtx *svc.ServiceContext) *AccountDeleteLogic {
+	return &AccountDeleteLogic{
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// AccountDelete schedules the account for deletion after the grace period.
+// Until then the user can still sign in and cancel; the account-deletion job
+// does the actual work.
+func (l *AccountDeleteLogic) AccountDelete(req *types.AccountDeleteRequest, userIdentity string) (resp *types.AccountDeleteReply, err error) {
+	user, err := findUserByIdentity(l.ctx, l.svcCtx, userIdentity)
+	if err != nil {
+		return nil, err
+	}
+	if user.DeleteAfter != nil {
+		return nil, errors.New("account deletion is already scheduled")
+	}
+	if err = reauthenticate(l.ctx, l.svcCtx, user, req.Password, req.EmailCode, req.TwoFactorCode); err != nil {
+		return nil, err
+	}
+
+	deleteAfter := time.Now().Add(define.AccountDeletionGrace)
+	if err = l.svcCtx.DB.WithContext(l.ctx).Model(&models.UserBasic{}).
+		Where("identity = ?", userIdentity).
+		Update("delete_after", deleteAfter).Error; err != nil {
+		return nil, err
+	}
+	audit.Record(l.ctx, l.svcCtx.DB, audit.Entry{Actor: userIdentity, Action: audit.ActionAccountDelete,
+		TargetType: audit.TargetUser, Target: userIdentity, Detail: map[string]interface{}{"delete_after": deleteAfter.Format(define.Datetime)}})
+
+	if user.Email != "" {
+		if err := helper.MailSendAccountDeletion(user.Email, user.Name, deleteAfter); err != nil {
+			log.Printf("[AccountDelete] Send notice failed: %v", err)
+		}
+	}
+	return &types.AccountDeleteReply{DeleteAfter: deleteAfter.Format(define.Datetime)}, nil
+}
@@ -0,0 +1,56 @@
+package logic
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"cloud-dist/core/define"
+	"cloud-dist/core/helper"
+	"cloud-dist/core/internal/types"
+	"cloud-dist/core/models"
+	"cloud-dist/core/svc"
+)
+
+// exportInterval is how often a user may ask for a new export; each one
+// reads every file they own
+const exportInterval = 24 * time.Hour
+
+type AccountExportCreateLogic struct {
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewAccountExportCreateLogic(ctx context.Context, svcCtx *svc.ServiceContext) *AccountExportCreateLogic {
+	return &AccountExportCreateLogic{
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// AccountExportCreate queues an export of all the user's data. The archive is
+// built by the account-export job, which mails the user when it is ready.
+func (l *AccountExportCreateLogic) AccountExportCreate(req *types.AccountExportCreateRequest, userIdentity string) (resp *types.AccountExportCreateReply, err error) {
+	var recent int64
+	err = l.svcCtx.DB.WithContext(l.ctx).Model(&models.AccountExport{}).
+		Where("user_identity = ? AND (status IN ? OR (status <> ? AND created_at > ?))", userIdentity,
+			[]string{define.ExportStatusPending, define.ExportStatusRunning},
+			define.ExportStatusFailed, time.Now().Add(-exportInterval)).
+		Count(&recent).Error
+	if err != nil {
+		return nil, err
+	}
+	if recent > 0 {
+		return nil, errors.New("an export was already requested in the last day")
+	}
+
+	e := &models.AccountExport{
+		Identity:     helper.UUID(),
+		UserIdentity: userIdentity,
+		Status:       define.ExportStatusPending,
+	}
+	if err = l.svcCtx.DB.WithContext(l.ctx).Create(e).Error; err != nil {
+		return nil, err
+	}
+	return &types.AccountExportCreateReply{Identity: e.Identity}, nil
+}
@@ -0,0 +1,47 @@
+package logic
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"cloud-dist/core/define"
+	"cloud-dist/core/helper"
+	"cloud-dist/core/internal/types"
+	"cloud-dist/core/models"
+	"cloud-dist/core/svc"
+
+	"gorm.io/gorm"
+)
+
+type AccountExportDownloadLogic struct {
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewAccountExportDownloadLogic(ctx context.Context, svcCtx *svc.ServiceContext) *AccountExportDownloadLogic {
+	return &AccountExportDownloadLogic{
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// AccountExportDownload returns a short-lived link to a finished archive
+func (l *AccountExportDownloadLogic) AccountExportDownload(req *types.AccountExportDownloadRequest, userIdentity string) (resp *types.AccountExportDownloadReply, err error) {
+	e := new(models.AccountExport)
+	err = l.svcCtx.DB.WithContext(l.ctx).
+		Where("identity = ? AND user_identity = ?", req.Identity, userIdentity).
+		First(e).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, errors.New("export not found")
+	}
+	if err != nil {
+		return nil, err
+	}
+	if e.Status != define.ExportStatusReady || (e.ExpiresAt != nil && time.Now().After(*e.ExpiresAt)) {
+		return nil, errors.New("export is not available for download")
+	}
+
+	name := "cloud-dist-export-" + e.CreatedAt.Format("2006-01-02") + ".zip"
+	return &types.AccountExportDownloadReply{URL: helper.S3PresignedURLWithExpiry(e.Path, 15*time.Minute, name)}, nil
+}
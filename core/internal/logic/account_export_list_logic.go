@@ -0,0 +1,53 @@
+package logic
+
+import (
+	"context"
+
+	"cloud-dist/core/define"
+	"cloud-dist/core/internal/types"
+	"cloud-dist/core/models"
+	"cloud-dist/core/svc"
+)
+
+type AccountExportListLogic struct {
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewAccountExportListLogic(ctx context.Context, svcCtx *svc.ServiceContext) *AccountExportListLogic {
+	return &AccountExportListLogic{
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *AccountExportListLogic) AccountExportList(req *types.AccountExportListRequest, userIdentity string) (resp *types.AccountExportListReply, err error) {
+	var exports []*models.AccountExport
+	err = l.svcCtx.DB.WithContext(l.ctx).
+		Where("user_identity = ?", userIdentity).
+		Order("id DESC").
+		Limit(20).
+		Find(&exports).Error
+	if err != nil {
+		return nil, err
+	}
+
+	resp = &types.AccountExportListReply{List: make([]*types.AccountExportItem, 0, len(exports))}
+	for _, e := range exports {
+		item := &types.AccountExportItem{
+			Identity:  e.Identity,
+			Status:    e.Status,
+			Size:      e.Size,
+			Error:     e.Error,
+			CreatedAt: e.CreatedAt.Format(define.Datetime),
+		}
+		if e.FinishedAt != nil {
+			item.FinishedAt = e.FinishedAt.Format(define.Datetime)
+		}
+		if e.ExpiresAt != nil {
+			item.ExpiresAt = e.ExpiresAt.Format(define.Datetime)
+		}
+		resp.List = append(resp.List, item)
+	}
+	return resp, nil
+}
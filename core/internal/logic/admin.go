@@ -0,0 +1,98 @@
+package logic
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"cloud-dist/core/define"
+	"cloud-dist/core/helper"
+	"cloud-dist/core/internal/types"
+	"cloud-dist/core/models"
+	"cloud-dist/core/svc"
+
+	"gorm.io/gorm"
+)
+
+var errAccountSuspended = errors.New("this account is suspended, contact support")
+
+// Admin actions, as recorded in admin_action
+const (
+	adminActionVolumeUpdate = "user.volume_update"
+	adminActionSuspend      = "user.suspend"
+	adminActionUnsuspend    = "user.unsuspend"
+	adminActionLogout       = "user.logout"
+	adminActionRoleUpdate   = "user.role_update"
+	adminActionTakedown     = "share.takedown"
+)
+
+const maxAdminPageSize = 100
+
+// recordAdminAction writes who did what to which account or share. detail is
+// stored as JSON. Pass the transaction that made the change so the record
+// cannot be lost on its own.
+func recordAdminAction(ctx context.Context, db *gorm.DB, actorIdentity, action, targetType, targetIdentity string, detail interface{}) error {
+	raw, err := json.Marshal(detail)
+	if err != nil {
+		return err
+	}
+	return db.WithContext(ctx).Create(&models.AdminAction{
+		ActorIdentity:  actorIdentity,
+		Action:         action,
+		TargetType:     targetType,
+		TargetIdentity: targetIdentity,
+		Detail:         string(raw),
+		IP:             helper.ClientMetaFromContext(ctx).IP,
+	}).Error
+}
+
+// adminPage turns page and size from a request into a limit and offset
+func adminPage(page, size int) (int, int) {
+	if size <= 0 {
+		size = define.PageSize
+	}
+	if size > maxAdminPageSize {
+		size = maxAdminPageSize
+	}
+	if page <= 0 {
+		page = 1
+	}
+	return size, (page - 1) * size
+}
+
+// findAdminTarget loads the account an admin acts on. Admins may not act on
+// their own account, so nobody can lock themselves out or raise their quota.
+func findAdminTarget(ctx context.Context, svcCtx *svc.ServiceContext, actorIdentity, identity string) (*models.UserBasic, error) {
+	if identity == actorIdentity {
+		return nil, errors.New("you cannot change your own account here")
+	}
+	return findUserByIdentity(ctx, svcCtx, identity)
+}
+
+func adminUserItem(u *models.UserBasic) *types.AdminUserItem {
+	item := &types.AdminUserItem{
+		Identity:         u.Identity,
+		Name:             u.Name,
+		Email:            u.Email,
+		Role:             u.Role,
+		NowVolume:        u.NowVolume,
+		TotalVolume:      u.TotalVolume,
+		TwoFactorEnabled: u.TOTPEnabled,
+		SuspendedAt:      formatOptionalTime(u.SuspendedAt),
+		SuspendReason:    u.SuspendReason,
+		DeleteAfter:      formatOptionalTime(u.DeleteAfter),
+		CreatedAt:        u.CreatedAt.Format(define.Datetime),
+	}
+	if item.Role == "" {
+		item.Role = define.UserRoleUser
+	}
+	return item
+}
+
+func formatOptionalTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.Format(define.Datetime)
+}
@@ -0,0 +1,59 @@
+package logic
+
+import (
+	"context"
+
+	"cloud-dist/core/define"
+	"cloud-dist/core/internal/types"
+	"cloud-dist/core/models"
+	"cloud-dist/core/svc"
+
+	"gorm.io/gorm"
+)
+
+type AdminActionListLogic struct {
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewAdminActionListLogic(ctx context.Context, svcCtx *svc.ServiceContext) *AdminActionListLogic {
+	return &AdminActionListLogic{
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *AdminActionListLogic) AdminActionList(req *types.AdminActionListRequest, userIdentity string) (resp *types.AdminActionListReply, err error) {
+	query := l.svcCtx.DB.WithContext(l.ctx).Model(&models.AdminAction{})
+	if req.ActorIdentity != "" {
+		query = query.Where("actor_identity = ?", req.ActorIdentity)
+	}
+	if req.TargetIdentity != "" {
+		query = query.Where("target_identity = ?", req.TargetIdentity)
+	}
+
+	// Counting and listing each start from the same filters
+	query = query.Session(&gorm.Session{})
+
+	resp = &types.AdminActionListReply{List: make([]*types.AdminActionItem, 0)}
+	if err = query.Count(&resp.Count).Error; err != nil {
+		return nil, err
+	}
+	limit, offset := adminPage(req.Page, req.Size)
+	var actions []*models.AdminAction
+	if err = query.Order("id DESC").Limit(limit).Offset(offset).Find(&actions).Error; err != nil {
+		return nil, err
+	}
+	for _, a := range actions {
+		resp.List = append(resp.List, &types.AdminActionItem{
+			ActorIdentity:  a.ActorIdentity,
+			Action:         a.Action,
+			TargetType:     a.TargetType,
+			TargetIdentity: a.TargetIdentity,
+			Detail:         a.Detail,
+			IP:             a.IP,
+			CreatedAt:      a.CreatedAt.Format(define.Datetime),
+		})
+	}
+	return resp, nil
+}
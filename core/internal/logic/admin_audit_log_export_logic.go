@@ -0,0 +1,28 @@
+package logic
+
+import (
+	"context"
+
+	"cloud-dist/core/internal/types"
+	"cloud-dist/core/svc"
+)
+
+type AdminAuditLogExportLogic struct {
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewAdminAuditLogExportLogic(ctx context.Context, svcCtx *svc.ServiceContext) *AdminAuditLogExportLogic {
+	return &AdminAuditLogExportLogic{
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *AdminAuditLogExportLogic) AdminAuditLogExport(req *types.AdminAuditLogExportRequest, userIdentity string) (*AuditLogExportResult, error) {
+	query, err := adminAuditLogQuery(l.ctx, l.svcCtx, &req.AdminAuditLogFilter)
+	if err != nil {
+		return nil, err
+	}
+	return newAuditLogExport(l.ctx, query), nil
+}
@@ -0,0 +1,28 @@
+package logic
+
+import (
+	"context"
+
+	"cloud-dist/core/internal/types"
+	"cloud-dist/core/svc"
+)
+
+type AdminAuditLogListLogic struct {
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewAdminAuditLogListLogic(ctx context.Context, svcCtx *svc.ServiceContext) *AdminAuditLogListLogic {
+	return &AdminAuditLogListLogic{
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *AdminAuditLogListLogic) AdminAuditLogList(req *types.AdminAuditLogListRequest, userIdentity string) (resp *types.AuditLogListReply, err error) {
+	query, err := adminAuditLogQuery(l.ctx, l.svcCtx, &req.AdminAuditLogFilter)
+	if err != nil {
+		return nil, err
+	}
+	return listAuditLog(query, req.Page, req.Size)
+}
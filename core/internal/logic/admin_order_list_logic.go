@@ -0,0 +1,62 @@
+package logic
+
+import (
+	"context"
+
+	"cloud-dist/core/define"
+	"cloud-dist/core/internal/types"
+	"cloud-dist/core/models"
+	"cloud-dist/core/svc"
+
+	"gorm.io/gorm"
+)
+
+type AdminOrderListLogic struct {
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewAdminOrderListLogic(ctx context.Context, svcCtx *svc.ServiceContext) *AdminOrderListLogic {
+	return &AdminOrderListLogic{
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *AdminOrderListLogic) AdminOrderList(req *types.AdminOrderListRequest, userIdentity string) (resp *types.AdminOrderListReply, err error) {
+	query := l.svcCtx.DB.WithContext(l.ctx).Model(&models.StorageOrder{})
+	if req.UserIdentity != "" {
+		query = query.Where("user_identity = ?", req.UserIdentity)
+	}
+	if req.Status != "" {
+		query = query.Where("status = ?", req.Status)
+	}
+
+	// Counting and listing each start from the same filters
+	query = query.Session(&gorm.Session{})
+
+	resp = &types.AdminOrderListReply{List: make([]*types.AdminOrderItem, 0)}
+	if err = query.Count(&resp.Count).Error; err != nil {
+		return nil, err
+	}
+	limit, offset := adminPage(req.Page, req.Size)
+	var orders []*models.StorageOrder
+	if err = query.Order("id DESC").Limit(limit).Offset(offset).Find(&orders).Error; err != nil {
+		return nil, err
+	}
+	for _, o := range orders {
+		resp.List = append(resp.List, &types.AdminOrderItem{
+			Identity:              o.Identity,
+			UserIdentity:          o.UserIdentity,
+			StripeSessionID:       o.StripeSessionID,
+			StripePaymentIntentID: o.StripePaymentIntentID,
+			StorageAmount:         o.StorageAmount,
+			PriceAmount:           o.PriceAmount,
+			Currency:              o.Currency,
+			Status:                o.Status,
+			CreatedAt:             o.CreatedAt.Format(define.Datetime),
+			UpdatedAt:             o.UpdatedAt.Format(define.Datetime),
+		})
+	}
+	return resp, nil
+}
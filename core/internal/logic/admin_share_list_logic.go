@@ -0,0 +1,83 @@
+package logic
+
+import (
+	"context"
+	"time"
+
+	"cloud-dist/core/define"
+	"cloud-dist/core/internal/types"
+	"cloud-dist/core/svc"
+
+	"gorm.io/gorm"
+)
+
+type AdminShareListLogic struct {
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewAdminShareListLogic(ctx context.Context, svcCtx *svc.ServiceContext) *AdminShareListLogic {
+	return &AdminShareListLogic{
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *AdminShareListLogic) AdminShareList(req *types.AdminShareListRequest, userIdentity string) (resp *types.AdminShareListReply, err error) {
+	query := l.svcCtx.DB.WithContext(l.ctx).Table("share_basic").
+		Where("share_basic.deleted_at IS NULL")
+	if req.UserIdentity != "" {
+		query = query.Where("share_basic.user_identity = ?", req.UserIdentity)
+	}
+
+	// Counting and listing each start from the same filters
+	query = query.Session(&gorm.Session{})
+
+	resp = &types.AdminShareListReply{List: make([]*types.AdminShareItem, 0)}
+	if err = query.Count(&resp.Count).Error; err != nil {
+		return nil, err
+	}
+
+	var rows []struct {
+		Identity           string
+		UserIdentity       string
+		UserName           string
+		Name               string
+		RepositoryIdentity string
+		Size               int64
+		ExpiredTime        int
+		ClickNum           int
+		DownloadLimit      int
+		DownloadNum        int
+		CreatedAt          time.Time
+	}
+	limit, offset := adminPage(req.Page, req.Size)
+	err = query.
+		Select("share_basic.identity, share_basic.user_identity, user_basic.name AS user_name, user_repository.name, " +
+			"share_basic.repository_identity, repository_pool.size, share_basic.expired_time, share_basic.click_num, " +
+			"share_basic.download_limit, share_basic.download_num, share_basic.created_at").
+		Joins("LEFT JOIN user_basic ON user_basic.identity = share_basic.user_identity").
+		Joins("LEFT JOIN user_repository ON user_repository.identity = share_basic.user_repository_identity").
+		Joins("LEFT JOIN repository_pool ON repository_pool.identity = share_basic.repository_identity").
+		Order("share_basic.id DESC").Limit(limit).Offset(offset).
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range rows {
+		resp.List = append(resp.List, &types.AdminShareItem{
+			Identity:           r.Identity,
+			UserIdentity:       r.UserIdentity,
+			UserName:           r.UserName,
+			Name:               r.Name,
+			RepositoryIdentity: r.RepositoryIdentity,
+			Size:               r.Size,
+			ExpiredTime:        r.ExpiredTime,
+			ClickNum:           r.ClickNum,
+			DownloadLimit:      r.DownloadLimit,
+			DownloadNum:        r.DownloadNum,
+			CreatedAt:          r.CreatedAt.Format(define.Datetime),
+		})
+	}
+	return resp, nil
+}
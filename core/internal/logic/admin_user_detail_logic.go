@@ -0,0 +1,47 @@
+package logic
+
+import (
+	"context"
+	"time"
+
+	"cloud-dist/core/internal/types"
+	"cloud-dist/core/models"
+	"cloud-dist/core/svc"
+)
+
+type AdminUserDetailLogic struct {
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewAdminUserDetailLogic(ctx context.Context, svcCtx *svc.ServiceContext) *AdminUserDetailLogic {
+	return &AdminUserDetailLogic{
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *AdminUserDetailLogic) AdminUserDetail(req *types.AdminUserDetailRequest, userIdentity string) (resp *types.AdminUserDetailReply, err error) {
+	user, err := findUserByIdentity(l.ctx, l.svcCtx, req.Identity)
+	if err != nil {
+		return nil, err
+	}
+	resp = &types.AdminUserDetailReply{User: adminUserItem(user)}
+
+	db := l.svcCtx.DB.WithContext(l.ctx)
+	if err = db.Model(&models.UserRepository{}).Where("user_identity = ?", user.Identity).Count(&resp.FileCount).Error; err != nil {
+		return nil, err
+	}
+	if err = db.Model(&models.ShareBasic{}).Where("user_identity = ?", user.Identity).Count(&resp.ShareCount).Error; err != nil {
+		return nil, err
+	}
+	if err = db.Model(&models.UserSession{}).
+		Where("user_identity = ? AND revoked_at IS NULL AND expires_at > ?", user.Identity, time.Now()).
+		Count(&resp.SessionCount).Error; err != nil {
+		return nil, err
+	}
+	if err = db.Model(&models.StorageOrder{}).Where("user_identity = ?", user.Identity).Count(&resp.OrderCount).Error; err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
@@ -0,0 +1,58 @@
+package logic
+
+import (
+	"context"
+	"strings"
+
+	"cloud-dist/core/internal/types"
+	"cloud-dist/core/models"
+	"cloud-dist/core/svc"
+
+	"gorm.io/gorm"
+)
+
+type AdminUserListLogic struct {
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewAdminUserListLogic(ctx context.Context, svcCtx *svc.ServiceContext) *AdminUserListLogic {
+	return &AdminUserListLogic{
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *AdminUserListLogic) AdminUserList(req *types.AdminUserListRequest, userIdentity string) (resp *types.AdminUserListReply, err error) {
+	query := l.svcCtx.DB.WithContext(l.ctx).Model(&models.UserBasic{})
+	if keyword := strings.TrimSpace(req.Keyword); keyword != "" {
+		if strings.Contains(keyword, "@") {
+			query = query.Where("email LIKE ?", escapeLike(keyword)+"%")
+		} else {
+			query = query.Where("(name LIKE ? OR identity = ?)", escapeLike(keyword)+"%", keyword)
+		}
+	}
+	if req.Role != "" {
+		query = query.Where("role = ?", req.Role)
+	}
+	if req.Suspended {
+		query = query.Where("suspended_at IS NOT NULL")
+	}
+
+	// Counting and listing each start from the same filters
+	query = query.Session(&gorm.Session{})
+
+	resp = &types.AdminUserListReply{List: make([]*types.AdminUserItem, 0)}
+	if err = query.Count(&resp.Count).Error; err != nil {
+		return nil, err
+	}
+	limit, offset := adminPage(req.Page, req.Size)
+	var users []*models.UserBasic
+	if err = query.Order("id DESC").Limit(limit).Offset(offset).Find(&users).Error; err != nil {
+		return nil, err
+	}
+	for _, u := range users {
+		resp.List = append(resp.List, adminUserItem(u))
+	}
+	return resp, nil
+}
@@ -0,0 +1,36 @@
+package logic
+
+import (
+	"context"
+
+	"cloud-dist/core/internal/types"
+	"cloud-dist/core/svc"
+)
+
+type AdminUserLogoutLogic struct {
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewAdminUserLogoutLogic(ctx context.Context, svcCtx *svc.ServiceContext) *AdminUserLogoutLogic {
+	return &AdminUserLogoutLogic{
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// AdminUserLogout ends every session of a user, e.g. when they report a lost
+// device and cannot sign in to do it themselves
+func (l *AdminUserLogoutLogic) AdminUserLogout(req *types.AdminUserLogoutRequest, userIdentity string) (resp *types.AdminUserLogoutReply, err error) {
+	user, err := findUserByIdentity(l.ctx, l.svcCtx, req.Identity)
+	if err != nil {
+		return nil, err
+	}
+	if err = logoutEverywhere(l.ctx, l.svcCtx, user.Identity, sessionRevokeAdmin); err != nil {
+		return nil, err
+	}
+	if err = recordAdminAction(l.ctx, l.svcCtx.DB, userIdentity, adminActionLogout, "user", user.Identity, struct{}{}); err != nil {
+		return nil, err
+	}
+	return &types.AdminUserLogoutReply{}, nil
+}
@@ -0,0 +1,49 @@
+package logic
+
+import (
+	"context"
+	"errors"
+
+	"cloud-dist/core/define"
+	"cloud-dist/core/internal/types"
+	"cloud-dist/core/models"
+	"cloud-dist/core/svc"
+
+	"gorm.io/gorm"
+)
+
+type AdminUserRoleUpdateLogic struct {
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewAdminUserRoleUpdateLogic(ctx context.Context, svcCtx *svc.ServiceContext) *AdminUserRoleUpdateLogic {
+	return &AdminUserRoleUpdateLogic{
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *AdminUserRoleUpdateLogic) AdminUserRoleUpdate(req *types.AdminUserRoleUpdateRequest, userIdentity string) (resp *types.AdminUserRoleUpdateReply, err error) {
+	if !define.UserRoleAllows(req.Role, define.UserRoleUser) {
+		return nil, errors.New("role must be user, support or admin")
+	}
+	user, err := findAdminTarget(l.ctx, l.svcCtx, userIdentity, req.Identity)
+	if err != nil {
+		return nil, err
+	}
+
+	err = l.svcCtx.DB.WithContext(l.ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.UserBasic{}).Where("identity = ?", user.Identity).
+			UpdateColumn("role", req.Role).Error; err != nil {
+			return err
+		}
+		return recordAdminAction(l.ctx, tx, userIdentity, adminActionRoleUpdate, "user", user.Identity,
+			map[string]string{"from": user.Role, "to": req.Role})
+	})
+	if err != nil {
+		return nil, err
+	}
+	user.Role = req.Role
+	return &types.AdminUserRoleUpdateReply{User: adminUserItem(user)}, nil
+}
@@ -0,0 +1,48 @@
+package logic
+
+import (
+	"context"
+	"errors"
+
+	"cloud-dist/core/internal/types"
+	"cloud-dist/core/models"
+	"cloud-dist/core/svc"
+
+	"gorm.io/gorm"
+)
+
+type AdminUserUnsuspendLogic struct {
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewAdminUserUnsuspendLogic(ctx context.Context, svcCtx *svc.ServiceContext) *AdminUserUnsuspendLogic {
+	return &AdminUserUnsuspendLogic{
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *AdminUserUnsuspendLogic) AdminUserUnsuspend(req *types.AdminUserUnsuspendRequest, userIdentity string) (resp *types.AdminUserUnsuspendReply, err error) {
+	user, err := findAdminTarget(l.ctx, l.svcCtx, userIdentity, req.Identity)
+	if err != nil {
+		return nil, err
+	}
+	if user.SuspendedAt == nil {
+		return nil, errors.New("account is not suspended")
+	}
+
+	err = l.svcCtx.DB.WithContext(l.ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.UserBasic{}).Where("identity = ?", user.Identity).
+			Updates(map[string]interface{}{"suspended_at": nil, "suspend_reason": ""}).Error; err != nil {
+			return err
+		}
+		return recordAdminAction(l.ctx, tx, userIdentity, adminActionUnsuspend, "user", user.Identity,
+			map[string]string{"reason": user.SuspendReason})
+	})
+	if err != nil {
+		return nil, err
+	}
+	user.SuspendedAt, user.SuspendReason = nil, ""
+	return &types.AdminUserUnsuspendReply{User: adminUserItem(user)}, nil
+}
@@ -0,0 +1,54 @@
+package logic
+
+import (
+	"context"
+	"errors"
+
+	"cloud-dist/core/internal/types"
+	"cloud-dist/core/models"
+	"cloud-dist/core/svc"
+
+	"gorm.io/gorm"
+)
+
+type AdminUserVolumeUpdateLogic struct {
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewAdminUserVolumeUpdateLogic(ctx context.Context, svcCtx *svc.ServiceContext) *AdminUserVolumeUpdateLogic {
+	return &AdminUserVolumeUpdateLogic{
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// AdminUserVolumeUpdate sets a user's storage quota, e.g. for a refund or a
+// support gesture. It may go below what they already use; uploads then stop
+// until they free space.
+func (l *AdminUserVolumeUpdateLogic) AdminUserVolumeUpdate(req *types.AdminUserVolumeUpdateRequest, userIdentity string) (resp *types.AdminUserVolumeUpdateReply, err error) {
+	if req.TotalVolume < 0 {
+		return nil, errors.New("total volume cannot be negative")
+	}
+	user, err := findAdminTarget(l.ctx, l.svcCtx, userIdentity, req.Identity)
+	if err != nil {
+		return nil, err
+	}
+
+	err = l.svcCtx.DB.WithContext(l.ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.UserBasic{}).Where("identity = ?", user.Identity).
+			UpdateColumn("total_volume", req.TotalVolume).Error; err != nil {
+			return err
+		}
+		return recordAdminAction(l.ctx, tx, userIdentity, adminActionVolumeUpdate, "user", user.Identity, map[string]interface{}{
+			"from": user.TotalVolume,
+			"to":   req.TotalVolume,
+			"note": req.Note,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	user.TotalVolume = req.TotalVolume
+	return &types.AdminUserVolumeUpdateReply{User: adminUserItem(user)}, nil
+}
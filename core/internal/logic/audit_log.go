@@ -0,0 +1,119 @@
+package logic
+
+import (
+	"context"
+	"errors"
+	"io"
+	"time"
+
+	"cloud-dist/core/audit"
+	"cloud-dist/core/define"
+	"cloud-dist/core/internal/types"
+	"cloud-dist/core/models"
+	"cloud-dist/core/svc"
+
+	"gorm.io/gorm"
+)
+
+// auditLogQuery selects audit log entries by action and date range
+func auditLogQuery(ctx context.Context, svcCtx *svc.ServiceContext, f *types.AuditLogFilter) (*gorm.DB, error) {
+	query := svcCtx.DB.WithContext(ctx).Model(&models.AuditLog{})
+	if f.Action != "" {
+		query = query.Where("action = ?", f.Action)
+	}
+	if f.StartDate != "" {
+		start, err := time.ParseInLocation("2006-01-02", f.StartDate, time.Local)
+		if err != nil {
+			return nil, errors.New("start_date must be YYYY-MM-DD")
+		}
+		query = query.Where("created_at >= ?", start)
+	}
+	if f.EndDate != "" {
+		end, err := time.ParseInLocation("2006-01-02", f.EndDate, time.Local)
+		if err != nil {
+			return nil, errors.New("end_date must be YYYY-MM-DD")
+		}
+		query = query.Where("created_at < ?", end.AddDate(0, 0, 1))
+	}
+	return query, nil
+}
+
+// userAuditLogQuery selects what a user may see: what they did, and what
+// others did to their account, files and shares
+func userAuditLogQuery(ctx context.Context, svcCtx *svc.ServiceContext, f *types.AuditLogFilter, userIdentity string) (*gorm.DB, error) {
+	query, err := auditLogQuery(ctx, svcCtx, f)
+	if err != nil {
+		return nil, err
+	}
+	return query.Where("(actor_identity = ? OR subject_identity = ?)", userIdentity, userIdentity), nil
+}
+
+func adminAuditLogQuery(ctx context.Context, svcCtx *svc.ServiceContext, f *types.AdminAuditLogFilter) (*gorm.DB, error) {
+	query, err := auditLogQuery(ctx, svcCtx, &f.AuditLogFilter)
+	if err != nil {
+		return nil, err
+	}
+	if f.ActorIdentity != "" {
+		query = query.Where("actor_identity = ?", f.ActorIdentity)
+	}
+	if f.SubjectIdentity != "" {
+		query = query.Where("subject_identity = ?", f.SubjectIdentity)
+	}
+	if f.TargetIdentity != "" {
+		query = query.Where("target_identity = ?", f.TargetIdentity)
+	}
+	if f.IP != "" {
+		query = query.Where("ip = ?", f.IP)
+	}
+	return query, nil
+}
+
+// listAuditLog returns one page of the selected entries, newest first
+func listAuditLog(query *gorm.DB, page, size int) (*types.AuditLogListReply, error) {
+	query = query.Session(&gorm.Session{})
+	resp := &types.AuditLogListReply{List: make([]*types.AuditLogItem, 0)}
+	if err := query.Count(&resp.Count).Error; err != nil {
+		return nil, err
+	}
+	limit, offset := adminPage(page, size)
+	var entries []*models.AuditLog
+	if err := query.Order("id DESC").Limit(limit).Offset(offset).Find(&entries).Error; err != nil {
+		return nil, err
+	}
+	for _, e := range entries {
+		resp.List = append(resp.List, &types.AuditLogItem{
+			ActorIdentity:   e.ActorIdentity,
+			SubjectIdentity: e.SubjectIdentity,
+			Action:          e.Action,
+			TargetType:      e.TargetType,
+			TargetIdentity:  e.TargetIdentity,
+			Success:         e.Success,
+			Reason:          e.Reason,
+			Detail:          e.Detail,
+			IP:              e.IP,
+			UserAgent:       e.UserAgent,
+			CreatedAt:       e.CreatedAt.Format(define.Datetime),
+		})
+	}
+	return resp, nil
+}
+
+// AuditLogExportResult is a CSV export ready to be streamed to the client
+type AuditLogExportResult struct {
+	FileName string
+	ctx      context.Context
+	query    *gorm.DB
+}
+
+func newAuditLogExport(ctx context.Context, query *gorm.DB) *AuditLogExportResult {
+	return &AuditLogExportResult{
+		FileName: "audit-log-" + time.Now().Format("2006-01-02") + ".csv",
+		ctx:      ctx,
+		query:    query,
+	}
+}
+
+// WriteCSV writes the entries, oldest first, up to audit.ExportLimit rows
+func (r *AuditLogExportResult) WriteCSV(w io.Writer) (int, error) {
+	return audit.WriteCSV(r.ctx, r.query, w)
+}
@@ -0,0 +1,28 @@
+package logic
+
+import (
+	"context"
+
+	"cloud-dist/core/internal/types"
+	"cloud-dist/core/svc"
+)
+
+type AuditLogExportLogic struct {
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewAuditLogExportLogic(ctx context.Context, svcCtx *svc.ServiceContext) *AuditLogExportLogic {
+	return &AuditLogExportLogic{
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *AuditLogExportLogic) AuditLogExport(req *types.AuditLogExportRequest, userIdentity string) (*AuditLogExportResult, error) {
+	query, err := userAuditLogQuery(l.ctx, l.svcCtx, &req.AuditLogFilter, userIdentity)
+	if err != nil {
+		return nil, err
+	}
+	return newAuditLogExport(l.ctx, query), nil
+}
@@ -0,0 +1,28 @@
+package logic
+
+import (
+	"context"
+
+	"cloud-dist/core/internal/types"
+	"cloud-dist/core/svc"
+)
+
+type AuditLogListLogic struct {
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewAuditLogListLogic(ctx context.Context, svcCtx *svc.ServiceContext) *AuditLogListLogic {
+	return &AuditLogListLogic{
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *AuditLogListLogic) AuditLogList(req *types.AuditLogListRequest, userIdentity string) (resp *types.AuditLogListReply, err error) {
+	query, err := userAuditLogQuery(l.ctx, l.svcCtx, &req.AuditLogFilter, userIdentity)
+	if err != nil {
+		return nil, err
+	}
+	return listAuditLog(query, req.Page, req.Size)
+}
@@ -0,0 +1,31 @@
+package logic
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"cloud-dist/core/models"
+	"cloud-dist/core/svc"
+)
+
+// emailChangeUndoTTL is how long the old address can take the account back
+const emailChangeUndoTTL = 3 * 24 * time.Hour
+
+var errEmailInUse = errors.New("email is already used by another account")
+
+// emailChangeKey holds the address a user asked to move to until they
+// confirm the code sent there
+func emailChangeKey(userIdentity string) string {
+	return "email_change:" + userIdentity
+}
+
+// emailInUse is the uniqueness check shared by registration and email
+// change. exceptUser may name the account that is allowed to hold it.
+func emailInUse(ctx context.Context, svcCtx *svc.ServiceContext, email, exceptUser string) (bool, error) {
+	var cnt int64
+	err := svcCtx.DB.WithContext(ctx).Model(&models.UserBasic{}).
+		Where("email = ? AND identity <> ?", email, exceptUser).
+		Count(&cnt).Error
+	return cnt > 0, err
+}
@@ -0,0 +1,110 @@
+package logic
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"time"
+
+	"cloud-dist/core/define"
+	"cloud-dist/core/events"
+	"cloud-dist/core/svc"
+)
+
+// eventHeartbeat keeps idle connections open through proxies
+const eventHeartbeat = 25 * time.Second
+
+// eventAuthCheck is how often an open stream re-checks its access token, so
+// a revoked session or suspended account stops receiving events
+const eventAuthCheck = 30 * time.Second
+
+type EventStreamLogic struct {
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewEventStreamLogic(ctx context.Context, svcCtx *svc.ServiceContext) *EventStreamLogic {
+	return &EventStreamLogic{
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// EventStream replays the events after lastEventID and then forwards live
+// events until the client disconnects, the access token the stream was opened
+// with expires, or it stops passing the auth checks. send writes one event,
+// or a heartbeat when the event is nil.
+func (l *EventStreamLogic) EventStream(claim *define.UserClaim, lastEventID string, send func(*events.Event) error) error {
+	if claim == nil {
+		return errors.New("a login session is required")
+	}
+	userIdentity := claim.Identity
+
+	// Subscribe before replaying so nothing published in between is lost;
+	// duplicates are skipped by comparing stream IDs
+	sub, err := l.svcCtx.Events.Subscribe(l.ctx, userIdentity)
+	if err != nil {
+		return err
+	}
+	defer sub.Close()
+
+	lastID := ""
+	if events.ValidID(lastEventID) {
+		replay, err := l.svcCtx.Events.Replay(l.ctx, userIdentity, lastEventID)
+		if err != nil {
+			return err
+		}
+		lastID = lastEventID
+		for _, e := range replay {
+			if err = send(e); err != nil {
+				return err
+			}
+			lastID = e.ID
+		}
+	}
+
+	ticker := time.NewTicker(eventHeartbeat)
+	defer ticker.Stop()
+	authTicker := time.NewTicker(eventAuthCheck)
+	defer authTicker.Stop()
+	var expired <-chan time.Time
+	if claim.ExpiresAt != nil {
+		timer := time.NewTimer(time.Until(claim.ExpiresAt.Time))
+		defer timer.Stop()
+		expired = timer.C
+	}
+	ch := sub.Channel()
+	for {
+		select {
+		case <-l.ctx.Done():
+			return nil
+		case <-expired:
+			return errors.New("access token has expired")
+		case <-authTicker.C:
+			if err = l.svcCtx.Revalidate(l.ctx, claim); err != nil {
+				return err
+			}
+		case <-ticker.C:
+			if err = send(nil); err != nil {
+				return err
+			}
+		case msg, ok := <-ch:
+			if !ok {
+				return nil
+			}
+			e := new(events.Event)
+			if err := json.Unmarshal([]byte(msg.Payload), e); err != nil {
+				log.Printf("[EventStream] Dropping malformed event for %s: %v", userIdentity, err)
+				continue
+			}
+			if !events.After(e.ID, lastID) {
+				continue
+			}
+			if err = send(e); err != nil {
+				return err
+			}
+			lastID = e.ID
+		}
+	}
+}
@@ -0,0 +1,50 @@
+package logic
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+
+	"cloud-dist/core/define"
+	"cloud-dist/core/helper"
+	"cloud-dist/core/internal/types"
+	"cloud-dist/core/svc"
+)
+
+type EventTicketLogic struct {
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewEventTicketLogic(ctx context.Context, svcCtx *svc.ServiceContext) *EventTicketLogic {
+	return &EventTicketLogic{
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// EventTicket issues a short-lived, single-use ticket that opens an event
+// stream as the caller's session. The stream is bound to the caller's access
+// token: it is re-checked while the stream is open and ends when the token
+// expires.
+func (l *EventTicketLogic) EventTicket(req *types.EventTicketRequest, claim *define.UserClaim) (resp *types.EventTicketReply, err error) {
+	if claim == nil {
+		return nil, errors.New("a login session is required")
+	}
+	data, err := json.Marshal(claim)
+	if err != nil {
+		return nil, err
+	}
+	ticket, err := helper.NewLinkToken()
+	if err != nil {
+		return nil, err
+	}
+	key := define.EventTicketPrefix + helper.HashLinkToken(ticket)
+	if err = l.svcCtx.RDB.Set(l.ctx, key, data, define.EventTicketTTL).Err(); err != nil {
+		return nil, err
+	}
+	return &types.EventTicketReply{
+		Ticket:    ticket,
+		ExpiresIn: int(define.EventTicketTTL.Seconds()),
+	}, nil
+}
@@ -0,0 +1,62 @@
+package logic
+
+import (
+	"context"
+	"errors"
+
+	"cloud-dist/core/friendship"
+	"cloud-dist/core/models"
+	"cloud-dist/core/svc"
+)
+
+// Friend row states
+const (
+	friendStatusActive  = friendship.StatusActive
+	friendStatusBlocked = friendship.StatusBlocked
+)
+
+// Friend request states besides pending/accept/reject. A request stored as
+// blocked is invisible to its recipient and shown to its sender as pending, so
+// that a blocked user cannot tell they were blocked.
+const (
+	friendRequestStatusPending   = "pending"
+	friendRequestStatusCancelled = "cancelled"
+	friendRequestStatusBlocked   = "blocked"
+)
+
+// hasBlocked reports whether blocker has blocked the other user
+func hasBlocked(ctx context.Context, svcCtx *svc.ServiceContext, blockerIdentity, otherIdentity string) (bool, error) {
+	var cnt int64
+	err := svcCtx.DB.WithContext(ctx).Model(&models.Friend{}).
+		Where("user_identity = ? AND friend_identity = ? AND status = ?", blockerIdentity, otherIdentity, friendStatusBlocked).
+		Count(&cnt).Error
+	return cnt > 0, err
+}
+
+// blockedBetween reports whether either user has blocked the other
+func blockedBetween(ctx context.Context, svcCtx *svc.ServiceContext, a, b string) (bool, error) {
+	blocked, err := hasBlocked(ctx, svcCtx, a, b)
+	if err != nil || blocked {
+		return blocked, err
+	}
+	return hasBlocked(ctx, svcCtx, b, a)
+}
+
+// findFriendUser checks that the target of a friend action is a real user
+func findFriendUser(ctx context.Context, svcCtx *svc.ServiceContext, userIdentity, targetIdentity string) error {
+	if targetIdentity == "" {
+		return errors.New("user identity is required")
+	}
+	if targetIdentity == userIdentity {
+		return errors.New("cannot perform this action on yourself")
+	}
+	var cnt int64
+	if err := svcCtx.DB.WithContext(ctx).Model(&models.UserBasic{}).
+		Where("identity = ?", targetIdentity).Count(&cnt).Error; err != nil {
+		return err
+	}
+	if cnt == 0 {
+		return errors.New("user not found")
+	}
+	return nil
+}
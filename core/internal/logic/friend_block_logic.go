@@ -0,0 +1,67 @@
+package logic
+
+import (
+	"context"
+
+	"cloud-dist/core/audit"
+	"cloud-dist/core/friendship"
+	"cloud-dist/core/helper"
+	"cloud-dist/core/internal/types"
+	"cloud-dist/core/models"
+	"cloud-dist/core/svc"
+
+	"gorm.io/gorm"
+)
+
+type FriendBlockLogic struct {
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewFriendBlockLogic(ctx context.Context, svcCtx *svc.ServiceContext) *FriendBlockLogic {
+	return &FriendBlockLogic{
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// FriendBlock blocks another user. The caller keeps a blocked row, the other
+// user's row is removed unless they blocked the caller too, pending requests
+// between them are withdrawn and shares between them are hidden until the
+// block is lifted.
+func (l *FriendBlockLogic) FriendBlock(req *types.FriendBlockRequest, userIdentity string) (resp *types.FriendBlockReply, err error) {
+	if err = findFriendUser(l.ctx, l.svcCtx, userIdentity, req.UserIdentity); err != nil {
+		return nil, err
+	}
+
+	err = l.svcCtx.DB.WithContext(l.ctx).Transaction(func(tx *gorm.DB) error {
+		if err := friendship.Sever(tx, userIdentity, req.UserIdentity); err != nil {
+			return err
+		}
+		if err := tx.Create(&models.Friend{
+			Identity:       helper.UUID(),
+			UserIdentity:   userIdentity,
+			FriendIdentity: req.UserIdentity,
+			Status:         friendStatusBlocked,
+		}).Error; err != nil {
+			return err
+		}
+
+		// Requests the caller sent are simply cancelled
+		if err := tx.Model(&models.FriendRequest{}).
+			Where("from_user_identity = ? AND to_user_identity = ? AND status = ?", userIdentity, req.UserIdentity, friendRequestStatusPending).
+			Update("status", friendRequestStatusCancelled).Error; err != nil {
+			return err
+		}
+		// Requests from the blocked user keep looking pending to them
+		return tx.Model(&models.FriendRequest{}).
+			Where("from_user_identity = ? AND to_user_identity = ? AND status = ?", req.UserIdentity, userIdentity, friendRequestStatusPending).
+			Update("status", friendRequestStatusBlocked).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	audit.Record(l.ctx, l.svcCtx.DB, audit.Entry{Actor: userIdentity, Action: audit.ActionFriendBlock,
+		TargetType: audit.TargetUser, Target: req.UserIdentity})
+	return &types.FriendBlockReply{}, nil
+}
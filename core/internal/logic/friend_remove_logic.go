@@ -0,0 +1,50 @@
+package logic
+
+import (
+	"context"
+	"errors"
+
+	"cloud-dist/core/audit"
+	"cloud-dist/core/friendship"
+	"cloud-dist/core/internal/types"
+	"cloud-dist/core/models"
+	"cloud-dist/core/svc"
+
+	"gorm.io/gorm"
+)
+
+type FriendRemoveLogic struct {
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewFriendRemoveLogic(ctx context.Context, svcCtx *svc.ServiceContext) *FriendRemoveLogic {
+	return &FriendRemoveLogic{
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// FriendRemove ends a friendship for both users. Regular shares between them
+// stop working because access requires an active friendship.
+func (l *FriendRemoveLogic) FriendRemove(req *types.FriendRemoveRequest, userIdentity string) (resp *types.FriendRemoveReply, err error) {
+	var cnt int64
+	if err = l.svcCtx.DB.WithContext(l.ctx).Model(&models.Friend{}).
+		Where("user_identity = ? AND friend_identity = ? AND status = ?", userIdentity, req.UserIdentity, friendStatusActive).
+		Count(&cnt).Error; err != nil {
+		return nil, err
+	}
+	if cnt == 0 {
+		return nil, errors.New("not friends")
+	}
+
+	err = l.svcCtx.DB.WithContext(l.ctx).Transaction(func(tx *gorm.DB) error {
+		return friendship.Sever(tx, userIdentity, req.UserIdentity)
+	})
+	if err != nil {
+		return nil, err
+	}
+	audit.Record(l.ctx, l.svcCtx.DB, audit.Entry{Actor: userIdentity, Action: audit.ActionFriendRemove,
+		TargetType: audit.TargetUser, Target: req.UserIdentity})
+	return &types.FriendRemoveReply{}, nil
+}
@@ -42,7 +42,9 @@ func (l *FriendRequestListLogic) FriendRequestList(req *types.FriendRequestListR
 		query = query.Where("friend_request.from_user_identity = ? OR friend_request.to_user_identity = ?", userIdentity, userIdentity)
 	}
 
-	query = query.Where("friend_request.deleted_at IS NULL").
+	// Requests from blocked users are never shown to the blocker
+	query = query.Where("NOT (friend_request.status = ? AND friend_request.to_user_identity = ?)", friendRequestStatusBlocked, userIdentity).
+		Where("friend_request.deleted_at IS NULL").
 		Order("friend_request.created_at DESC")
 
 	var results []struct {
@@ -68,13 +70,19 @@ func (l *FriendRequestListLogic) FriendRequestList(req *types.FriendRequestListR
 			createdAt = time.Now().Format(define.Datetime)
 		}
 
+		// ...and look pending to the blocked sender
+		status := r.Status
+		if status == friendRequestStatusBlocked {
+			status = friendRequestStatusPending
+		}
+
 		resp.List = append(resp.List, &types.FriendRequestItem{
 			Identity:         r.Identity,
 			FromUserIdentity: r.FromUserIdentity,
 			ToUserIdentity:   r.ToUserIdentity,
 			FromUserName:     r.FromUserName,
 			ToUserName:       r.ToUserName,
-			Status:           r.Status,
+			Status:           status,
 			Message:          r.Message,
 			CreatedAt:        createdAt,
 		})
@@ -4,6 +4,8 @@ import (
 	"context"
 	"errors"
 
+	"cloud-dist/core/audit"
+	"cloud-dist/core/events"
 	"cloud-dist/core/helper"
 	"cloud-dist/core/svc"
 	"cloud-dist/core/internal/types"
@@ -82,6 +84,21 @@ func (l *FriendRequestRespondLogic) FriendRequestRespond(req *types.FriendReques
 		}
 	}
 
+	action, auditAction := "declined", audit.ActionFriendDecline
+	if newStatus == "accept" {
+		action, auditAction = "accepted", audit.ActionFriendAccept
+	}
+	audit.Record(l.ctx, l.svcCtx.DB, audit.Entry{Actor: userIdentity, Action: auditAction,
+		TargetType: audit.TargetUser, Target: fr.FromUserIdentity, Detail: map[string]interface{}{"request": fr.Identity}})
+	notify(l.ctx, l.svcCtx, fr.FromUserIdentity, events.FriendRequestResponded,
+		"Friend request "+action,
+		displayName(l.ctx, l.svcCtx, userIdentity)+" "+action+" your friend request.",
+		map[string]interface{}{
+			"identity":         fr.Identity,
+			"to_user_identity": userIdentity,
+			"action":           newStatus,
+		})
+
 	resp = &types.FriendRequestRespondReply{}
 	return
 }
@@ -3,8 +3,9 @@ package logic
 import (
 	"context"
 	"errors"
-	"strings"
 
+	"cloud-dist/core/audit"
+	"cloud-dist/core/events"
 	"cloud-dist/core/helper"
 	"cloud-dist/core/svc"
 	"cloud-dist/core/internal/types"
@@ -26,26 +27,8 @@ func NewFriendRequestSendLogic(ctx context.Context, svcCtx *svc.ServiceContext)
 }
 
 func (l *FriendRequestSendLogic) FriendRequestSend(req *types.FriendRequestSendRequest, fromUserIdentity string) (resp *types.FriendRequestSendReply, err error) {
-	// Find target user by email or username
-	var toUser models.UserBasic
-	toUserIdentity := strings.TrimSpace(req.ToUserIdentity)
-
-	// Check if it's an email or username
-	if strings.Contains(toUserIdentity, "@") {
-		// It's an email
-		err = l.svcCtx.DB.WithContext(l.ctx).
-			Where("email = ?", toUserIdentity).
-			First(&toUser).Error
-	} else {
-		// It's a username
-		err = l.svcCtx.DB.WithContext(l.ctx).
-			Where("name = ?", toUserIdentity).
-			First(&toUser).Error
-	}
-
-	if errors.Is(err, gorm.ErrRecordNotFound) {
-		return nil, errors.New("user not found")
-	}
+	// Undiscoverable and unknown accounts get the same answer
+	toUser, err := findDiscoverableUser(l.ctx, l.svcCtx, req.ToUserIdentity)
 	if err != nil {
 		return nil, err
 	}
@@ -55,10 +38,19 @@ func (l *FriendRequestSendLogic) FriendRequestSend(req *types.FriendRequestSendR
 		return nil, errors.New("cannot send friend request to yourself")
 	}
 
+	// The sender has to lift their own block first
+	blocked, err := hasBlocked(l.ctx, l.svcCtx, fromUserIdentity, toUser.Identity)
+	if err != nil {
+		return nil, err
+	}
+	if blocked {
+		return nil, errors.New("unblock this user before sending a friend request")
+	}
+
 	// Check if already friends
 	var friendCount int64
 	err = l.svcCtx.DB.WithContext(l.ctx).Model(&models.Friend{}).
-		Where("user_identity = ? AND friend_identity = ?", fromUserIdentity, toUser.Identity).
+		Where("user_identity = ? AND friend_identity = ? AND status = ?", fromUserIdentity, toUser.Identity, friendStatusActive).
 		Count(&friendCount).Error
 	if err != nil {
 		return nil, err
@@ -67,11 +59,12 @@ func (l *FriendRequestSendLogic) FriendRequestSend(req *types.FriendRequestSendR
 		return nil, errors.New("already friends")
 	}
 
-	// Check if there's a pending request
+	// Check if there's a pending request (requests swallowed by a block count as pending)
 	var existingRequest models.FriendRequest
 	err = l.svcCtx.DB.WithContext(l.ctx).
-		Where("((from_user_identity = ? AND to_user_identity = ?) OR (from_user_identity = ? AND to_user_identity = ?)) AND status = ?",
-			fromUserIdentity, toUser.Identity, toUser.Identity, fromUserIdentity, "pending").
+		Where("((from_user_identity = ? AND to_user_identity = ?) OR (from_user_identity = ? AND to_user_identity = ?)) AND status IN ?",
+			fromUserIdentity, toUser.Identity, toUser.Identity, fromUserIdentity,
+			[]string{friendRequestStatusPending, friendRequestStatusBlocked}).
 		First(&existingRequest).Error
 	if err == nil {
 		return nil, errors.New("friend request already exists")
@@ -80,12 +73,23 @@ func (l *FriendRequestSendLogic) FriendRequestSend(req *types.FriendRequestSendR
 		return nil, err
 	}
 
+	// If the recipient blocked the sender, the request is stored but never
+	// delivered, so the sender sees the same result as a normal request
+	status := friendRequestStatusPending
+	blocked, err = hasBlocked(l.ctx, l.svcCtx, toUser.Identity, fromUserIdentity)
+	if err != nil {
+		return nil, err
+	}
+	if blocked {
+		status = friendRequestStatusBlocked
+	}
+
 	// Create friend request
 	fr := &models.FriendRequest{
 		Identity:         helper.UUID(),
 		FromUserIdentity: fromUserIdentity,
 		ToUserIdentity:   toUser.Identity,
-		Status:           "pending",
+		Status:           status,
 		Message:          req.Message,
 	}
 
@@ -93,6 +97,19 @@ func (l *FriendRequestSendLogic) FriendRequestSend(req *types.FriendRequestSendR
 	if err != nil {
 		return nil, err
 	}
+	audit.Record(l.ctx, l.svcCtx.DB, audit.Entry{Actor: fromUserIdentity, Action: audit.ActionFriendRequest,
+		TargetType: audit.TargetUser, Target: toUser.Identity, Detail: map[string]interface{}{"request": fr.Identity}})
+
+	if status == friendRequestStatusPending {
+		notify(l.ctx, l.svcCtx, toUser.Identity, events.FriendRequestReceived,
+			"New friend request",
+			displayName(l.ctx, l.svcCtx, fromUserIdentity)+" wants to add you as a friend.",
+			map[string]interface{}{
+				"identity":           fr.Identity,
+				"from_user_identity": fromUserIdentity,
+				"message":            fr.Message,
+			})
+	}
 
 	resp = &types.FriendRequestSendReply{
 		Identity: fr.Identity,
@@ -0,0 +1,211 @@
+package logic
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"cloud-dist/core/audit"
+	"cloud-dist/core/models"
+	"cloud-dist/core/svc"
+
+	"gorm.io/gorm"
+)
+
+// Friend share permissions
+const (
+	friendSharePermissionView = "view"
+	friendSharePermissionEdit = "edit"
+)
+
+// Recipient inbox states
+const (
+	friendShareStateInbox     = "inbox"
+	friendShareStateArchived  = "archived"
+	friendShareStateDismissed = "dismissed"
+)
+
+// maxFolderDepth bounds the parent walk when checking that an item lies
+// inside a shared folder
+const maxFolderDepth = 64
+
+// normalizeFriendSharePermission defaults an empty permission to view and
+// rejects unknown values.
+func normalizeFriendSharePermission(permission string) (string, error) {
+	switch permission {
+	case "":
+		return friendSharePermissionView, nil
+	case friendSharePermissionView, friendSharePermissionEdit:
+		return permission, nil
+	}
+	return "", errors.New("permission must be view or edit")
+}
+
+// friendShareAccessible reports whether a friend share may still be opened.
+// A block on either side always hides the share. Shares created from an email
+// invite were granted without a friendship, so only regular shares require the
+// two users to be active friends.
+func friendShareAccessible(ctx context.Context, svcCtx *svc.ServiceContext, fs *models.FriendShare) (bool, error) {
+	blocked, err := blockedBetween(ctx, svcCtx, fs.FromUserIdentity, fs.ToUserIdentity)
+	if err != nil || blocked {
+		return false, err
+	}
+	if fs.InviteIdentity != "" {
+		return true, nil
+	}
+	var friendCount int64
+	err = svcCtx.DB.WithContext(ctx).Model(&models.Friend{}).
+		Where("((user_identity = ? AND friend_identity = ?) OR (user_identity = ? AND friend_identity = ?)) AND status = ?",
+			fs.FromUserIdentity, fs.ToUserIdentity,
+			fs.ToUserIdentity, fs.FromUserIdentity,
+			"active").
+		Count(&friendCount).Error
+	if err != nil {
+		return false, err
+	}
+	return friendCount > 0, nil
+}
+
+// loadReceivedFriendShare loads a share for its recipient and re-checks access
+// on every call, so revoking the share or the friendship takes effect at once.
+// When edit is true the share must grant edit permission.
+func loadReceivedFriendShare(ctx context.Context, svcCtx *svc.ServiceContext, shareIdentity, userIdentity string, edit bool) (*models.FriendShare, error) {
+	fs := new(models.FriendShare)
+	err := svcCtx.DB.WithContext(ctx).
+		Where("identity = ? AND to_user_identity = ?", shareIdentity, userIdentity).
+		First(fs).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, errors.New("share record not found")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	accessible, err := friendShareAccessible(ctx, svcCtx, fs)
+	if err != nil {
+		return nil, err
+	}
+	if !accessible {
+		return nil, errors.New("access denied: friendship relationship not found")
+	}
+	if err = checkFriendShareExpiry(fs); err != nil {
+		return nil, err
+	}
+	if edit && fs.Permission != friendSharePermissionEdit {
+		return nil, errors.New("access denied: this share is view-only")
+	}
+	return fs, nil
+}
+
+// resolveFriendShareItem returns the owner's user_repository row for an item of
+// the share. An empty identity means the shared item itself; anything else must
+// be the shared item or lie somewhere below the shared folder.
+func resolveFriendShareItem(ctx context.Context, svcCtx *svc.ServiceContext, fs *models.FriendShare, itemIdentity string) (*models.UserRepository, error) {
+	root := new(models.UserRepository)
+	err := svcCtx.DB.WithContext(ctx).
+		Where("identity = ? AND user_identity = ?", fs.UserRepositoryIdentity, friendShareOwner(fs)).
+		First(root).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, errors.New("shared item no longer exists")
+	}
+	if err != nil {
+		return nil, err
+	}
+	if itemIdentity == "" || itemIdentity == root.Identity {
+		return root, nil
+	}
+
+	item := new(models.UserRepository)
+	err = svcCtx.DB.WithContext(ctx).
+		Where("identity = ? AND user_identity = ?", itemIdentity, friendShareOwner(fs)).
+		First(item).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, errors.New("file not found in this share")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	parentID := item.ParentId
+	for depth := 0; parentID != 0 && depth < maxFolderDepth; depth++ {
+		if parentID == root.ID {
+			return item, nil
+		}
+		parent := new(models.UserRepository)
+		err = svcCtx.DB.WithContext(ctx).Select("parent_id").
+			Where("id = ? AND user_identity = ?", parentID, friendShareOwner(fs)).
+			First(parent).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		parentID = parent.ParentId
+	}
+	return nil, errors.New("file not found in this share")
+}
+
+// friendShareOwner returns whose drive holds the shared item: the team space
+// it was shared from, or the sender's own drive
+func friendShareOwner(fs *models.FriendShare) string {
+	if fs.SpaceIdentity != "" {
+		return fs.SpaceIdentity
+	}
+	return fs.FromUserIdentity
+}
+
+// friendShareAuditContext is used when a friend changes a shared folder
+// through the owner's file logic. That logic records the entry with the owner
+// as subject and the signed-in friend as actor; this adds which share it was.
+func friendShareAuditContext(ctx context.Context, fs *models.FriendShare) context.Context {
+	return audit.WithDetail(ctx, map[string]interface{}{"friend_share": fs.Identity})
+}
+
+// friendShareBilling returns the user whose quota pays for the shared item
+func friendShareBilling(ctx context.Context, svcCtx *svc.ServiceContext, fs *models.FriendShare) (string, error) {
+	if fs.SpaceIdentity == "" {
+		return fs.FromUserIdentity, nil
+	}
+	space := new(models.Space)
+	err := svcCtx.DB.WithContext(ctx).Select("owner_identity").Where("identity = ?", fs.SpaceIdentity).First(space).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return "", errors.New("shared item no longer exists")
+	}
+	if err != nil {
+		return "", err
+	}
+	return space.OwnerIdentity, nil
+}
+
+// resolveFriendShareFolder is resolveFriendShareItem restricted to folders
+func resolveFriendShareFolder(ctx context.Context, svcCtx *svc.ServiceContext, fs *models.FriendShare, folderIdentity string) (*models.UserRepository, error) {
+	folder, err := resolveFriendShareItem(ctx, svcCtx, fs, folderIdentity)
+	if err != nil {
+		return nil, err
+	}
+	if folder.RepositoryIdentity != "" {
+		return nil, errors.New("not a folder")
+	}
+	return folder, nil
+}
+
+// checkFriendShareExpiry refuses shares whose expiry set by the sender has passed
+func checkFriendShareExpiry(fs *models.FriendShare) error {
+	if fs.ExpiresAt != nil && time.Now().After(*fs.ExpiresAt) {
+		return errors.New("this share has expired")
+	}
+	return nil
+}
+
+// markFriendShare records the first time the recipient opened or saved the
+// share, so the sender can see it. column is opened_at or saved_at.
+func markFriendShare(ctx context.Context, svcCtx *svc.ServiceContext, fs *models.FriendShare, column string) {
+	err := svcCtx.DB.WithContext(ctx).Model(&models.FriendShare{}).
+		Where("identity = ? AND "+column+" IS NULL", fs.Identity).
+		UpdateColumn(column, time.Now()).Error
+	if err != nil {
+		log.Printf("[FriendShare] Failed to set %s on share %s: %v", column, fs.Identity, err)
+	}
+}
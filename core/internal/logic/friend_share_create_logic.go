@@ -3,7 +3,10 @@ package logic
 import (
 	"context"
 	"errors"
+	"time"
 
+	"cloud-dist/core/audit"
+	"cloud-dist/core/events"
 	"cloud-dist/core/helper"
 	"cloud-dist/core/svc"
 	"cloud-dist/core/internal/types"
@@ -24,7 +27,17 @@ func NewFriendShareCreateLogic(ctx context.Context, svcCtx *svc.ServiceContext)
 	}
 }
 
-func (l *FriendShareCreateLogic) FriendShareCreate(req *types.FriendShareCreateRequest, fromUserIdentity string) (resp *types.FriendShareCreateReply, err error) {
+// FriendShareCreate shares an item with a friend. ownerIdentity owns the item:
+// the sender, or the team space selected for the request.
+func (l *FriendShareCreateLogic) FriendShareCreate(req *types.FriendShareCreateRequest, fromUserIdentity, ownerIdentity, spaceIdentity string) (resp *types.FriendShareCreateReply, err error) {
+	permission, err := normalizeFriendSharePermission(req.Permission)
+	if err != nil {
+		return nil, err
+	}
+	if req.ExpiredTime < 0 {
+		return nil, errors.New("expired time cannot be negative")
+	}
+
 	// Verify that users are friends
 	var friendCount int64
 	err = l.svcCtx.DB.WithContext(l.ctx).Model(&models.Friend{}).
@@ -37,10 +50,10 @@ func (l *FriendShareCreateLogic) FriendShareCreate(req *types.FriendShareCreateR
 		return nil, errors.New("users are not friends")
 	}
 
-	// Get user repository info
+	// Get user repository info (a file, or a folder when repository_identity is empty)
 	var ur models.UserRepository
 	err = l.svcCtx.DB.WithContext(l.ctx).
-		Where("identity = ? AND user_identity = ?", req.UserRepositoryIdentity, fromUserIdentity).
+		Where("identity = ? AND user_identity = ?", req.UserRepositoryIdentity, ownerIdentity).
 		First(&ur).Error
 	if errors.Is(err, gorm.ErrRecordNotFound) {
 		return nil, errors.New("file not found")
@@ -58,12 +71,34 @@ func (l *FriendShareCreateLogic) FriendShareCreate(req *types.FriendShareCreateR
 		UserRepositoryIdentity: req.UserRepositoryIdentity,
 		Message:                req.Message,
 		IsRead:                 false,
+		Permission:             permission,
+		SpaceIdentity:          spaceIdentity,
+	}
+	if req.ExpiredTime > 0 {
+		expiresAt := time.Now().Add(time.Duration(req.ExpiredTime) * time.Second)
+		fs.ExpiresAt = &expiresAt
 	}
 
 	err = l.svcCtx.DB.WithContext(l.ctx).Create(fs).Error
 	if err != nil {
 		return nil, err
 	}
+	audit.Record(l.ctx, l.svcCtx.DB, audit.Entry{Actor: fromUserIdentity, Subject: ownerIdentity, Action: audit.ActionShareCreate,
+		TargetType: audit.TargetShare, Target: fs.Identity, Detail: map[string]interface{}{
+			"file":         req.UserRepositoryIdentity,
+			"friend":       fs.ToUserIdentity,
+			"permission":   fs.Permission,
+			"expired_time": req.ExpiredTime,
+		}})
+
+	notify(l.ctx, l.svcCtx, fs.ToUserIdentity, events.FriendShareReceived,
+		"New shared item",
+		displayName(l.ctx, l.svcCtx, fromUserIdentity)+" shared "+ur.Name+ur.Ext+" with you.",
+		map[string]interface{}{
+			"identity":           fs.Identity,
+			"from_user_identity": fs.FromUserIdentity,
+			"message":            fs.Message,
+		})
 
 	resp = &types.FriendShareCreateReply{
 		Identity: fs.Identity,
@@ -46,29 +46,38 @@ func (l *FriendShareDownloadLogic) FriendShareDownload(req *types.FriendShareDow
 		return nil, "", "", errors.New("access denied: you are not authorized to download this file")
 	}
 
-	// Verify that both users are friends
-	// Check if friendship exists (bidirectional check)
-	var friendCount int64
-	err = l.svcCtx.DB.WithContext(l.ctx).Model(&models.Friend{}).
-		Where("((user_identity = ? AND friend_identity = ?) OR (user_identity = ? AND friend_identity = ?)) AND status = ?",
-			fs.FromUserIdentity, fs.ToUserIdentity,
-			fs.ToUserIdentity, fs.FromUserIdentity,
-			"active").
-		Count(&friendCount).Error
+	// Verify that both users are friends (invite-based shares are exempt)
+	accessible, err := friendShareAccessible(l.ctx, l.svcCtx, fs)
 	if err != nil {
 		log.Printf("[FriendShareDownload] Failed to check friendship: %v", err)
 		return nil, "", "", err
 	}
-
-	if friendCount == 0 {
+	if !accessible {
 		log.Printf("[FriendShareDownload] Access denied: Users %s and %s are not friends", fs.FromUserIdentity, fs.ToUserIdentity)
 		return nil, "", "", errors.New("access denied: friendship relationship not found")
 	}
 
+	// The sender's expiry only applies to the recipient
+	isRecipient := fs.ToUserIdentity == userIdentity
+	if isRecipient {
+		if err = checkFriendShareExpiry(fs); err != nil {
+			return nil, "", "", err
+		}
+	}
+
+	// Folder shares download one file from inside the shared folder
+	item, err := resolveFriendShareItem(l.ctx, l.svcCtx, fs, req.ItemIdentity)
+	if err != nil {
+		return nil, "", "", err
+	}
+	if item.RepositoryIdentity == "" {
+		return nil, "", "", errors.New("folders cannot be downloaded, choose a file inside it")
+	}
+
 	// Get file info from repository_pool
 	rp := new(models.RepositoryPool)
 	err = l.svcCtx.DB.WithContext(l.ctx).
-		Where("identity = ?", fs.RepositoryIdentity).
+		Where("identity = ?", item.RepositoryIdentity).
 		First(rp).Error
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
@@ -111,6 +120,10 @@ func (l *FriendShareDownloadLogic) FriendShareDownload(req *types.FriendShareDow
 		contentType = *headResp.ContentType
 	}
 
+	if isRecipient {
+		markFriendShare(l.ctx, l.svcCtx, fs, "opened_at")
+	}
+
 	log.Printf("[FriendShareDownload] Successfully prepared file download: key=%s, name=%s, type=%s", s3Key, fileName, contentType)
 	return fileData, fileName, contentType, nil
 }
@@ -0,0 +1,54 @@
+package logic
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"cloud-dist/core/define"
+	"cloud-dist/core/internal/types"
+	"cloud-dist/core/models"
+	"cloud-dist/core/svc"
+)
+
+type FriendShareExpiryUpdateLogic struct {
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewFriendShareExpiryUpdateLogic(ctx context.Context, svcCtx *svc.ServiceContext) *FriendShareExpiryUpdateLogic {
+	return &FriendShareExpiryUpdateLogic{
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *FriendShareExpiryUpdateLogic) FriendShareExpiryUpdate(req *types.FriendShareExpiryUpdateRequest, userIdentity string) (resp *types.FriendShareExpiryUpdateReply, err error) {
+	if req.ExpiredTime < 0 {
+		return nil, errors.New("expired time cannot be negative")
+	}
+
+	// Only the sender can extend, shorten or clear the expiry
+	fs := new(models.FriendShare)
+	err = l.svcCtx.DB.WithContext(l.ctx).
+		Where("identity = ? AND from_user_identity = ?", req.Identity, userIdentity).
+		First(fs).Error
+	if err != nil {
+		return nil, errors.New("share record not found")
+	}
+
+	resp = new(types.FriendShareExpiryUpdateReply)
+	var expiresAt *time.Time
+	if req.ExpiredTime > 0 {
+		t := time.Now().Add(time.Duration(req.ExpiredTime) * time.Second)
+		expiresAt = &t
+		resp.ExpiresAt = t.Format(define.Datetime)
+	}
+	err = l.svcCtx.DB.WithContext(l.ctx).Model(&models.FriendShare{}).
+		Where("identity = ?", fs.Identity).
+		Update("expires_at", expiresAt).Error
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
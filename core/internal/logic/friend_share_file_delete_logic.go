@@ -0,0 +1,48 @@
+package logic
+
+import (
+	"context"
+	"errors"
+
+	"cloud-dist/core/internal/types"
+	"cloud-dist/core/svc"
+)
+
+type FriendShareFileDeleteLogic struct {
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewFriendShareFileDeleteLogic(ctx context.Context, svcCtx *svc.ServiceContext) *FriendShareFileDeleteLogic {
+	return &FriendShareFileDeleteLogic{
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *FriendShareFileDeleteLogic) FriendShareFileDelete(req *types.FriendShareFileDeleteRequest, userIdentity string) (resp *types.FriendShareFileDeleteReply, err error) {
+	fs, err := loadReceivedFriendShare(l.ctx, l.svcCtx, req.ShareIdentity, userIdentity, true)
+	if err != nil {
+		return nil, err
+	}
+	item, err := resolveFriendShareItem(l.ctx, l.svcCtx, fs, req.Identity)
+	if err != nil {
+		return nil, err
+	}
+	// Only the owner can delete what they shared
+	if item.Identity == fs.UserRepositoryIdentity {
+		return nil, errors.New("the shared item itself cannot be deleted")
+	}
+
+	billing, err := friendShareBilling(l.ctx, l.svcCtx, fs)
+	if err != nil {
+		return nil, err
+	}
+	_, err = NewUserFileDeleteLogic(friendShareAuditContext(l.ctx, fs), l.svcCtx).UserFileDelete(&types.UserFileDeleteRequest{
+		Identity: item.Identity,
+	}, friendShareOwner(fs), billing)
+	if err != nil {
+		return nil, err
+	}
+	return &types.FriendShareFileDeleteReply{}, nil
+}
@@ -0,0 +1,40 @@
+package logic
+
+import (
+	"context"
+
+	"cloud-dist/core/internal/types"
+	"cloud-dist/core/svc"
+)
+
+type FriendShareFileRenameLogic struct {
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewFriendShareFileRenameLogic(ctx context.Context, svcCtx *svc.ServiceContext) *FriendShareFileRenameLogic {
+	return &FriendShareFileRenameLogic{
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *FriendShareFileRenameLogic) FriendShareFileRename(req *types.FriendShareFileRenameRequest, userIdentity string) (resp *types.FriendShareFileRenameReply, err error) {
+	fs, err := loadReceivedFriendShare(l.ctx, l.svcCtx, req.ShareIdentity, userIdentity, true)
+	if err != nil {
+		return nil, err
+	}
+	item, err := resolveFriendShareItem(l.ctx, l.svcCtx, fs, req.Identity)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = NewUserFileNameUpdateLogic(friendShareAuditContext(l.ctx, fs), l.svcCtx).UserFileNameUpdate(&types.UserFileNameUpdateRequest{
+		Identity: item.Identity,
+		Name:     req.Name,
+	}, friendShareOwner(fs))
+	if err != nil {
+		return nil, err
+	}
+	return &types.FriendShareFileRenameReply{}, nil
+}
@@ -0,0 +1,48 @@
+package logic
+
+import (
+	"context"
+
+	"cloud-dist/core/internal/types"
+	"cloud-dist/core/svc"
+)
+
+type FriendShareFileSaveLogic struct {
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewFriendShareFileSaveLogic(ctx context.Context, svcCtx *svc.ServiceContext) *FriendShareFileSaveLogic {
+	return &FriendShareFileSaveLogic{
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// FriendShareFileSave places an uploaded file into a shared folder. The file
+// belongs to the share owner and counts against the owner's quota.
+func (l *FriendShareFileSaveLogic) FriendShareFileSave(req *types.FriendShareFileSaveRequest, userIdentity string) (resp *types.FriendShareFileSaveReply, err error) {
+	fs, err := loadReceivedFriendShare(l.ctx, l.svcCtx, req.ShareIdentity, userIdentity, true)
+	if err != nil {
+		return nil, err
+	}
+	parent, err := resolveFriendShareFolder(l.ctx, l.svcCtx, fs, req.ParentIdentity)
+	if err != nil {
+		return nil, err
+	}
+
+	billing, err := friendShareBilling(l.ctx, l.svcCtx, fs)
+	if err != nil {
+		return nil, err
+	}
+	_, err = NewUserRepositorySaveLogic(friendShareAuditContext(l.ctx, fs), l.svcCtx).UserRepositorySave(&types.UserRepositorySaveRequest{
+		ParentId:           parent.ID,
+		RepositoryIdentity: req.RepositoryIdentity,
+		Ext:                req.Ext,
+		Name:               req.Name,
+	}, friendShareOwner(fs), billing)
+	if err != nil {
+		return nil, err
+	}
+	return &types.FriendShareFileSaveReply{}, nil
+}
@@ -0,0 +1,41 @@
+package logic
+
+import (
+	"context"
+
+	"cloud-dist/core/internal/types"
+	"cloud-dist/core/svc"
+)
+
+type FriendShareFolderCreateLogic struct {
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewFriendShareFolderCreateLogic(ctx context.Context, svcCtx *svc.ServiceContext) *FriendShareFolderCreateLogic {
+	return &FriendShareFolderCreateLogic{
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *FriendShareFolderCreateLogic) FriendShareFolderCreate(req *types.FriendShareFolderCreateRequest, userIdentity string) (resp *types.FriendShareFolderCreateReply, err error) {
+	fs, err := loadReceivedFriendShare(l.ctx, l.svcCtx, req.ShareIdentity, userIdentity, true)
+	if err != nil {
+		return nil, err
+	}
+	parent, err := resolveFriendShareFolder(l.ctx, l.svcCtx, fs, req.ParentIdentity)
+	if err != nil {
+		return nil, err
+	}
+
+	// The folder is created directly in the owner's drive or space
+	reply, err := NewUserFolderCreateLogic(friendShareAuditContext(l.ctx, fs), l.svcCtx).UserFolderCreate(&types.UserFolderCreateRequest{
+		ParentId: parent.ID,
+		Name:     req.Name,
+	}, friendShareOwner(fs))
+	if err != nil {
+		return nil, err
+	}
+	return &types.FriendShareFolderCreateReply{Identity: reply.Identity}, nil
+}
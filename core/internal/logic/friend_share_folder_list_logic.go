@@ -0,0 +1,52 @@
+package logic
+
+import (
+	"context"
+
+	"cloud-dist/core/internal/types"
+	"cloud-dist/core/svc"
+)
+
+type FriendShareFolderListLogic struct {
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewFriendShareFolderListLogic(ctx context.Context, svcCtx *svc.ServiceContext) *FriendShareFolderListLogic {
+	return &FriendShareFolderListLogic{
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// FriendShareFolderList lists a folder inside a received folder share. The
+// listing reflects the owner's files live.
+func (l *FriendShareFolderListLogic) FriendShareFolderList(req *types.FriendShareFolderListRequest, userIdentity string) (resp *types.UserFileListReply, err error) {
+	fs, err := loadReceivedFriendShare(l.ctx, l.svcCtx, req.ShareIdentity, userIdentity, false)
+	if err != nil {
+		return nil, err
+	}
+	folder, err := resolveFriendShareFolder(l.ctx, l.svcCtx, fs, req.Identity)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err = NewUserFileListLogic(l.ctx, l.svcCtx).UserFileList(&types.UserFileListRequest{
+		Identity: folder.Identity,
+		Page:     req.Page,
+		Size:     req.Size,
+	}, friendShareOwner(fs))
+	if err != nil {
+		return nil, err
+	}
+
+	markFriendShare(l.ctx, l.svcCtx, fs, "opened_at")
+
+	// The owner's download links do not work for the recipient
+	for _, file := range resp.List {
+		if file.RepositoryIdentity != "" {
+			file.Path = "/friend/share/download?identity=" + fs.Identity + "&item=" + file.Identity
+		}
+	}
+	return
+}
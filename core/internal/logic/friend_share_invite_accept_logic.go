@@ -0,0 +1,57 @@
+package logic
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"cloud-dist/core/internal/types"
+	"cloud-dist/core/models"
+	"cloud-dist/core/svc"
+
+	"gorm.io/gorm"
+)
+
+type FriendShareInviteAcceptLogic struct {
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewFriendShareInviteAcceptLogic(ctx context.Context, svcCtx *svc.ServiceContext) *FriendShareInviteAcceptLogic {
+	return &FriendShareInviteAcceptLogic{
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// FriendShareInviteAccept turns an invite sent to the user's email into a
+// friend share. Only then does the sender learn who the recipient is.
+func (l *FriendShareInviteAcceptLogic) FriendShareInviteAccept(req *types.FriendShareInviteAcceptRequest, userIdentity string) (resp *types.FriendShareInviteAcceptReply, err error) {
+	user := new(models.UserBasic)
+	if err = l.svcCtx.DB.WithContext(l.ctx).Where("identity = ?", userIdentity).First(user).Error; err != nil {
+		return nil, err
+	}
+
+	invite := new(models.ShareInvite)
+	err = l.svcCtx.DB.WithContext(l.ctx).
+		Where("identity = ? AND email = ? AND status = ? AND expires_at > ?",
+			req.Identity, user.Email, shareInviteStatusPending, time.Now()).
+		Where("from_user_identity <> ?", userIdentity).
+		First(invite).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, errors.New("invite not found")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	fs, err := claimShareInvite(l.ctx, l.svcCtx, user, invite)
+	if err != nil {
+		return nil, err
+	}
+	if fs == nil {
+		// Revoked while we were looking
+		return nil, errors.New("invite not found")
+	}
+	return &types.FriendShareInviteAcceptReply{Identity: fs.Identity}, nil
+}
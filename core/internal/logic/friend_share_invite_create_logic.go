@@ -0,0 +1,104 @@
+package logic
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/url"
+	"strings"
+	"time"
+
+	"cloud-dist/core/define"
+	"cloud-dist/core/helper"
+	"cloud-dist/core/internal/types"
+	"cloud-dist/core/models"
+	"cloud-dist/core/svc"
+
+	"gorm.io/gorm"
+)
+
+type FriendShareInviteCreateLogic struct {
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewFriendShareInviteCreateLogic(ctx context.Context, svcCtx *svc.ServiceContext) *FriendShareInviteCreateLogic {
+	return &FriendShareInviteCreateLogic{
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *FriendShareInviteCreateLogic) FriendShareInviteCreate(req *types.FriendShareInviteCreateRequest, fromUserIdentity string) (resp *types.FriendShareInviteCreateReply, err error) {
+	email := strings.ToLower(strings.TrimSpace(req.Email))
+	if email == "" || !strings.Contains(email, "@") {
+		return nil, errors.New("invalid email address")
+	}
+	permission, err := normalizeFriendSharePermission(req.Permission)
+	if err != nil {
+		return nil, err
+	}
+	if req.ExpireDays < 0 {
+		return nil, errors.New("expire days cannot be negative")
+	}
+
+	// Whether the address has an account must not show in the reply
+	var recipients []*models.UserBasic
+	if err = l.svcCtx.DB.WithContext(l.ctx).Where("email = ?", email).Limit(1).Find(&recipients).Error; err != nil {
+		return nil, err
+	}
+
+	sender := new(models.UserBasic)
+	if err = l.svcCtx.DB.WithContext(l.ctx).Where("identity = ?", fromUserIdentity).First(sender).Error; err != nil {
+		return nil, err
+	}
+
+	var ur models.UserRepository
+	err = l.svcCtx.DB.WithContext(l.ctx).
+		Where("identity = ? AND user_identity = ?", req.UserRepositoryIdentity, fromUserIdentity).
+		First(&ur).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, errors.New("file not found")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	expireDays := req.ExpireDays
+	if expireDays == 0 {
+		expireDays = define.ShareInviteExpireDays
+	}
+	invite := &models.ShareInvite{
+		Identity:               helper.UUID(),
+		FromUserIdentity:       fromUserIdentity,
+		Email:                  email,
+		RepositoryIdentity:     ur.RepositoryIdentity,
+		UserRepositoryIdentity: ur.Identity,
+		Message:                req.Message,
+		Permission:             permission,
+		Status:                 shareInviteStatusPending,
+		ExpiresAt:              time.Now().AddDate(0, 0, expireDays),
+	}
+	if err = l.svcCtx.DB.WithContext(l.ctx).Create(invite).Error; err != nil {
+		return nil, err
+	}
+
+	// Registered recipients hear about the invite in-app and accept it there.
+	// A failed email is only logged: the sender may revoke and resend, and a
+	// different reply would tell them whether the address has an account.
+	fileName := ur.Name + ur.Ext
+	if len(recipients) > 0 {
+		deliverShareInvite(l.ctx, l.svcCtx, invite, recipients[0], sender.Name, fileName)
+	} else {
+		link := define.FrontendBaseURL + "/register?email=" + url.QueryEscape(email) + "&invite=" + invite.Identity
+		if err = helper.MailSendShareInvite(email, sender.Name, fileName, req.Message, link); err != nil {
+			log.Printf("[FriendShareInviteCreate] Send failed for invite %s: %v", invite.Identity, err)
+		}
+	}
+
+	resp = &types.FriendShareInviteCreateReply{
+		Identity:  invite.Identity,
+		ExpiresAt: invite.ExpiresAt.Format(define.Datetime),
+	}
+	return
+}
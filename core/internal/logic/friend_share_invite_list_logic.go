@@ -0,0 +1,78 @@
+package logic
+
+import (
+	"context"
+	"time"
+
+	"cloud-dist/core/define"
+	"cloud-dist/core/internal/types"
+	"cloud-dist/core/svc"
+)
+
+type FriendShareInviteListLogic struct {
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewFriendShareInviteListLogic(ctx context.Context, svcCtx *svc.ServiceContext) *FriendShareInviteListLogic {
+	return &FriendShareInviteListLogic{
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *FriendShareInviteListLogic) FriendShareInviteList(req *types.FriendShareInviteListRequest, userIdentity string) (resp *types.FriendShareInviteListReply, err error) {
+	resp = new(types.FriendShareInviteListReply)
+	resp.List = make([]*types.FriendShareInviteItem, 0)
+
+	// Revoked invites are soft-deleted, so they are only listed when asked for
+	query := l.svcCtx.DB.WithContext(l.ctx).Table("share_invite").
+		Select("share_invite.identity, share_invite.email, share_invite.user_repository_identity, "+
+			"share_invite.message, share_invite.status, share_invite.to_user_identity, "+
+			"share_invite.expires_at, share_invite.created_at, "+
+			"user_repository.name as file_name, user_repository.ext as file_ext").
+		Joins("LEFT JOIN user_repository ON share_invite.user_repository_identity = user_repository.identity").
+		Where("share_invite.from_user_identity = ?", userIdentity)
+	if req.Status != "" {
+		query = query.Where("share_invite.status = ?", req.Status)
+	}
+	if req.Status != shareInviteStatusRevoked {
+		query = query.Where("share_invite.deleted_at IS NULL")
+	}
+
+	var results []struct {
+		Identity               string
+		Email                  string
+		UserRepositoryIdentity string
+		Message                string
+		Status                 string
+		ToUserIdentity         string
+		ExpiresAt              time.Time
+		CreatedAt              time.Time
+		FileName               string
+		FileExt                string
+	}
+	if err = query.Order("share_invite.created_at DESC").Scan(&results).Error; err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	for _, r := range results {
+		status := r.Status
+		if status == shareInviteStatusPending && now.After(r.ExpiresAt) {
+			status = "expired"
+		}
+		resp.List = append(resp.List, &types.FriendShareInviteItem{
+			Identity:               r.Identity,
+			Email:                  r.Email,
+			UserRepositoryIdentity: r.UserRepositoryIdentity,
+			FileName:               r.FileName + r.FileExt,
+			Message:                r.Message,
+			Status:                 status,
+			ToUserIdentity:         r.ToUserIdentity,
+			ExpiresAt:              r.ExpiresAt.Format(define.Datetime),
+			CreatedAt:              r.CreatedAt.Format(define.Datetime),
+		})
+	}
+	return
+}
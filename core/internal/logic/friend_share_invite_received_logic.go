@@ -0,0 +1,78 @@
+package logic
+
+import (
+	"context"
+	"time"
+
+	"cloud-dist/core/define"
+	"cloud-dist/core/internal/types"
+	"cloud-dist/core/models"
+	"cloud-dist/core/svc"
+)
+
+type FriendShareInviteReceivedLogic struct {
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewFriendShareInviteReceivedLogic(ctx context.Context, svcCtx *svc.ServiceContext) *FriendShareInviteReceivedLogic {
+	return &FriendShareInviteReceivedLogic{
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// FriendShareInviteReceived lists the pending invites sent to the user's
+// email, leaving out senders the user has blocked
+func (l *FriendShareInviteReceivedLogic) FriendShareInviteReceived(req *types.FriendShareInviteReceivedRequest, userIdentity string) (resp *types.FriendShareInviteReceivedReply, err error) {
+	user := new(models.UserBasic)
+	if err = l.svcCtx.DB.WithContext(l.ctx).Where("identity = ?", userIdentity).First(user).Error; err != nil {
+		return nil, err
+	}
+
+	var results []struct {
+		Identity         string
+		FromUserIdentity string
+		FromUserName     string
+		Message          string
+		Permission       string
+		ExpiresAt        time.Time
+		CreatedAt        time.Time
+		FileName         string
+		FileExt          string
+	}
+	err = l.svcCtx.DB.WithContext(l.ctx).Table("share_invite").
+		Select("share_invite.identity, share_invite.from_user_identity, user_basic.name as from_user_name, "+
+			"share_invite.message, share_invite.permission, share_invite.expires_at, share_invite.created_at, "+
+			"user_repository.name as file_name, user_repository.ext as file_ext").
+		Joins("LEFT JOIN user_basic ON share_invite.from_user_identity = user_basic.identity").
+		Joins("LEFT JOIN user_repository ON share_invite.user_repository_identity = user_repository.identity").
+		Where("share_invite.email = ? AND share_invite.status = ? AND share_invite.expires_at > ?",
+			user.Email, shareInviteStatusPending, time.Now()).
+		Where("share_invite.from_user_identity <> ?", userIdentity).
+		Where("share_invite.deleted_at IS NULL").
+		Where("NOT EXISTS (SELECT 1 FROM friend WHERE friend.user_identity = ? "+
+			"AND friend.friend_identity = share_invite.from_user_identity AND friend.status = ? AND friend.deleted_at IS NULL)",
+			userIdentity, friendStatusBlocked).
+		Order("share_invite.created_at DESC").
+		Scan(&results).Error
+	if err != nil {
+		return nil, err
+	}
+
+	resp = new(types.FriendShareInviteReceivedReply)
+	resp.List = make([]*types.FriendShareInviteReceivedItem, 0, len(results))
+	for _, r := range results {
+		resp.List = append(resp.List, &types.FriendShareInviteReceivedItem{
+			Identity:         r.Identity,
+			FromUserIdentity: r.FromUserIdentity,
+			FromUserName:     r.FromUserName,
+			FileName:         r.FileName + r.FileExt,
+			Message:          r.Message,
+			Permission:       r.Permission,
+			ExpiresAt:        r.ExpiresAt.Format(define.Datetime),
+			CreatedAt:        r.CreatedAt.Format(define.Datetime),
+		})
+	}
+	return
+}
@@ -0,0 +1,55 @@
+package logic
+
+import (
+	"context"
+	"errors"
+
+	"cloud-dist/core/internal/types"
+	"cloud-dist/core/models"
+	"cloud-dist/core/svc"
+
+	"gorm.io/gorm"
+)
+
+type FriendShareInviteRevokeLogic struct {
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewFriendShareInviteRevokeLogic(ctx context.Context, svcCtx *svc.ServiceContext) *FriendShareInviteRevokeLogic {
+	return &FriendShareInviteRevokeLogic{
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// FriendShareInviteRevoke cancels an invite. If it was already accepted, the
+// share it turned into is removed as well.
+func (l *FriendShareInviteRevokeLogic) FriendShareInviteRevoke(req *types.FriendShareInviteRevokeRequest, userIdentity string) (resp *types.FriendShareInviteRevokeReply, err error) {
+	invite := new(models.ShareInvite)
+	err = l.svcCtx.DB.WithContext(l.ctx).
+		Where("identity = ? AND from_user_identity = ?", req.Identity, userIdentity).
+		First(invite).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, errors.New("invite not found")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	err = l.svcCtx.DB.WithContext(l.ctx).Transaction(func(tx *gorm.DB) error {
+		if invite.FriendShareIdentity != "" {
+			if err := tx.Where("identity = ?", invite.FriendShareIdentity).Delete(&models.FriendShare{}).Error; err != nil {
+				return err
+			}
+		}
+		if err := tx.Model(invite).Update("status", shareInviteStatusRevoked).Error; err != nil {
+			return err
+		}
+		return tx.Delete(invite).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &types.FriendShareInviteRevokeReply{}, nil
+}
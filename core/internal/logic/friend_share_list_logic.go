@@ -2,6 +2,7 @@ package logic
 
 import (
 	"context"
+	"errors"
 	"time"
 
 	"cloud-dist/core/define"
@@ -28,13 +29,15 @@ func (l *FriendShareListLogic) FriendShareList(req *types.FriendShareListRequest
 	query := l.svcCtx.DB.WithContext(l.ctx).Table("friend_share").
 		Select("friend_share.identity, friend_share.from_user_identity, friend_share.to_user_identity, " +
 			"friend_share.repository_identity, friend_share.user_repository_identity, " +
-			"friend_share.message, friend_share.is_read, friend_share.created_at, " +
+			"friend_share.message, friend_share.is_read, friend_share.permission, friend_share.created_at, " +
+			"friend_share.recipient_state, friend_share.expires_at, friend_share.opened_at, friend_share.saved_at, " +
 			"from_user.name as from_user_name, to_user.name as to_user_name, " +
-			"repository_pool.name as file_name, repository_pool.ext as file_ext, repository_pool.size as file_size, " +
-			"repository_pool.path as s3_key").
+			"COALESCE(repository_pool.name, user_repository.name) as file_name, " +
+			"COALESCE(repository_pool.ext, user_repository.ext) as file_ext, repository_pool.size as file_size").
 		Joins("LEFT JOIN user_basic as from_user ON friend_share.from_user_identity = from_user.identity").
 		Joins("LEFT JOIN user_basic as to_user ON friend_share.to_user_identity = to_user.identity").
-		Joins("LEFT JOIN repository_pool ON friend_share.repository_identity = repository_pool.identity")
+		Joins("LEFT JOIN repository_pool ON friend_share.repository_identity = repository_pool.identity").
+		Joins("LEFT JOIN user_repository ON friend_share.user_repository_identity = user_repository.identity")
 
 	// Filter by type
 	if req.Type == "sent" {
@@ -46,7 +49,55 @@ func (l *FriendShareListLogic) FriendShareList(req *types.FriendShareListRequest
 		query = query.Where("friend_share.from_user_identity = ? OR friend_share.to_user_identity = ?", userIdentity, userIdentity)
 	}
 
-	query = query.Where("friend_share.deleted_at IS NULL").
+	// Archived and dismissed shares only leave the recipient's inbox; the
+	// sender keeps seeing everything they sent
+	state := req.State
+	if state == "" {
+		state = friendShareStateInbox
+	}
+	switch state {
+	case "all":
+	case friendShareStateInbox, friendShareStateArchived, friendShareStateDismissed:
+		query = query.Where("(friend_share.from_user_identity = ? OR friend_share.recipient_state = ?)", userIdentity, state)
+	default:
+		return nil, errors.New("state must be inbox, archived, dismissed or all")
+	}
+
+	if req.FromUserIdentity != "" {
+		query = query.Where("friend_share.from_user_identity = ?", req.FromUserIdentity)
+	}
+
+	switch req.Read {
+	case "":
+	case "read":
+		query = query.Where("friend_share.is_read = ?", true)
+	case "unread":
+		query = query.Where("friend_share.is_read = ?", false)
+	default:
+		return nil, errors.New("read must be read or unread")
+	}
+
+	if req.StartDate != "" {
+		start, err := time.ParseInLocation("2006-01-02", req.StartDate, time.Local)
+		if err != nil {
+			return nil, errors.New("start_date must be YYYY-MM-DD")
+		}
+		query = query.Where("friend_share.created_at >= ?", start)
+	}
+	if req.EndDate != "" {
+		end, err := time.ParseInLocation("2006-01-02", req.EndDate, time.Local)
+		if err != nil {
+			return nil, errors.New("end_date must be YYYY-MM-DD")
+		}
+		query = query.Where("friend_share.created_at < ?", end.AddDate(0, 0, 1))
+	}
+
+	// Hide shares between users where either side has blocked the other
+	query = query.Where("NOT EXISTS (SELECT 1 FROM friend WHERE friend.status = ? AND friend.deleted_at IS NULL AND "+
+		"((friend.user_identity = friend_share.from_user_identity AND friend.friend_identity = friend_share.to_user_identity) OR "+
+		"(friend.user_identity = friend_share.to_user_identity AND friend.friend_identity = friend_share.from_user_identity)))",
+		friendStatusBlocked).
+		Where("friend_share.deleted_at IS NULL").
 		Order("friend_share.created_at DESC")
 
 	var results []struct {
@@ -62,8 +113,12 @@ func (l *FriendShareListLogic) FriendShareList(req *types.FriendShareListRequest
 		FileSize               int64
 		Message                string
 		IsRead                 bool
+		Permission             string
+		RecipientState         string
+		ExpiresAt              *time.Time
+		OpenedAt               *time.Time
+		SavedAt                *time.Time
 		CreatedAt              string
-		S3Key                  string
 	}
 
 	err = query.Scan(&results).Error
@@ -83,14 +138,31 @@ func (l *FriendShareListLogic) FriendShareList(req *types.FriendShareListRequest
 			FileName:               r.FileName,
 			FileExt:                r.FileExt,
 			FileSize:               r.FileSize,
+			IsFolder:               r.RepositoryIdentity == "",
+			Permission:             r.Permission,
 			Message:                r.Message,
 			IsRead:                 r.IsRead,
+			State:                  r.RecipientState,
 			CreatedAt:              r.CreatedAt,
 		}
+		if item.State == "" {
+			item.State = friendShareStateInbox
+		}
+		if r.ExpiresAt != nil {
+			item.ExpiresAt = r.ExpiresAt.Format(define.Datetime)
+			item.Expired = time.Now().After(*r.ExpiresAt)
+		}
+		if r.OpenedAt != nil {
+			item.OpenedAt = r.OpenedAt.Format(define.Datetime)
+		}
+		if r.SavedAt != nil {
+			item.SavedAt = r.SavedAt.Format(define.Datetime)
+		}
 
 		// Use friend share download endpoint (no expiration, verifies friendship)
 		// This endpoint verifies that both users are friends and allows permanent access
-		if r.Identity != "" {
+		// Folder contents are browsed through /friend/share/folder/list instead
+		if r.Identity != "" && !item.IsFolder {
 			item.Path = "/friend/share/download?identity=" + r.Identity
 		} else if r.RepositoryIdentity != "" {
 			// Fallback to repository identity if share identity is not available
@@ -0,0 +1,52 @@
+package logic
+
+import (
+	"context"
+	"errors"
+
+	"cloud-dist/core/internal/types"
+	"cloud-dist/core/models"
+	"cloud-dist/core/svc"
+)
+
+type FriendSharePermissionUpdateLogic struct {
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewFriendSharePermissionUpdateLogic(ctx context.Context, svcCtx *svc.ServiceContext) *FriendSharePermissionUpdateLogic {
+	return &FriendSharePermissionUpdateLogic{
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *FriendSharePermissionUpdateLogic) FriendSharePermissionUpdate(req *types.FriendSharePermissionUpdateRequest, userIdentity string) (resp *types.FriendSharePermissionUpdateReply, err error) {
+	if req.Permission == "" {
+		return nil, errors.New("permission is required")
+	}
+	permission, err := normalizeFriendSharePermission(req.Permission)
+	if err != nil {
+		return nil, err
+	}
+
+	// Only the sender can change what the recipient may do
+	result := l.svcCtx.DB.WithContext(l.ctx).Model(&models.FriendShare{}).
+		Where("identity = ? AND from_user_identity = ?", req.Identity, userIdentity).
+		Update("permission", permission)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		var cnt int64
+		if err = l.svcCtx.DB.WithContext(l.ctx).Model(&models.FriendShare{}).
+			Where("identity = ? AND from_user_identity = ?", req.Identity, userIdentity).
+			Count(&cnt).Error; err != nil {
+			return nil, err
+		}
+		if cnt == 0 {
+			return nil, errors.New("share record not found")
+		}
+	}
+	return &types.FriendSharePermissionUpdateReply{}, nil
+}
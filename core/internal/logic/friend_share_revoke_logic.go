@@ -0,0 +1,56 @@
+package logic
+
+import (
+	"context"
+	"errors"
+
+	"cloud-dist/core/internal/types"
+	"cloud-dist/core/models"
+	"cloud-dist/core/svc"
+
+	"gorm.io/gorm"
+)
+
+type FriendShareRevokeLogic struct {
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewFriendShareRevokeLogic(ctx context.Context, svcCtx *svc.ServiceContext) *FriendShareRevokeLogic {
+	return &FriendShareRevokeLogic{
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// FriendShareRevoke removes a share the caller sent. Access checks reload the
+// share on every request, so the recipient loses access immediately.
+func (l *FriendShareRevokeLogic) FriendShareRevoke(req *types.FriendShareRevokeRequest, userIdentity string) (resp *types.FriendShareRevokeReply, err error) {
+	fs := new(models.FriendShare)
+	err = l.svcCtx.DB.WithContext(l.ctx).
+		Where("identity = ? AND from_user_identity = ?", req.Identity, userIdentity).
+		First(fs).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, errors.New("share record not found")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	err = l.svcCtx.DB.WithContext(l.ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(fs).Error; err != nil {
+			return err
+		}
+		// Keep the originating email invite in step
+		if fs.InviteIdentity != "" {
+			return tx.Model(&models.ShareInvite{}).
+				Where("identity = ?", fs.InviteIdentity).
+				Update("status", shareInviteStatusRevoked).Error
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &types.FriendShareRevokeReply{}, nil
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 
+	"cloud-dist/core/audit"
 	"cloud-dist/core/helper"
 	"cloud-dist/core/internal/types"
 	"cloud-dist/core/models"
@@ -24,7 +25,7 @@ func NewFriendShareSaveLogic(ctx context.Context, svcCtx *svc.ServiceContext) *F
 	}
 }
 
-func (l *FriendShareSaveLogic) FriendShareSave(req *types.FriendShareSaveRequest, userIdentity string) (resp *types.FriendShareSaveReply, err error) {
+func (l *FriendShareSaveLogic) FriendShareSave(req *types.FriendShareSaveRequest, userIdentity, ownerIdentity, billingIdentity string) (resp *types.FriendShareSaveReply, err error) {
 	// First, verify the friend share record exists
 	fs := new(models.FriendShare)
 	err = l.svcCtx.DB.WithContext(l.ctx).
@@ -42,25 +43,31 @@ func (l *FriendShareSaveLogic) FriendShareSave(req *types.FriendShareSaveRequest
 		return nil, errors.New("access denied: only the receiver can save this file")
 	}
 
-	// Verify that both users are friends
-	var friendCount int64
-	err = l.svcCtx.DB.WithContext(l.ctx).Model(&models.Friend{}).
-		Where("((user_identity = ? AND friend_identity = ?) OR (user_identity = ? AND friend_identity = ?)) AND status = ?",
-			fs.FromUserIdentity, fs.ToUserIdentity,
-			fs.ToUserIdentity, fs.FromUserIdentity,
-			"active").
-		Count(&friendCount).Error
+	// Verify that both users are friends (invite-based shares are exempt)
+	accessible, err := friendShareAccessible(l.ctx, l.svcCtx, fs)
 	if err != nil {
 		return nil, err
 	}
-
-	if friendCount == 0 {
+	if !accessible {
 		return nil, errors.New("access denied: friendship relationship not found")
 	}
 
+	if err = checkFriendShareExpiry(fs); err != nil {
+		return nil, err
+	}
+
+	// Folder shares save one file from inside the shared folder
+	item, err := resolveFriendShareItem(l.ctx, l.svcCtx, fs, req.ItemIdentity)
+	if err != nil {
+		return nil, err
+	}
+	if item.RepositoryIdentity == "" {
+		return nil, errors.New("folders cannot be saved, choose a file inside it")
+	}
+
 	// Get file info from repository_pool
 	rp := new(models.RepositoryPool)
-	err = l.svcCtx.DB.WithContext(l.ctx).Where("identity = ?", fs.RepositoryIdentity).First(rp).Error
+	err = l.svcCtx.DB.WithContext(l.ctx).Where("identity = ?", item.RepositoryIdentity).First(rp).Error
 	if errors.Is(err, gorm.ErrRecordNotFound) {
 		return nil, errors.New("resource does not exist")
 	}
@@ -71,8 +78,8 @@ func (l *FriendShareSaveLogic) FriendShareSave(req *types.FriendShareSaveRequest
 	// Check if file already exists in user's repository (global deduplication - user level, not folder level)
 	var existingUR models.UserRepository
 	err = l.svcCtx.DB.WithContext(l.ctx).
-		Where("user_identity = ?", userIdentity).
-		Where("repository_identity = ?", fs.RepositoryIdentity).
+		Where("user_identity = ?", ownerIdentity).
+		Where("repository_identity = ?", item.RepositoryIdentity).
 		Where("deleted_at IS NULL").
 		First(&existingUR).Error
 	if err == nil {
@@ -87,7 +94,7 @@ func (l *FriendShareSaveLogic) FriendShareSave(req *types.FriendShareSaveRequest
 	ub := new(models.UserBasic)
 	err = l.svcCtx.DB.WithContext(l.ctx).
 		Select("now_volume", "total_volume").
-		Where("identity = ?", userIdentity).First(ub).Error
+		Where("identity = ?", billingIdentity).First(ub).Error
 	if err != nil {
 		return nil, err
 	}
@@ -99,23 +106,28 @@ func (l *FriendShareSaveLogic) FriendShareSave(req *types.FriendShareSaveRequest
 	// Create user repository entry
 	ur := &models.UserRepository{
 		Identity:           helper.UUID(),
-		UserIdentity:       userIdentity,
+		UserIdentity:       ownerIdentity,
 		ParentId:           req.ParentId,
-		RepositoryIdentity: fs.RepositoryIdentity,
+		RepositoryIdentity: item.RepositoryIdentity,
 		Ext:                rp.Ext,
 		Name:               rp.Name,
 	}
 	if err = l.svcCtx.DB.WithContext(l.ctx).Create(ur).Error; err != nil {
 		return nil, err
 	}
+	audit.Record(l.ctx, l.svcCtx.DB, audit.Entry{Actor: userIdentity, Subject: ownerIdentity, Action: audit.ActionFileCreate,
+		TargetType: audit.TargetFile, Target: ur.Identity, Detail: map[string]interface{}{"name": ur.Name, "source": "friend_share"}})
 
 	// Update user storage capacity
 	err = l.svcCtx.DB.WithContext(l.ctx).Model(&models.UserBasic{}).
-		Where("identity = ?", userIdentity).
+		Where("identity = ?", billingIdentity).
 		UpdateColumn("now_volume", gorm.Expr("now_volume + ?", rp.Size)).Error
 	if err != nil {
 		return nil, err
 	}
+	notifyQuotaThreshold(l.ctx, l.svcCtx, billingIdentity, ub.NowVolume, ub.NowVolume+rp.Size, ub.TotalVolume)
+
+	markFriendShare(l.ctx, l.svcCtx, fs, "saved_at")
 
 	resp = &types.FriendShareSaveReply{Identity: ur.Identity}
 	return
@@ -0,0 +1,50 @@
+package logic
+
+import (
+	"context"
+	"errors"
+
+	"cloud-dist/core/internal/types"
+	"cloud-dist/core/models"
+	"cloud-dist/core/svc"
+)
+
+type FriendShareStateUpdateLogic struct {
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewFriendShareStateUpdateLogic(ctx context.Context, svcCtx *svc.ServiceContext) *FriendShareStateUpdateLogic {
+	return &FriendShareStateUpdateLogic{
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *FriendShareStateUpdateLogic) FriendShareStateUpdate(req *types.FriendShareStateUpdateRequest, userIdentity string) (resp *types.FriendShareStateUpdateReply, err error) {
+	switch req.State {
+	case friendShareStateInbox, friendShareStateArchived, friendShareStateDismissed:
+	default:
+		return nil, errors.New("state must be inbox, archived or dismissed")
+	}
+
+	// The inbox state belongs to the recipient only
+	var cnt int64
+	err = l.svcCtx.DB.WithContext(l.ctx).Model(&models.FriendShare{}).
+		Where("identity = ? AND to_user_identity = ?", req.Identity, userIdentity).
+		Count(&cnt).Error
+	if err != nil {
+		return nil, err
+	}
+	if cnt == 0 {
+		return nil, errors.New("share record not found")
+	}
+
+	err = l.svcCtx.DB.WithContext(l.ctx).Model(&models.FriendShare{}).
+		Where("identity = ? AND to_user_identity = ?", req.Identity, userIdentity).
+		Update("recipient_state", req.State).Error
+	if err != nil {
+		return nil, err
+	}
+	return &types.FriendShareStateUpdateReply{}, nil
+}
@@ -0,0 +1,48 @@
+package logic
+
+import (
+	"context"
+	"errors"
+
+	"cloud-dist/core/audit"
+	"cloud-dist/core/internal/types"
+	"cloud-dist/core/models"
+	"cloud-dist/core/svc"
+)
+
+type FriendUnblockLogic struct {
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewFriendUnblockLogic(ctx context.Context, svcCtx *svc.ServiceContext) *FriendUnblockLogic {
+	return &FriendUnblockLogic{
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// FriendUnblock lifts a block. The friendship is not restored; either user can
+// send a new friend request.
+func (l *FriendUnblockLogic) FriendUnblock(req *types.FriendUnblockRequest, userIdentity string) (resp *types.FriendUnblockReply, err error) {
+	result := l.svcCtx.DB.WithContext(l.ctx).
+		Where("user_identity = ? AND friend_identity = ? AND status = ?", userIdentity, req.UserIdentity, friendStatusBlocked).
+		Delete(&models.Friend{})
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, errors.New("user is not blocked")
+	}
+
+	// Requests swallowed while blocked are dropped so the other user can ask again
+	err = l.svcCtx.DB.WithContext(l.ctx).Model(&models.FriendRequest{}).
+		Where("from_user_identity = ? AND to_user_identity = ? AND status = ?", req.UserIdentity, userIdentity, friendRequestStatusBlocked).
+		Update("status", friendRequestStatusCancelled).Error
+	if err != nil {
+		return nil, err
+	}
+	audit.Record(l.ctx, l.svcCtx.DB, audit.Entry{Actor: userIdentity, Action: audit.ActionFriendUnblock,
+		TargetType: audit.TargetUser, Target: req.UserIdentity})
+	return &types.FriendUnblockReply{}, nil
+}
@@ -0,0 +1,187 @@
+package logic
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/url"
+	"strings"
+	"time"
+
+	"cloud-dist/core/audit"
+	"cloud-dist/core/define"
+	"cloud-dist/core/helper"
+	"cloud-dist/core/models"
+	"cloud-dist/core/svc"
+)
+
+// Failed logins are counted per account and per IP within a sliding window.
+// After a few failures each further attempt has to wait a growing delay, and
+// past the limit the account or IP is locked for a while.
+const (
+	loginFailureWindow    = 15 * time.Minute
+	loginDelayAfter       = 3
+	loginMaxDelay         = 30 * time.Second
+	loginAccountLockAfter = 10
+	loginIPLockAfter      = 50
+	loginLockDuration     = 15 * time.Minute
+)
+
+// Lockout scopes
+const (
+	loginScopeAccount = "account"
+	loginScopeIP      = "ip"
+)
+
+// loginTarget is what a login attempt is counted against
+type loginTarget struct {
+	accountKey string
+	account    string
+	user       *models.UserBasic // nil when no account matched
+	meta       helper.ClientMeta
+	skipIP     bool // The IP already counted this attempt against another target
+}
+
+// newLoginTarget keys the account counter by user identity when the account
+// exists, and by the typed name otherwise, so unknown accounts lock the same way
+func newLoginTarget(ctx context.Context, account string, user *models.UserBasic) *loginTarget {
+	t := &loginTarget{account: account, user: user, meta: helper.ClientMetaFromContext(ctx)}
+	if user != nil {
+		t.accountKey = "user:" + user.Identity
+	} else {
+		t.accountKey = "name:" + strings.ToLower(account)
+	}
+	return t
+}
+
+func (t *loginTarget) scopes() map[string]string {
+	scopes := map[string]string{loginScopeAccount: t.accountKey}
+	if t.meta.IP != "" && !t.skipIP {
+		scopes[loginScopeIP] = t.meta.IP
+	}
+	return scopes
+}
+
+func loginGuardKey(kind, scope, key string) string {
+	return "login_" + kind + ":" + scope + ":" + key
+}
+
+// checkLoginAllowed refuses the attempt while the account or IP is locked or
+// still has to wait out its delay
+func checkLoginAllowed(ctx context.Context, svcCtx *svc.ServiceContext, t *loginTarget) error {
+	for scope, key := range t.scopes() {
+		if ttl, err := svcCtx.RDB.TTL(ctx, loginGuardKey("lock", scope, key)).Result(); err == nil && ttl > 0 {
+			return fmt.Errorf("too many failed login attempts, try again in %d minutes", int(ttl.Minutes())+1)
+		}
+		if ttl, err := svcCtx.RDB.TTL(ctx, loginGuardKey("wait", scope, key)).Result(); err == nil && ttl > 0 {
+			return fmt.Errorf("too many failed login attempts, try again in %d seconds", int(ttl.Seconds())+1)
+		}
+	}
+	return nil
+}
+
+// recordLoginFailure counts a failed attempt and adds it to the audit log. It
+// returns an error when this failure triggered a lockout, so the caller can
+// report it right away.
+func recordLoginFailure(ctx context.Context, svcCtx *svc.ServiceContext, t *loginTarget, reason string) error {
+	entry := audit.Entry{Action: audit.ActionLoginFailed, TargetType: audit.TargetUser, Reason: reason,
+		Detail: map[string]interface{}{"account": t.account}}
+	if t.user != nil {
+		entry.Subject, entry.Target = t.user.Identity, t.user.Identity
+	}
+	audit.Record(ctx, svcCtx.DB, entry)
+
+	var lockErr error
+	for scope, key := range t.scopes() {
+		failKey := loginGuardKey("fail", scope, key)
+		n, err := svcCtx.RDB.Incr(ctx, failKey).Result()
+		if err != nil {
+			log.Printf("[LoginGuard] Failed to count login failure for %s %s: %v", scope, key, err)
+			continue
+		}
+		if n == 1 {
+			svcCtx.RDB.Expire(ctx, failKey, loginFailureWindow)
+		}
+
+		limit := int64(loginAccountLockAfter)
+		if scope == loginScopeIP {
+			limit = loginIPLockAfter
+		}
+		if n >= limit {
+			svcCtx.RDB.Set(ctx, loginGuardKey("lock", scope, key), n, loginLockDuration)
+			svcCtx.RDB.Del(ctx, failKey, loginGuardKey("wait", scope, key))
+			recordLoginLockout(ctx, svcCtx, t, scope, n)
+			lockErr = fmt.Errorf("too many failed login attempts, try again in %d minutes", int(loginLockDuration.Minutes()))
+			continue
+		}
+		if n >= loginDelayAfter {
+			delay := time.Second << uint(n-loginDelayAfter)
+			if delay > loginMaxDelay {
+				delay = loginMaxDelay
+			}
+			svcCtx.RDB.Set(ctx, loginGuardKey("wait", scope, key), n, delay)
+		}
+	}
+	return lockErr
+}
+
+// clearLoginFailures resets the account counter after a successful login. The
+// IP counter is kept, since one success says little about the other accounts
+// tried from that address.
+func clearLoginFailures(ctx context.Context, svcCtx *svc.ServiceContext, t *loginTarget) {
+	svcCtx.RDB.Del(ctx,
+		loginGuardKey("fail", loginScopeAccount, t.accountKey),
+		loginGuardKey("wait", loginScopeAccount, t.accountKey))
+}
+
+// recordLoginLockout stores the lockout and emails the account owner a link to
+// lift it. Existing sessions are kept: failed guesses say nothing about them,
+// and ending them would let anyone log the owner out by guessing.
+// Failures are logged only, so that auditing never changes the login result.
+func recordLoginLockout(ctx context.Context, svcCtx *svc.ServiceContext, t *loginTarget, scope string, failures int64) {
+	until := time.Now().Add(loginLockDuration)
+	entry := &models.LoginLockout{
+		Identity:    helper.UUID(),
+		Scope:       scope,
+		Account:     t.account,
+		IP:          t.meta.IP,
+		UserAgent:   t.meta.UserAgent,
+		Failures:    failures,
+		LockedUntil: until,
+	}
+	if scope == loginScopeAccount && t.user != nil {
+		entry.UserIdentity = t.user.Identity
+	}
+
+	link := ""
+	if entry.UserIdentity != "" && t.user.Email != "" {
+		token, err := helper.NewLinkToken()
+		if err != nil {
+			log.Printf("[LoginGuard] Failed to create unlock token for %s: %v", entry.UserIdentity, err)
+		} else {
+			entry.UnlockTokenHash = helper.HashLinkToken(token)
+			link = define.FrontendBaseURL + "/login/unlock?token=" + url.QueryEscape(token)
+		}
+	}
+	if err := svcCtx.DB.WithContext(ctx).Create(entry).Error; err != nil {
+		log.Printf("[LoginGuard] Failed to record %s lockout for %s: %v", scope, t.account, err)
+		link = ""
+	}
+	log.Printf("[LoginGuard] Locked %s after %d failures (account=%s ip=%s)", scope, failures, t.account, t.meta.IP)
+
+	if entry.UserIdentity != "" && t.user.Email != "" {
+		if err := helper.MailSendLoginLockout(t.user.Email, t.user.Name, t.meta.IP, link, until); err != nil {
+			log.Printf("[LoginGuard] Failed to email lockout notice to %s: %v", t.user.Email, err)
+		}
+	}
+}
+
+// unlockLoginAccount lifts the account lock and its failure counters. The IP
+// lock stays, since it guards the other accounts tried from that address.
+func unlockLoginAccount(ctx context.Context, svcCtx *svc.ServiceContext, userIdentity string) error {
+	key := "user:" + userIdentity
+	return svcCtx.RDB.Del(ctx,
+		loginGuardKey("lock", loginScopeAccount, key),
+		loginGuardKey("fail", loginScopeAccount, key),
+		loginGuardKey("wait", loginScopeAccount, key)).Err()
+}
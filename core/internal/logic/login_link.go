@@ -0,0 +1,31 @@
+package logic
+
+import (
+	"errors"
+	"strings"
+)
+
+// Passwordless login keeps one pending link per email. The record holds the
+// code and the hash of the link token; a second key maps the token hash back
+// to the email so the link works without typing anything.
+const loginLinkMaxAttempts = 5
+
+var errLoginLinkInvalid = errors.New("sign-in link or code is invalid or has expired")
+
+type loginLinkRecord struct {
+	UserIdentity string `json:"user_identity"`
+	Code         string `json:"code"`
+	TokenHash    string `json:"token_hash"`
+}
+
+func loginLinkKey(email string) string {
+	return "login_link:" + strings.ToLower(email)
+}
+
+func loginLinkTokenKey(tokenHash string) string {
+	return "login_link_token:" + tokenHash
+}
+
+func loginLinkAttemptsKey(email string) string {
+	return "login_link_attempts:" + strings.ToLower(email)
+}
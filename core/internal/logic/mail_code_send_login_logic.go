@@ -0,0 +1,91 @@
+package logic
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"strings"
+	"time"
+
+	"cloud-dist/core/define"
+	"cloud-dist/core/helper"
+	"cloud-dist/core/internal/types"
+	"cloud-dist/core/models"
+	"cloud-dist/core/svc"
+)
+
+type MailCodeSendLoginLogic struct {
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewMailCodeSendLoginLogic(ctx context.Context, svcCtx *svc.ServiceContext) *MailCodeSendLoginLogic {
+	return &MailCodeSendLoginLogic{
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// MailCodeSendLogin emails a one-time sign-in link and code. The reply is the
+// same whether or not the email has an account, so it cannot be used to probe
+// for users.
+func (l *MailCodeSendLoginLogic) MailCodeSendLogin(req *types.MailCodeSendLoginRequest) (resp *types.MailCodeSendLoginReply, err error) {
+	resp = &types.MailCodeSendLoginReply{}
+	email := strings.TrimSpace(req.Email)
+	if email == "" {
+		return nil, errors.New("email is required")
+	}
+
+	if err = throttleCodeSend(l.ctx, l.svcCtx, codePurposeLogin, email); err != nil {
+		return nil, err
+	}
+	redisKey := loginLinkKey(email)
+
+	// An email shared by several accounts cannot say which one to sign in
+	var users []*models.UserBasic
+	if err = l.svcCtx.DB.WithContext(l.ctx).Where("email = ?", email).Limit(2).Find(&users).Error; err != nil {
+		return nil, err
+	}
+	if len(users) != 1 {
+		log.Printf("[MailCodeSendLogin] No single account for %s (%d matches), not sending", email, len(users))
+		return resp, nil
+	}
+
+	code, token, err := helper.NewLoginLink()
+	if err != nil {
+		return nil, err
+	}
+	record, _ := json.Marshal(&loginLinkRecord{
+		UserIdentity: users[0].Identity,
+		Code:         code,
+		TokenHash:    helper.HashLinkToken(token),
+	})
+	ttl := time.Second * time.Duration(define.CodeExpire)
+
+	// Replace any earlier link for this email
+	if old, err := l.svcCtx.RDB.Get(l.ctx, redisKey).Result(); err == nil {
+		var prev loginLinkRecord
+		if json.Unmarshal([]byte(old), &prev) == nil {
+			l.svcCtx.RDB.Del(l.ctx, loginLinkTokenKey(prev.TokenHash))
+		}
+	}
+	pipe := l.svcCtx.RDB.TxPipeline()
+	pipe.Set(l.ctx, redisKey, record, ttl)
+	pipe.Set(l.ctx, loginLinkTokenKey(helper.HashLinkToken(token)), email, ttl)
+	pipe.Del(l.ctx, loginLinkAttemptsKey(email))
+	if _, err = pipe.Exec(l.ctx); err != nil {
+		return nil, err
+	}
+
+	link := define.FrontendBaseURL + "/login/email?token=" + url.QueryEscape(token)
+	if err = helper.MailSendLoginLink(email, code, link); err != nil {
+		l.svcCtx.RDB.Del(l.ctx, redisKey, loginLinkTokenKey(helper.HashLinkToken(token)), codeSentKey(codePurposeLogin, email))
+		log.Printf("[MailCodeSendLogin] Send failed: %v", err)
+		return nil, fmt.Errorf("failed to send sign-in link: %v", err)
+	}
+	log.Printf("[MailCodeSendLogin] Sign-in link sent successfully to: %s", email)
+	return resp, nil
+}
@@ -3,17 +3,12 @@ package logic
 import (
 	"context"
 	"errors"
-	"fmt"
 	"log"
-	"time"
+	"strings"
 
-	"cloud-dist/core/define"
-	"cloud-dist/core/helper"
 	"cloud-dist/core/internal/types"
 	"cloud-dist/core/models"
 	"cloud-dist/core/svc"
-
-	"gorm.io/gorm"
 )
 
 type MailCodeSendPasswordResetLogic struct {
@@ -29,40 +24,29 @@ func NewMailCodeSendPasswordResetLogic(ctx context.Context, svcCtx *svc.ServiceC
 }
 
 func (l *MailCodeSendPasswordResetLogic) MailCodeSendPasswordReset(req *types.MailCodeSendPasswordResetRequest) (resp *types.MailCodeSendPasswordResetReply, err error) {
-	// Check if email is registered
-	var user models.UserBasic
-	err = l.svcCtx.DB.WithContext(l.ctx).
-		Where("email = ?", req.Email).
-		First(&user).Error
-	if errors.Is(err, gorm.ErrRecordNotFound) {
-		return nil, errors.New("email not found")
+	email := strings.TrimSpace(req.Email)
+	if email == "" {
+		return nil, errors.New("email is required")
 	}
-	if err != nil {
+	if err = throttleCodeSend(l.ctx, l.svcCtx, codePurposePasswordReset, email); err != nil {
 		return nil, err
 	}
 
-	// Check if within minimum send interval (60 seconds) to prevent frequent sending
-	redisKey := "password_reset:" + req.Email
-	codeTTL, _ := l.svcCtx.RDB.TTL(l.ctx, redisKey).Result()
-	if codeTTL.Seconds() > 60 {
-		return nil, errors.New("please wait 60 seconds before resending verification code")
+	// Unknown emails get the same reply, so this cannot be used to find accounts
+	var cnt int64
+	if err = l.svcCtx.DB.WithContext(l.ctx).Model(&models.UserBasic{}).
+		Where("email = ?", email).Count(&cnt).Error; err != nil {
+		return nil, err
+	}
+	if cnt == 0 {
+		log.Printf("[MailCodeSendPasswordReset] No account for %s, not sending", email)
+		return &types.MailCodeSendPasswordResetReply{}, nil
 	}
 
-	// Generate verification code
-	code := helper.RandCode()
-	log.Printf("[MailCodeSendPasswordReset] Preparing to send verification code to email: %s, code: %s", req.Email, code)
-
-	// Store verification code (overwrite old one)
-	l.svcCtx.RDB.Set(l.ctx, redisKey, code, time.Second*time.Duration(define.CodeExpire))
-
-	// Send verification code
-	err = helper.MailSendCode(req.Email, code)
-	if err != nil {
-		// If sending fails, delete stored verification code
-		l.svcCtx.RDB.Del(l.ctx, redisKey)
-		log.Printf("[MailCodeSendPasswordReset] Send failed: %v", err)
-		return nil, fmt.Errorf("failed to send verification code: %v", err)
+	// A failed send gets the same reply too, since an error here would only
+	// ever show for known emails
+	if err = sendVerificationCode(l.ctx, l.svcCtx, codePurposePasswordReset, email); err != nil {
+		log.Printf("[MailCodeSendPasswordReset] Send code failed: %v", err)
 	}
-	log.Printf("[MailCodeSendPasswordReset] Verification code sent successfully to: %s", req.Email)
-	return
+	return &types.MailCodeSendPasswordResetReply{}, nil
 }
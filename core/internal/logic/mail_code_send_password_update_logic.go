@@ -3,15 +3,10 @@ package logic
 import (
 	"context"
 	"errors"
-	"fmt"
-	"log"
-	"time"
 
-	"cloud-dist/core/define"
-	"cloud-dist/core/helper"
-	"cloud-dist/core/svc"
 	"cloud-dist/core/internal/types"
 	"cloud-dist/core/models"
+	"cloud-dist/core/svc"
 )
 
 type MailCodeSendPasswordUpdateLogic struct {
@@ -41,31 +36,11 @@ func (l *MailCodeSendPasswordUpdateLogic) MailCodeSendPasswordUpdate(req *types.
 		return nil, errors.New("email does not match your account")
 	}
 
-	// Check if within minimum send interval (60 seconds) to prevent frequent sending
-	redisKey := "password_update:" + req.Email
-	codeTTL, _ := l.svcCtx.RDB.TTL(l.ctx, redisKey).Result()
-	if codeTTL.Seconds() > 60 {
-		return nil, errors.New("please wait 60 seconds before resending verification code")
+	if err = throttleCodeSend(l.ctx, l.svcCtx, codePurposePasswordUpdate, user.Email); err != nil {
+		return nil, err
 	}
-
-	// Generate verification code
-	code := helper.RandCode()
-	log.Printf("[MailCodeSendPasswordUpdate] Preparing to send verification code to email: %s, code: %s", req.Email, code)
-
-	// Store verification code (overwrite old one)
-	l.svcCtx.RDB.Set(l.ctx, redisKey, code, time.Second*time.Duration(define.CodeExpire))
-
-	// Send verification code
-	err = helper.MailSendCode(req.Email, code)
-	if err != nil {
-		// If sending fails, delete stored verification code
-		l.svcCtx.RDB.Del(l.ctx, redisKey)
-		log.Printf("[MailCodeSendPasswordUpdate] Send failed: %v", err)
-		return nil, fmt.Errorf("failed to send verification code: %v", err)
+	if err = sendVerificationCode(l.ctx, l.svcCtx, codePurposePasswordUpdate, user.Email); err != nil {
+		return nil, err
 	}
-	log.Printf("[MailCodeSendPasswordUpdate] Verification code sent successfully to: %s", req.Email)
-	return
+	return &types.MailCodeSendPasswordUpdateReply{}, nil
 }
-
-
-
@@ -0,0 +1,42 @@
+package logic
+
+import (
+	"context"
+	"errors"
+
+	"cloud-dist/core/internal/types"
+	"cloud-dist/core/svc"
+)
+
+type MailCodeSendReauthLogic struct {
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewMailCodeSendReauthLogic(ctx context.Context, svcCtx *svc.ServiceContext) *MailCodeSendReauthLogic {
+	return &MailCodeSendReauthLogic{
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// MailCodeSendReauth mails a code to the account's current address, which
+// confirms the user in place of a password before an email change or an
+// account deletion
+func (l *MailCodeSendReauthLogic) MailCodeSendReauth(req *types.MailCodeSendReauthRequest, userIdentity string) (resp *types.MailCodeSendReauthReply, err error) {
+	user, err := findUserByIdentity(l.ctx, l.svcCtx, userIdentity)
+	if err != nil {
+		return nil, err
+	}
+	if user.Email == "" {
+		return nil, errors.New("your account has no email address")
+	}
+
+	if err = throttleCodeSend(l.ctx, l.svcCtx, codePurposeReauth, user.Email); err != nil {
+		return nil, err
+	}
+	if err = sendVerificationCode(l.ctx, l.svcCtx, codePurposeReauth, user.Email); err != nil {
+		return nil, err
+	}
+	return &types.MailCodeSendReauthReply{}, nil
+}
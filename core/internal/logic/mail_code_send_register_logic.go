@@ -3,15 +3,12 @@ package logic
 import (
 	"context"
 	"errors"
-	"fmt"
 	"log"
-	"time"
+	"strings"
 
-	"cloud-dist/core/define"
 	"cloud-dist/core/helper"
-	"cloud-dist/core/svc"
 	"cloud-dist/core/internal/types"
-	"cloud-dist/core/models"
+	"cloud-dist/core/svc"
 )
 
 type MailCodeSendRegisterLogic struct {
@@ -27,36 +24,33 @@ func NewMailCodeSendRegisterLogic(ctx context.Context, svcCtx *svc.ServiceContex
 }
 
 func (l *MailCodeSendRegisterLogic) MailCodeSendRegister(req *types.MailCodeSendRequest) (resp *types.MailCodeSendReply, err error) {
-	// Check if email is not registered
-	var cnt int64
-	if err = l.svcCtx.DB.WithContext(l.ctx).Model(&models.UserBasic{}).
-		Where("email = ?", req.Email).Count(&cnt).Error; err != nil {
-		return
+	email := strings.TrimSpace(req.Email)
+	if email == "" {
+		return nil, errors.New("email is required")
 	}
-	if cnt > 0 {
-		err = errors.New("email is already registered")
-		return
+	if err = throttleCodeSend(l.ctx, l.svcCtx, codePurposeRegister, email); err != nil {
+		return nil, err
 	}
-	// Check if within minimum send interval (60 seconds) to prevent frequent sending
-	codeTTL, _ := l.svcCtx.RDB.TTL(l.ctx, req.Email).Result()
-	if codeTTL.Seconds() > 60 {
-		return nil, errors.New("please wait 60 seconds before resending verification code")
-	}
-	// Generate verification code
-	code := helper.RandCode()
-	log.Printf("[MailCodeSend] Preparing to send verification code to email: %s, code: %s", req.Email, code)
-
-	// Store verification code (overwrite old one)
-	l.svcCtx.RDB.Set(l.ctx, req.Email, code, time.Second*time.Duration(define.CodeExpire))
 
-	// Send verification code
-	err = helper.MailSendCode(req.Email, code)
+	// A registered email gets a notice instead of a code, and the caller sees
+	// the same reply either way
+	inUse, err := emailInUse(l.ctx, l.svcCtx, email, "")
 	if err != nil {
-		// If sending fails, delete stored verification code
-		l.svcCtx.RDB.Del(l.ctx, req.Email)
-		log.Printf("[MailCodeSend] Send failed: %v", err)
-		return nil, fmt.Errorf("failed to send verification code: %v", err)
+		return nil, err
+	}
+	if inUse {
+		if err = helper.MailSendNotification(email, "Sign-up attempt",
+			"Someone tried to create an account with this email, but you already have one. "+
+				"If you forgot your password, use password reset. If this was not you, ignore this email."); err != nil {
+			log.Printf("[MailCodeSend] Send notice failed: %v", err)
+		}
+		return &types.MailCodeSendReply{}, nil
+	}
+
+	// A failed send gets the same reply too, since an error here would only
+	// ever show for unused emails
+	if err = sendVerificationCode(l.ctx, l.svcCtx, codePurposeRegister, email); err != nil {
+		log.Printf("[MailCodeSend] Send code failed: %v", err)
 	}
-	log.Printf("[MailCodeSend] Verification code sent successfully to: %s", req.Email)
-	return
+	return &types.MailCodeSendReply{}, nil
 }
@@ -0,0 +1,33 @@
+package logic
+
+import (
+	"context"
+
+	"cloud-dist/core/internal/types"
+	"cloud-dist/core/models"
+	"cloud-dist/core/svc"
+)
+
+type NotificationDeleteLogic struct {
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewNotificationDeleteLogic(ctx context.Context, svcCtx *svc.ServiceContext) *NotificationDeleteLogic {
+	return &NotificationDeleteLogic{
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *NotificationDeleteLogic) NotificationDelete(req *types.NotificationDeleteRequest, userIdentity string) (resp *types.NotificationDeleteReply, err error) {
+	if len(req.Identities) > 0 {
+		err = l.svcCtx.DB.WithContext(l.ctx).
+			Where("user_identity = ? AND identity IN ?", userIdentity, req.Identities).
+			Delete(&models.Notification{}).Error
+		if err != nil {
+			return nil, err
+		}
+	}
+	return &types.NotificationDeleteReply{}, nil
+}
@@ -0,0 +1,69 @@
+package logic
+
+import (
+	"context"
+
+	"cloud-dist/core/define"
+	"cloud-dist/core/internal/types"
+	"cloud-dist/core/models"
+	"cloud-dist/core/svc"
+)
+
+type NotificationListLogic struct {
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewNotificationListLogic(ctx context.Context, svcCtx *svc.ServiceContext) *NotificationListLogic {
+	return &NotificationListLogic{
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *NotificationListLogic) NotificationList(req *types.NotificationListRequest, userIdentity string) (resp *types.NotificationListReply, err error) {
+	size := req.Size
+	if size == 0 {
+		size = define.PageSize
+	}
+	page := req.Page
+	if page == 0 {
+		page = 1
+	}
+	offset := (page - 1) * size
+
+	resp = new(types.NotificationListReply)
+	resp.List = make([]*types.NotificationItem, 0)
+
+	if err = l.svcCtx.DB.WithContext(l.ctx).Model(&models.Notification{}).
+		Where("user_identity = ? AND is_read = ?", userIdentity, false).
+		Count(&resp.UnreadCount).Error; err != nil {
+		return nil, err
+	}
+
+	query := l.svcCtx.DB.WithContext(l.ctx).Model(&models.Notification{}).
+		Where("user_identity = ?", userIdentity)
+	if req.UnreadOnly {
+		query = query.Where("is_read = ?", false)
+	}
+	if err = query.Count(&resp.Count).Error; err != nil {
+		return nil, err
+	}
+
+	var list []*models.Notification
+	if err = query.Order("created_at DESC").Limit(size).Offset(offset).Find(&list).Error; err != nil {
+		return nil, err
+	}
+	for _, n := range list {
+		resp.List = append(resp.List, &types.NotificationItem{
+			Identity:  n.Identity,
+			Type:      n.Type,
+			Title:     n.Title,
+			Body:      n.Body,
+			Data:      n.Data,
+			IsRead:    n.IsRead,
+			CreatedAt: n.CreatedAt.Format(define.Datetime),
+		})
+	}
+	return
+}
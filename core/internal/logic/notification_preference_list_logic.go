@@ -0,0 +1,50 @@
+package logic
+
+import (
+	"context"
+
+	"cloud-dist/core/internal/types"
+	"cloud-dist/core/models"
+	"cloud-dist/core/svc"
+)
+
+type NotificationPreferenceListLogic struct {
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewNotificationPreferenceListLogic(ctx context.Context, svcCtx *svc.ServiceContext) *NotificationPreferenceListLogic {
+	return &NotificationPreferenceListLogic{
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// NotificationPreferenceList returns the channel for every notification type,
+// including the in-app default for types the user never changed
+func (l *NotificationPreferenceListLogic) NotificationPreferenceList(req *types.NotificationPreferenceListRequest, userIdentity string) (resp *types.NotificationPreferenceListReply, err error) {
+	var prefs []*models.NotificationPreference
+	if err = l.svcCtx.DB.WithContext(l.ctx).
+		Where("user_identity = ?", userIdentity).
+		Find(&prefs).Error; err != nil {
+		return nil, err
+	}
+	channels := make(map[string]string, len(prefs))
+	for _, p := range prefs {
+		channels[p.Type] = p.Channel
+	}
+
+	resp = new(types.NotificationPreferenceListReply)
+	resp.List = make([]*types.NotificationPreferenceItem, 0, len(notificationTypes))
+	for _, t := range notificationTypes {
+		channel, ok := channels[t]
+		if !ok {
+			channel = notificationChannelInApp
+		}
+		resp.List = append(resp.List, &types.NotificationPreferenceItem{
+			Type:    t,
+			Channel: channel,
+		})
+	}
+	return
+}
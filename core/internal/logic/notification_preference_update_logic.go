@@ -0,0 +1,60 @@
+package logic
+
+import (
+	"context"
+	"errors"
+
+	"cloud-dist/core/internal/types"
+	"cloud-dist/core/models"
+	"cloud-dist/core/svc"
+
+	"gorm.io/gorm"
+)
+
+type NotificationPreferenceUpdateLogic struct {
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewNotificationPreferenceUpdateLogic(ctx context.Context, svcCtx *svc.ServiceContext) *NotificationPreferenceUpdateLogic {
+	return &NotificationPreferenceUpdateLogic{
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *NotificationPreferenceUpdateLogic) NotificationPreferenceUpdate(req *types.NotificationPreferenceUpdateRequest, userIdentity string) (resp *types.NotificationPreferenceUpdateReply, err error) {
+	known := false
+	for _, t := range notificationTypes {
+		if t == req.Type {
+			known = true
+			break
+		}
+	}
+	if !known {
+		return nil, errors.New("unknown notification type")
+	}
+	switch req.Channel {
+	case notificationChannelInApp, notificationChannelEmail, notificationChannelOff:
+	default:
+		return nil, errors.New("channel must be in_app, email or off")
+	}
+
+	pref := new(models.NotificationPreference)
+	err = l.svcCtx.DB.WithContext(l.ctx).
+		Where("user_identity = ? AND type = ?", userIdentity, req.Type).
+		First(pref).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		err = l.svcCtx.DB.WithContext(l.ctx).Create(&models.NotificationPreference{
+			UserIdentity: userIdentity,
+			Type:         req.Type,
+			Channel:      req.Channel,
+		}).Error
+	} else if err == nil {
+		err = l.svcCtx.DB.WithContext(l.ctx).Model(pref).Update("channel", req.Channel).Error
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &types.NotificationPreferenceUpdateReply{}, nil
+}
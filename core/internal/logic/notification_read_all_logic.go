@@ -0,0 +1,31 @@
+package logic
+
+import (
+	"context"
+
+	"cloud-dist/core/internal/types"
+	"cloud-dist/core/models"
+	"cloud-dist/core/svc"
+)
+
+type NotificationReadAllLogic struct {
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewNotificationReadAllLogic(ctx context.Context, svcCtx *svc.ServiceContext) *NotificationReadAllLogic {
+	return &NotificationReadAllLogic{
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *NotificationReadAllLogic) NotificationReadAll(req *types.NotificationReadAllRequest, userIdentity string) (resp *types.NotificationReadAllReply, err error) {
+	err = l.svcCtx.DB.WithContext(l.ctx).Model(&models.Notification{}).
+		Where("user_identity = ? AND is_read = ?", userIdentity, false).
+		Update("is_read", true).Error
+	if err != nil {
+		return nil, err
+	}
+	return &types.NotificationReadAllReply{}, nil
+}
@@ -0,0 +1,33 @@
+package logic
+
+import (
+	"context"
+
+	"cloud-dist/core/internal/types"
+	"cloud-dist/core/models"
+	"cloud-dist/core/svc"
+)
+
+type NotificationReadLogic struct {
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewNotificationReadLogic(ctx context.Context, svcCtx *svc.ServiceContext) *NotificationReadLogic {
+	return &NotificationReadLogic{
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *NotificationReadLogic) NotificationRead(req *types.NotificationReadRequest, userIdentity string) (resp *types.NotificationReadReply, err error) {
+	if len(req.Identities) > 0 {
+		err = l.svcCtx.DB.WithContext(l.ctx).Model(&models.Notification{}).
+			Where("user_identity = ? AND identity IN ?", userIdentity, req.Identities).
+			Update("is_read", true).Error
+		if err != nil {
+			return nil, err
+		}
+	}
+	return &types.NotificationReadReply{}, nil
+}
@@ -0,0 +1,151 @@
+package logic
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+
+	"cloud-dist/core/events"
+	"cloud-dist/core/helper"
+	"cloud-dist/core/models"
+	"cloud-dist/core/svc"
+
+	"gorm.io/gorm"
+)
+
+// Notification delivery channels
+const (
+	notificationChannelInApp = "in_app"
+	notificationChannelEmail = "email"
+	notificationChannelOff   = "off"
+)
+
+// notificationTypes lists the types a user can set a preference for
+var notificationTypes = []string{
+	events.FriendRequestReceived,
+	events.FriendRequestResponded,
+	events.FriendShareReceived,
+	events.ShareInviteReceived,
+	events.SpaceInviteReceived,
+	events.StorageOrderPaid,
+	events.StorageOrderFailed,
+	events.QuotaThreshold,
+	events.ShareLinkActivity,
+}
+
+// quotaThresholds are the usage percentages that trigger a quota notification
+var quotaThresholds = []int64{80, 95}
+
+// notificationChannel returns the user's channel for a type, in-app by default
+func notificationChannel(ctx context.Context, svcCtx *svc.ServiceContext, userIdentity, notificationType string) (string, error) {
+	pref := new(models.NotificationPreference)
+	err := svcCtx.DB.WithContext(ctx).
+		Where("user_identity = ? AND type = ?", userIdentity, notificationType).
+		First(pref).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return notificationChannelInApp, nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return pref.Channel, nil
+}
+
+// notify pushes an event to connected clients, then records a notification
+// and emails it as the user's preference says. A type that is off sends
+// nothing at all, live event included. Failures are logged only, so that a
+// notification never fails the operation that caused it.
+func notify(ctx context.Context, svcCtx *svc.ServiceContext, userIdentity, notificationType, title, body string, data map[string]interface{}) {
+	channel, err := notificationChannel(ctx, svcCtx, userIdentity, notificationType)
+	if err != nil {
+		log.Printf("[Notify] Failed to load preference for %s: %v", userIdentity, err)
+		channel = notificationChannelInApp
+	}
+	if channel == notificationChannelOff {
+		return
+	}
+
+	n := &models.Notification{
+		Identity:     helper.UUID(),
+		UserIdentity: userIdentity,
+		Type:         notificationType,
+		Title:        title,
+		Body:         body,
+	}
+	event := make(map[string]interface{}, len(data)+1)
+	for k, v := range data {
+		event[k] = v
+	}
+	event["notification_identity"] = n.Identity
+	svcCtx.Events.Emit(ctx, userIdentity, notificationType, event)
+
+	payload, err := json.Marshal(data)
+	if err != nil {
+		log.Printf("[Notify] Failed to encode %s data: %v", notificationType, err)
+		return
+	}
+	n.Data = string(payload)
+	if err = svcCtx.DB.WithContext(ctx).Create(n).Error; err != nil {
+		log.Printf("[Notify] Failed to store %s for %s: %v", notificationType, userIdentity, err)
+		return
+	}
+
+	if channel == notificationChannelEmail {
+		ub := new(models.UserBasic)
+		err = svcCtx.DB.WithContext(ctx).Select("email").Where("identity = ?", userIdentity).First(ub).Error
+		if err == nil && ub.Email != "" {
+			err = helper.MailSendNotification(ub.Email, title, body)
+		}
+		if err != nil {
+			log.Printf("[Notify] Failed to email %s to %s: %v", notificationType, userIdentity, err)
+		}
+	}
+}
+
+// notifyQuotaThreshold notifies the user when an increase in used storage
+// from before to after crosses one of the quota thresholds
+func notifyQuotaThreshold(ctx context.Context, svcCtx *svc.ServiceContext, userIdentity string, before, after, total int64) {
+	if total <= 0 {
+		return
+	}
+	var crossed int64
+	for _, pct := range quotaThresholds {
+		limit := total * pct / 100
+		if before < limit && after >= limit {
+			crossed = pct
+		}
+	}
+	if crossed == 0 {
+		return
+	}
+	notify(ctx, svcCtx, userIdentity, events.QuotaThreshold,
+		fmt.Sprintf("Storage %d%% full", crossed),
+		fmt.Sprintf("You have used %d%% of your storage. Free up space or buy more to keep uploading.", after*100/total),
+		map[string]interface{}{
+			"threshold":    crossed,
+			"now_volume":   after,
+			"total_volume": total,
+		})
+}
+
+// displayName returns a user's name for notification texts
+func displayName(ctx context.Context, svcCtx *svc.ServiceContext, userIdentity string) string {
+	ub := new(models.UserBasic)
+	if err := svcCtx.DB.WithContext(ctx).Select("name").Where("identity = ?", userIdentity).First(ub).Error; err != nil || ub.Name == "" {
+		return "Someone"
+	}
+	return ub.Name
+}
+
+// notifyStorageOrderPaid confirms a completed storage purchase
+func notifyStorageOrderPaid(ctx context.Context, svcCtx *svc.ServiceContext, order *models.StorageOrder) {
+	notify(ctx, svcCtx, order.UserIdentity, events.StorageOrderPaid,
+		"Payment received",
+		fmt.Sprintf("Your storage has been increased by %d GB.", order.StorageAmount/(1024*1024*1024)),
+		map[string]interface{}{
+			"identity":       order.Identity,
+			"storage_amount": order.StorageAmount,
+		})
+}
@@ -0,0 +1,174 @@
+package logic
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"strings"
+	"time"
+
+	"cloud-dist/core/helper"
+	"cloud-dist/core/models"
+	"cloud-dist/core/oidc"
+	"cloud-dist/core/svc"
+
+	"gorm.io/gorm"
+)
+
+// oidcStateTTL bounds how long the user may spend at the identity provider
+const oidcStateTTL = 10 * time.Minute
+
+var (
+	errOIDCStateInvalid    = errors.New("sign-in request expired or is invalid, please try again")
+	errOIDCSignupDisabled  = errors.New("no account is linked to this identity")
+	errOIDCEmailAmbiguous  = errors.New("more than one account uses this email, sign in with your password instead")
+	errOIDCEmailUnverified = errors.New("an account already uses this email; verify it with your identity provider to link it")
+)
+
+// oidcLoginState is what the start step remembers for the callback
+type oidcLoginState struct {
+	Verifier   string `json:"verifier"`
+	Nonce      string `json:"nonce"`
+	DeviceName string `json:"device_name"`
+}
+
+func oidcStateKey(state string) string {
+	return "oidc_state:" + state
+}
+
+// takeOIDCState loads and deletes the state in one step, so a callback can
+// be completed only once
+func takeOIDCState(ctx context.Context, svcCtx *svc.ServiceContext, state string) (*oidcLoginState, error) {
+	if state == "" {
+		return nil, errOIDCStateInvalid
+	}
+	pipe := svcCtx.RDB.TxPipeline()
+	get := pipe.Get(ctx, oidcStateKey(state))
+	pipe.Del(ctx, oidcStateKey(state))
+	if _, err := pipe.Exec(ctx); err != nil {
+		return nil, errOIDCStateInvalid
+	}
+	s := new(oidcLoginState)
+	if err := json.Unmarshal([]byte(get.Val()), s); err != nil {
+		return nil, errOIDCStateInvalid
+	}
+	return s, nil
+}
+
+// resolveOIDCUser finds the account for a verified ID token. In order:
+// an existing link, an account with the same verified email (which is then
+// linked), or a new account when signup is allowed.
+func resolveOIDCUser(ctx context.Context, svcCtx *svc.ServiceContext, claims *oidc.Claims) (*models.UserBasic, error) {
+	db := svcCtx.DB.WithContext(ctx)
+	now := time.Now()
+
+	link := new(models.UserExternalIdentity)
+	err := db.Where("issuer = ? AND subject = ?", claims.Issuer, claims.Subject).First(link).Error
+	if err == nil {
+		user, err := findUserByIdentity(ctx, svcCtx, link.UserIdentity)
+		if err != nil {
+			return nil, err
+		}
+		db.Model(link).Updates(map[string]interface{}{"email": claims.Email, "last_login_at": now})
+		return user, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	email := strings.TrimSpace(claims.Email)
+	var user *models.UserBasic
+	if email != "" {
+		var matches []*models.UserBasic
+		if err = db.Where("email = ?", email).Limit(2).Find(&matches).Error; err != nil {
+			return nil, err
+		}
+		switch {
+		case len(matches) > 0 && !claims.EmailVerified:
+			return nil, errOIDCEmailUnverified
+		case len(matches) > 1:
+			return nil, errOIDCEmailAmbiguous
+		case len(matches) == 1:
+			user = matches[0]
+			log.Printf("[OIDC] Linking %s subject %s to user %s by verified email", claims.Issuer, claims.Subject, user.Identity)
+		}
+	}
+
+	if user == nil {
+		if svcCtx.Config.OIDC.DisableSignup {
+			return nil, errOIDCSignupDisabled
+		}
+		if user, err = provisionOIDCUser(ctx, svcCtx, claims); err != nil {
+			return nil, err
+		}
+	}
+
+	link = &models.UserExternalIdentity{
+		UserIdentity: user.Identity,
+		Issuer:       claims.Issuer,
+		Subject:      claims.Subject,
+		Email:        claims.Email,
+		LastLoginAt:  &now,
+	}
+	if err = db.Create(link).Error; err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// provisionOIDCUser creates an account on first sign-in. It has no password;
+// one can be set later through password reset. An unverified email is not
+// stored, so it cannot claim invites meant for someone else.
+func provisionOIDCUser(ctx context.Context, svcCtx *svc.ServiceContext, claims *oidc.Claims) (*models.UserBasic, error) {
+	email := ""
+	if claims.EmailVerified {
+		email = strings.TrimSpace(claims.Email)
+	}
+	name, err := uniqueUserName(ctx, svcCtx, oidcUserName(claims))
+	if err != nil {
+		return nil, err
+	}
+	user := &models.UserBasic{
+		Identity:    helper.UUID(),
+		Name:        name,
+		Email:       email,
+		NowVolume:   0,
+		TotalVolume: 16106127360, // Same default as UserRegister
+	}
+	if err = svcCtx.DB.WithContext(ctx).Create(user).Error; err != nil {
+		return nil, err
+	}
+	log.Printf("[OIDC] Provisioned user %s for %s subject %s", user.Identity, claims.Issuer, claims.Subject)
+	if email != "" {
+		claimShareInvites(ctx, svcCtx, user)
+	}
+	return user, nil
+}
+
+// oidcUserName picks a username hint from the token claims
+func oidcUserName(claims *oidc.Claims) string {
+	for _, v := range []string{claims.PreferredUsername, strings.Split(claims.Email, "@")[0], claims.Name} {
+		if v = strings.TrimSpace(v); v != "" {
+			return v
+		}
+	}
+	return "user"
+}
+
+// uniqueUserName returns base, or base with a short suffix when it is taken
+func uniqueUserName(ctx context.Context, svcCtx *svc.ServiceContext, base string) (string, error) {
+	name := base
+	for i := 0; i < 5; i++ {
+		var cnt int64
+		if err := svcCtx.DB.WithContext(ctx).Model(&models.UserBasic{}).
+			Where("name = ?", name).Count(&cnt).Error; err != nil {
+			return "", err
+		}
+		if cnt == 0 {
+			return name, nil
+		}
+		name = base + "-" + helper.UUID()[:6]
+	}
+	return "", errors.New("could not pick a free username")
+}
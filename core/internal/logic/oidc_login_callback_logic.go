@@ -0,0 +1,61 @@
+package logic
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strings"
+
+	"cloud-dist/core/internal/types"
+	"cloud-dist/core/oidc"
+	"cloud-dist/core/svc"
+)
+
+type OIDCLoginCallbackLogic struct {
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewOIDCLoginCallbackLogic(ctx context.Context, svcCtx *svc.ServiceContext) *OIDCLoginCallbackLogic {
+	return &OIDCLoginCallbackLogic{
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// OIDCLoginCallback finishes a sign-in: it redeems the code, validates the ID
+// token and logs in the linked, matched or newly created account
+func (l *OIDCLoginCallbackLogic) OIDCLoginCallback(req *types.OIDCLoginCallbackRequest) (resp *types.LoginReply, err error) {
+	if l.svcCtx.OIDC == nil {
+		return nil, oidc.ErrDisabled
+	}
+	code := strings.TrimSpace(req.Code)
+	if code == "" {
+		return nil, errors.New("authorization code is required")
+	}
+	state, err := takeOIDCState(l.ctx, l.svcCtx, strings.TrimSpace(req.State))
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := l.svcCtx.OIDC.Exchange(l.ctx, code, state.Verifier)
+	if err != nil {
+		log.Printf("[OIDC] Code exchange failed: %v", err)
+		return nil, errors.New("sign-in with the identity provider failed")
+	}
+	claims, err := l.svcCtx.OIDC.VerifyIDToken(l.ctx, token.IDToken, state.Nonce)
+	if err != nil {
+		log.Printf("[OIDC] %v", err)
+		return nil, oidc.ErrInvalidToken
+	}
+
+	user, err := resolveOIDCUser(l.ctx, l.svcCtx, claims)
+	if err != nil {
+		return nil, err
+	}
+	// A local second factor still applies on top of the provider's login
+	if user.TOTPEnabled {
+		return newLoginChallenge(l.ctx, l.svcCtx, user)
+	}
+	return startSession(l.ctx, l.svcCtx, user, state.DeviceName)
+}
@@ -0,0 +1,52 @@
+package logic
+
+import (
+	"context"
+	"encoding/json"
+
+	"cloud-dist/core/internal/types"
+	"cloud-dist/core/oidc"
+	"cloud-dist/core/svc"
+)
+
+type OIDCLoginStartLogic struct {
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewOIDCLoginStartLogic(ctx context.Context, svcCtx *svc.ServiceContext) *OIDCLoginStartLogic {
+	return &OIDCLoginStartLogic{
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// OIDCLoginStart returns the identity provider URL for a new sign-in. The
+// PKCE verifier and nonce stay server side, keyed by state.
+func (l *OIDCLoginStartLogic) OIDCLoginStart(req *types.OIDCLoginStartRequest) (resp *types.OIDCLoginStartReply, err error) {
+	if l.svcCtx.OIDC == nil {
+		return nil, oidc.ErrDisabled
+	}
+	verifier, challenge, err := oidc.NewPKCE()
+	if err != nil {
+		return nil, err
+	}
+	state, err := oidc.RandomString(24)
+	if err != nil {
+		return nil, err
+	}
+	nonce, err := oidc.RandomString(24)
+	if err != nil {
+		return nil, err
+	}
+	authURL, err := l.svcCtx.OIDC.AuthCodeURL(l.ctx, state, nonce, challenge)
+	if err != nil {
+		return nil, err
+	}
+
+	data, _ := json.Marshal(&oidcLoginState{Verifier: verifier, Nonce: nonce, DeviceName: req.DeviceName})
+	if err = l.svcCtx.RDB.Set(l.ctx, oidcStateKey(state), data, oidcStateTTL).Err(); err != nil {
+		return nil, err
+	}
+	return &types.OIDCLoginStartReply{AuthorizationURL: authURL, State: state}, nil
+}
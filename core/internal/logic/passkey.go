@@ -0,0 +1,144 @@
+package logic
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"time"
+
+	"cloud-dist/core/define"
+	"cloud-dist/core/internal/types"
+	"cloud-dist/core/models"
+	"cloud-dist/core/svc"
+	"cloud-dist/core/webauthn"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// passkeyCeremonyTTL is how long a begun registration or login stays open
+	passkeyCeremonyTTL = 5 * time.Minute
+	maxPasskeysPerUser = 20
+	passkeyNameMaxLen  = 64
+)
+
+var errPasskeyCeremonyInvalid = errors.New("passkey request expired or is invalid, please try again")
+
+// passkeyCeremony is what a begin step remembers for its finish step
+type passkeyCeremony struct {
+	Challenge    string `json:"challenge"`
+	UserIdentity string `json:"user_identity,omitempty"` // Empty for a passwordless login
+	LoginToken   string `json:"login_token,omitempty"`   // Login challenge met by this passkey as a second factor
+}
+
+func passkeyRegisterKey(userIdentity string) string {
+	return "webauthn_register:" + userIdentity
+}
+
+func passkeyLoginKey(session string) string {
+	return "webauthn_login:" + session
+}
+
+func savePasskeyCeremony(ctx context.Context, svcCtx *svc.ServiceContext, key string, c *passkeyCeremony) error {
+	data, _ := json.Marshal(c)
+	return svcCtx.RDB.Set(ctx, key, data, passkeyCeremonyTTL).Err()
+}
+
+// takePasskeyCeremony loads and deletes a ceremony, so each challenge can be
+// answered only once
+func takePasskeyCeremony(ctx context.Context, svcCtx *svc.ServiceContext, key string) (*passkeyCeremony, error) {
+	pipe := svcCtx.RDB.TxPipeline()
+	get := pipe.Get(ctx, key)
+	pipe.Del(ctx, key)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return nil, errPasskeyCeremonyInvalid
+	}
+	c := new(passkeyCeremony)
+	if err := json.Unmarshal([]byte(get.Val()), c); err != nil {
+		return nil, errPasskeyCeremonyInvalid
+	}
+	return c, nil
+}
+
+// passkeyCredentialIDs lists a user's credential IDs for allow and exclude lists
+func passkeyCredentialIDs(ctx context.Context, svcCtx *svc.ServiceContext, userIdentity string) ([]string, error) {
+	ids := make([]string, 0)
+	err := svcCtx.DB.WithContext(ctx).Model(&models.WebAuthnCredential{}).
+		Where("user_identity = ?", userIdentity).
+		Pluck("credential_id", &ids).Error
+	return ids, err
+}
+
+// verifyPasskeyAssertion checks an assertion against the stored credential
+// and records the new counter. A counter that went backwards is refused and
+// logged, as it points to a cloned authenticator.
+func verifyPasskeyAssertion(ctx context.Context, svcCtx *svc.ServiceContext, challenge string, in *types.PasskeyAssertion, requireUV bool) (*models.WebAuthnCredential, error) {
+	a := new(webauthn.Assertion)
+	var err error
+	for dst, src := range map[*[]byte]string{
+		&a.CredentialID:      in.CredentialID,
+		&a.ClientDataJSON:    in.ClientDataJSON,
+		&a.AuthenticatorData: in.AuthenticatorData,
+		&a.Signature:         in.Signature,
+		&a.UserHandle:        in.UserHandle,
+	} {
+		if *dst, err = webauthn.DecodeBase64(src); err != nil {
+			return nil, webauthn.ErrInvalid
+		}
+	}
+
+	cred := new(models.WebAuthnCredential)
+	err = svcCtx.DB.WithContext(ctx).Where("credential_id = ?", webauthn.EncodeBase64(a.CredentialID)).First(cred).Error
+	if err != nil {
+		return nil, errors.New("passkey is not registered")
+	}
+	// The user handle, when sent, must name the credential's owner
+	if len(a.UserHandle) > 0 && string(a.UserHandle) != cred.UserIdentity {
+		return nil, webauthn.ErrInvalid
+	}
+
+	count, err := svcCtx.WebAuthn.VerifyAssertion(challenge, a, cred.PublicKey, cred.SignCount, requireUV)
+	if errors.Is(err, webauthn.ErrCounterRegressed) {
+		log.Printf("[Passkey] Counter regressed for credential %s of user %s", cred.Identity, cred.UserIdentity)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// The conditional update keeps two concurrent logins from both passing
+	// with the same counter value
+	now := time.Now()
+	result := svcCtx.DB.WithContext(ctx).Model(&models.WebAuthnCredential{}).
+		Where("id = ? AND sign_count = ?", cred.ID, cred.SignCount).
+		Updates(map[string]interface{}{"sign_count": count, "last_used_at": now})
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 && count != 0 {
+		return nil, webauthn.ErrCounterRegressed
+	}
+	cred.SignCount = count
+	cred.LastUsedAt = &now
+	return cred, nil
+}
+
+func passkeyItem(cred *models.WebAuthnCredential) *types.PasskeyItem {
+	item := &types.PasskeyItem{
+		Identity:       cred.Identity,
+		Name:           cred.Name,
+		BackupEligible: cred.BackupEligible,
+		CreatedAt:      cred.CreatedAt.Format(define.Datetime),
+	}
+	if cred.LastUsedAt != nil {
+		item.LastUsedAt = cred.LastUsedAt.Format(define.Datetime)
+	}
+	return item
+}
+
+// deletePasskeys removes every passkey of the user. A password reset or an
+// undone email change may mean someone else held the account and registered
+// their own passkey, which would otherwise let them straight back in.
+func deletePasskeys(tx *gorm.DB, userIdentity string) error {
+	return tx.Where("user_identity = ?", userIdentity).Delete(&models.WebAuthnCredential{}).Error
+}
@@ -0,0 +1,47 @@
+package logic
+
+import (
+	"context"
+	"errors"
+
+	"cloud-dist/core/audit"
+	"cloud-dist/core/internal/types"
+	"cloud-dist/core/models"
+	"cloud-dist/core/svc"
+)
+
+type PasskeyDeleteLogic struct {
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewPasskeyDeleteLogic(ctx context.Context, svcCtx *svc.ServiceContext) *PasskeyDeleteLogic {
+	return &PasskeyDeleteLogic{
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// PasskeyDelete removes a passkey after confirming the user, so a stolen
+// session cannot strip the owner's sign-in methods
+func (l *PasskeyDeleteLogic) PasskeyDelete(req *types.PasskeyDeleteRequest, userIdentity string) (resp *types.PasskeyDeleteReply, err error) {
+	user, err := findUserByIdentity(l.ctx, l.svcCtx, userIdentity)
+	if err != nil {
+		return nil, err
+	}
+	if err = reauthenticate(l.ctx, l.svcCtx, user, req.Password, req.EmailCode, req.TwoFactorCode); err != nil {
+		return nil, err
+	}
+	result := l.svcCtx.DB.WithContext(l.ctx).
+		Where("identity = ? AND user_identity = ?", req.Identity, userIdentity).
+		Delete(&models.WebAuthnCredential{})
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, errors.New("passkey not found")
+	}
+	audit.Record(l.ctx, l.svcCtx.DB, audit.Entry{Actor: userIdentity, Action: audit.ActionPasskeyRemove,
+		TargetType: audit.TargetPasskey, Target: req.Identity})
+	return &types.PasskeyDeleteReply{}, nil
+}
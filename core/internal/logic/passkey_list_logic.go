@@ -0,0 +1,39 @@
+package logic
+
+import (
+	"context"
+
+	"cloud-dist/core/internal/types"
+	"cloud-dist/core/models"
+	"cloud-dist/core/svc"
+)
+
+type PasskeyListLogic struct {
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewPasskeyListLogic(ctx context.Context, svcCtx *svc.ServiceContext) *PasskeyListLogic {
+	return &PasskeyListLogic{
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *PasskeyListLogic) PasskeyList(req *types.PasskeyListRequest, userIdentity string) (resp *types.PasskeyListReply, err error) {
+	var creds []*models.WebAuthnCredential
+	err = l.svcCtx.DB.WithContext(l.ctx).
+		Where("user_identity = ?", userIdentity).
+		Order("created_at DESC").
+		Find(&creds).Error
+	if err != nil {
+		return nil, err
+	}
+
+	resp = new(types.PasskeyListReply)
+	resp.List = make([]*types.PasskeyItem, 0, len(creds))
+	for _, cred := range creds {
+		resp.List = append(resp.List, passkeyItem(cred))
+	}
+	return resp, nil
+}
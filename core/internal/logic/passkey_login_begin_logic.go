@@ -0,0 +1,64 @@
+package logic
+
+import (
+	"context"
+	"errors"
+
+	"cloud-dist/core/internal/types"
+	"cloud-dist/core/svc"
+	"cloud-dist/core/webauthn"
+)
+
+type PasskeyLoginBeginLogic struct {
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewPasskeyLoginBeginLogic(ctx context.Context, svcCtx *svc.ServiceContext) *PasskeyLoginBeginLogic {
+	return &PasskeyLoginBeginLogic{
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// PasskeyLoginBegin returns the options for navigator.credentials.get(). On
+// its own it starts a passwordless login with any discoverable passkey; with
+// a login challenge token it asks for one of that user's passkeys instead.
+func (l *PasskeyLoginBeginLogic) PasskeyLoginBegin(req *types.PasskeyLoginBeginRequest) (resp *types.PasskeyLoginBeginReply, err error) {
+	ceremony := new(passkeyCeremony)
+	resp = &types.PasskeyLoginBeginReply{
+		RPID:             l.svcCtx.WebAuthn.RPID(),
+		AllowCredentials: []string{},
+		UserVerification: "required",
+		Timeout:          passkeyCeremonyTTL.Milliseconds(),
+	}
+
+	if req.ChallengeToken != "" {
+		userIdentity, err := l.svcCtx.RDB.Get(l.ctx, loginChallengeKey(req.ChallengeToken)).Result()
+		if err != nil {
+			return nil, errors.New("login challenge is expired or not found, please log in again")
+		}
+		if resp.AllowCredentials, err = passkeyCredentialIDs(l.ctx, l.svcCtx, userIdentity); err != nil {
+			return nil, err
+		}
+		if len(resp.AllowCredentials) == 0 {
+			return nil, errors.New("no passkey is registered for this account")
+		}
+		// The password was the first factor, presence is enough here
+		resp.UserVerification = "preferred"
+		ceremony.UserIdentity = userIdentity
+		ceremony.LoginToken = req.ChallengeToken
+	}
+
+	if ceremony.Challenge, err = webauthn.NewChallenge(); err != nil {
+		return nil, err
+	}
+	if resp.Session, err = webauthn.NewChallenge(); err != nil {
+		return nil, err
+	}
+	if err = savePasskeyCeremony(l.ctx, l.svcCtx, passkeyLoginKey(resp.Session), ceremony); err != nil {
+		return nil, err
+	}
+	resp.Challenge = ceremony.Challenge
+	return resp, nil
+}
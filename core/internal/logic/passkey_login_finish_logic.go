@@ -0,0 +1,84 @@
+package logic
+
+import (
+	"context"
+	"errors"
+
+	"cloud-dist/core/internal/types"
+	"cloud-dist/core/svc"
+)
+
+type PasskeyLoginFinishLogic struct {
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewPasskeyLoginFinishLogic(ctx context.Context, svcCtx *svc.ServiceContext) *PasskeyLoginFinishLogic {
+	return &PasskeyLoginFinishLogic{
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *PasskeyLoginFinishLogic) PasskeyLoginFinish(req *types.PasskeyLoginFinishRequest) (resp *types.LoginReply, err error) {
+	if req.Session == "" {
+		return nil, errPasskeyCeremonyInvalid
+	}
+	ceremony, err := takePasskeyCeremony(l.ctx, l.svcCtx, passkeyLoginKey(req.Session))
+	if err != nil {
+		return nil, err
+	}
+	secondFactor := ceremony.LoginToken != ""
+
+	// As a second factor, a failed passkey counts towards the account
+	// lockout like a wrong TOTP code
+	var target *loginTarget
+	if secondFactor {
+		pending, err := findUserByIdentity(l.ctx, l.svcCtx, ceremony.UserIdentity)
+		if err != nil {
+			return nil, err
+		}
+		target = newLoginTarget(l.ctx, pending.Name, pending)
+		if err = checkLoginAllowed(l.ctx, l.svcCtx, target); err != nil {
+			return nil, err
+		}
+	}
+
+	// A passwordless login needs user verification (PIN or biometrics), which
+	// makes the passkey count as both factors
+	cred, err := verifyPasskeyAssertion(l.ctx, l.svcCtx, ceremony.Challenge, &req.PasskeyAssertion, !secondFactor)
+	if err == nil && secondFactor && cred.UserIdentity != ceremony.UserIdentity {
+		err = errors.New("passkey belongs to another account")
+	}
+	if err != nil {
+		if target != nil {
+			if lockErr := recordLoginFailure(l.ctx, l.svcCtx, target, "wrong_second_factor"); lockErr != nil {
+				return nil, lockErr
+			}
+		}
+		return nil, err
+	}
+	user, err := findUserByIdentity(l.ctx, l.svcCtx, cred.UserIdentity)
+	if err != nil {
+		return nil, err
+	}
+
+	if secondFactor {
+		// The login challenge is used up, as after a TOTP code
+		deleted, err := l.svcCtx.RDB.Del(l.ctx, loginChallengeKey(ceremony.LoginToken)).Result()
+		if err != nil {
+			return nil, err
+		}
+		if deleted == 0 {
+			return nil, errors.New("login challenge is expired or not found, please log in again")
+		}
+		l.svcCtx.RDB.Del(l.ctx, loginChallengeAttemptsKey(ceremony.LoginToken))
+		clearLoginFailures(l.ctx, l.svcCtx, target)
+	} else {
+		target := newLoginTarget(l.ctx, user.Name, user)
+		if err = checkLoginAllowed(l.ctx, l.svcCtx, target); err != nil {
+			return nil, err
+		}
+	}
+	return startSession(l.ctx, l.svcCtx, user, req.DeviceName)
+}
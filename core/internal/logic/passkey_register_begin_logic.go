@@ -0,0 +1,62 @@
+package logic
+
+import (
+	"context"
+	"errors"
+
+	"cloud-dist/core/internal/types"
+	"cloud-dist/core/svc"
+	"cloud-dist/core/webauthn"
+)
+
+type PasskeyRegisterBeginLogic struct {
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewPasskeyRegisterBeginLogic(ctx context.Context, svcCtx *svc.ServiceContext) *PasskeyRegisterBeginLogic {
+	return &PasskeyRegisterBeginLogic{
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// PasskeyRegisterBegin returns the options for navigator.credentials.create().
+// The user handle is the user identity, which never changes. A passkey can
+// later sign in on its own, so adding one needs the same confirmation as
+// other account changes; the finish step is bound to this ceremony.
+func (l *PasskeyRegisterBeginLogic) PasskeyRegisterBegin(req *types.PasskeyRegisterBeginRequest, userIdentity string) (resp *types.PasskeyRegisterBeginReply, err error) {
+	user, err := findUserByIdentity(l.ctx, l.svcCtx, userIdentity)
+	if err != nil {
+		return nil, err
+	}
+	if err = reauthenticate(l.ctx, l.svcCtx, user, req.Password, req.EmailCode, req.TwoFactorCode); err != nil {
+		return nil, err
+	}
+	existing, err := passkeyCredentialIDs(l.ctx, l.svcCtx, userIdentity)
+	if err != nil {
+		return nil, err
+	}
+	if len(existing) >= maxPasskeysPerUser {
+		return nil, errors.New("too many passkeys, remove one first")
+	}
+
+	challenge, err := webauthn.NewChallenge()
+	if err != nil {
+		return nil, err
+	}
+	if err = savePasskeyCeremony(l.ctx, l.svcCtx, passkeyRegisterKey(userIdentity), &passkeyCeremony{Challenge: challenge, UserIdentity: userIdentity}); err != nil {
+		return nil, err
+	}
+	return &types.PasskeyRegisterBeginReply{
+		Challenge:          challenge,
+		RPID:               l.svcCtx.WebAuthn.RPID(),
+		RPName:             l.svcCtx.WebAuthn.RPName(),
+		UserID:             webauthn.EncodeBase64([]byte(user.Identity)),
+		UserName:           user.Name,
+		UserDisplayName:    user.Name,
+		Algorithms:         webauthn.Algorithms,
+		ExcludeCredentials: existing,
+		Timeout:            passkeyCeremonyTTL.Milliseconds(),
+	}, nil
+}
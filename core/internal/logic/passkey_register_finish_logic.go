@@ -0,0 +1,87 @@
+package logic
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"strings"
+
+	"cloud-dist/core/audit"
+	"cloud-dist/core/helper"
+	"cloud-dist/core/internal/types"
+	"cloud-dist/core/models"
+	"cloud-dist/core/svc"
+	"cloud-dist/core/webauthn"
+)
+
+type PasskeyRegisterFinishLogic struct {
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewPasskeyRegisterFinishLogic(ctx context.Context, svcCtx *svc.ServiceContext) *PasskeyRegisterFinishLogic {
+	return &PasskeyRegisterFinishLogic{
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *PasskeyRegisterFinishLogic) PasskeyRegisterFinish(req *types.PasskeyRegisterFinishRequest, userIdentity string) (resp *types.PasskeyRegisterFinishReply, err error) {
+	ceremony, err := takePasskeyCeremony(l.ctx, l.svcCtx, passkeyRegisterKey(userIdentity))
+	if err != nil {
+		return nil, err
+	}
+	clientData, err := webauthn.DecodeBase64(req.ClientDataJSON)
+	if err != nil {
+		return nil, webauthn.ErrInvalid
+	}
+	attestation, err := webauthn.DecodeBase64(req.AttestationObject)
+	if err != nil {
+		return nil, webauthn.ErrInvalid
+	}
+	// User verification is required so the passkey can later stand in for
+	// both password and second factor
+	cred, err := l.svcCtx.WebAuthn.VerifyRegistration(ceremony.Challenge, clientData, attestation, true)
+	if err != nil {
+		return nil, err
+	}
+	credentialID := webauthn.EncodeBase64(cred.ID)
+	if req.CredentialID != "" && strings.TrimRight(req.CredentialID, "=") != credentialID {
+		return nil, webauthn.ErrInvalid
+	}
+
+	var cnt int64
+	if err = l.svcCtx.DB.WithContext(l.ctx).Model(&models.WebAuthnCredential{}).
+		Where("credential_id = ?", credentialID).Count(&cnt).Error; err != nil {
+		return nil, err
+	}
+	if cnt > 0 {
+		return nil, errors.New("this passkey is already registered")
+	}
+
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		name = deviceNameFromUserAgent(helper.ClientMetaFromContext(l.ctx).UserAgent)
+	}
+	if len(name) > passkeyNameMaxLen {
+		return nil, errors.New("passkey name is too long")
+	}
+	row := &models.WebAuthnCredential{
+		Identity:       helper.UUID(),
+		UserIdentity:   userIdentity,
+		Name:           name,
+		CredentialID:   credentialID,
+		PublicKey:      cred.PublicKey,
+		Algorithm:      cred.Algorithm,
+		SignCount:      cred.SignCount,
+		AAGUID:         hex.EncodeToString(cred.AAGUID),
+		Transports:     strings.Join(req.Transports, ","),
+		BackupEligible: cred.BackupEligible,
+	}
+	if err = l.svcCtx.DB.WithContext(l.ctx).Create(row).Error; err != nil {
+		return nil, err
+	}
+	audit.Record(l.ctx, l.svcCtx.DB, audit.Entry{Actor: userIdentity, Action: audit.ActionPasskeyAdd,
+		TargetType: audit.TargetPasskey, Target: row.Identity, Detail: map[string]interface{}{"name": row.Name}})
+	return &types.PasskeyRegisterFinishReply{Item: passkeyItem(row)}, nil
+}
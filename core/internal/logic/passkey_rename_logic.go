@@ -0,0 +1,49 @@
+package logic
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"cloud-dist/core/internal/types"
+	"cloud-dist/core/models"
+	"cloud-dist/core/svc"
+
+	"gorm.io/gorm"
+)
+
+type PasskeyRenameLogic struct {
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewPasskeyRenameLogic(ctx context.Context, svcCtx *svc.ServiceContext) *PasskeyRenameLogic {
+	return &PasskeyRenameLogic{
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *PasskeyRenameLogic) PasskeyRename(req *types.PasskeyRenameRequest, userIdentity string) (resp *types.PasskeyRenameReply, err error) {
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return nil, errors.New("passkey name is required")
+	}
+	if len(name) > passkeyNameMaxLen {
+		return nil, errors.New("passkey name is too long")
+	}
+	cred := new(models.WebAuthnCredential)
+	err = l.svcCtx.DB.WithContext(l.ctx).
+		Where("identity = ? AND user_identity = ?", req.Identity, userIdentity).
+		First(cred).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, errors.New("passkey not found")
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err = l.svcCtx.DB.WithContext(l.ctx).Model(cred).Update("name", name).Error; err != nil {
+		return nil, err
+	}
+	return &types.PasskeyRenameReply{}, nil
+}
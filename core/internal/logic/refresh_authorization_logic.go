@@ -3,12 +3,15 @@ package logic
 import (
 	"context"
 	"errors"
+	"log"
 	"time"
 
+	"cloud-dist/core/audit"
 	"cloud-dist/core/define"
 	"cloud-dist/core/helper"
 	"cloud-dist/core/svc"
 	"cloud-dist/core/internal/types"
+	"cloud-dist/core/models"
 )
 
 type RefreshAuthorizationLogic struct {
@@ -29,6 +32,9 @@ func (l *RefreshAuthorizationLogic) RefreshAuthorization(req *types.RefreshAutho
 	if err != nil {
 		return nil, err
 	}
+	if uc.TokenType == define.TokenTypeAccess {
+		return nil, errors.New("an access token cannot be used to refresh")
+	}
 
 	// Check if refresh token is blacklisted
 	if l.svcCtx.RDB != nil {
@@ -40,28 +46,83 @@ func (l *RefreshAuthorizationLogic) RefreshAuthorization(req *types.RefreshAutho
 		}
 	}
 
-	// Generate new access token
-	token, err := helper.GenerateToken(uc.Id, uc.Identity, uc.Name, define.TokenExpire)
-	if err != nil {
+	// Tokens from before a password change or "log out everywhere" are dead
+	if err = l.svcCtx.TokenVersions.Check(l.ctx, uc.Identity, uc.TokenVersion); err != nil {
 		return nil, err
 	}
 
-	// Generate new refresh token (rotate refresh token for security)
-	newRefreshToken, err := helper.GenerateToken(uc.Id, uc.Identity, uc.Name, define.RefreshTokenExpire)
-	if err != nil {
+	var pair *types.LoginReply
+	if uc.SessionIdentity == "" {
+		// Tokens issued before sessions existed move into a new session
+		user, err := findUserByIdentity(l.ctx, l.svcCtx, uc.Identity)
+		if err != nil {
+			return nil, err
+		}
+		if pair, err = startSession(l.ctx, l.svcCtx, user, ""); err != nil {
+			return nil, err
+		}
+		l.svcCtx.RDB.Set(l.ctx, "refresh_token:blacklist:"+refreshToken, "blacklisted",
+			time.Duration(define.RefreshTokenExpire)*time.Second)
+	} else if pair, err = l.rotate(uc); err != nil {
 		return nil, err
 	}
 
-	// Optionally: blacklist the old refresh token (token rotation)
-	// This ensures that if an old refresh token is stolen, it can't be used after refresh
-	if l.svcCtx.RDB != nil {
-		oldBlacklistKey := "refresh_token:blacklist:" + refreshToken
-		expiration := time.Duration(define.RefreshTokenExpire) * time.Second
-		l.svcCtx.RDB.Set(l.ctx, oldBlacklistKey, "blacklisted", expiration)
-	}
-
 	resp = new(types.RefreshAuthorizationReply)
-	resp.Token = token
-	resp.RefreshToken = newRefreshToken
+	resp.Token = pair.Token
+	resp.RefreshToken = pair.RefreshToken
 	return
 }
+
+// rotate swaps the session's refresh token for a new one. Presenting any
+// older refresh token of the session means it was copied, so the whole
+// session is revoked and both holders have to log in again.
+func (l *RefreshAuthorizationLogic) rotate(uc *define.UserClaim) (*types.LoginReply, error) {
+	s := new(models.UserSession)
+	err := l.svcCtx.DB.WithContext(l.ctx).
+		Where("identity = ? AND user_identity = ?", uc.SessionIdentity, uc.Identity).
+		First(s).Error
+	if err != nil {
+		return nil, errors.New("session not found, please log in again")
+	}
+	if s.RevokedAt != nil {
+		return nil, errors.New("session has been revoked, please log in again")
+	}
+	if time.Now().After(s.ExpiresAt) {
+		return nil, errors.New("session has expired, please log in again")
+	}
+
+	meta := helper.ClientMetaFromContext(l.ctx)
+	now := time.Now()
+	newTokenID := helper.UUID()
+	result := l.svcCtx.DB.WithContext(l.ctx).Model(&models.UserSession{}).
+		Where("identity = ? AND refresh_token_id = ? AND revoked_at IS NULL", s.Identity, uc.ID).
+		Updates(map[string]interface{}{
+			"refresh_token_id": newTokenID,
+			"last_used_at":     now,
+			"ip":               meta.IP,
+			"user_agent":       meta.UserAgent,
+			"expires_at":       now.Add(time.Duration(define.RefreshTokenExpire) * time.Second),
+		})
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		log.Printf("[RefreshAuthorization] Refresh token reuse on session %s of user %s, revoking session", s.Identity, s.UserIdentity)
+		audit.Record(l.ctx, l.svcCtx.DB, audit.Entry{Subject: s.UserIdentity, Action: audit.ActionTokenRefresh,
+			TargetType: audit.TargetSession, Target: s.Identity, Reason: sessionRevokeReuse})
+		if err = revokeSessions(l.ctx, l.svcCtx, sessionRevokeReuse, "identity = ?", s.Identity); err != nil {
+			return nil, err
+		}
+		return nil, errors.New("refresh token was already used, please log in again")
+	}
+
+	s.RefreshTokenID = newTokenID
+	audit.Record(l.ctx, l.svcCtx.DB, audit.Entry{Actor: uc.Identity, Action: audit.ActionTokenRefresh,
+		TargetType: audit.TargetSession, Target: s.Identity})
+	return sessionTokens(define.UserClaim{
+		Id:           uc.Id,
+		Identity:     uc.Identity,
+		Name:         uc.Name,
+		TokenVersion: uc.TokenVersion,
+	}, s)
+}
@@ -0,0 +1,176 @@
+package logic
+
+import (
+	"context"
+	"log"
+	"strings"
+	"time"
+
+	"cloud-dist/core/audit"
+	"cloud-dist/core/define"
+	"cloud-dist/core/helper"
+	"cloud-dist/core/internal/types"
+	"cloud-dist/core/models"
+	"cloud-dist/core/svc"
+)
+
+// Why a session ended
+const (
+	sessionRevokeLogout = "logout"
+	sessionRevokeManual = "revoked"
+	sessionRevokeReuse  = "refresh_reuse"
+	// Reasons for logging out everywhere
+	sessionRevokePasswordChange = "password_change"
+	sessionRevokePasswordReset  = "password_reset"
+	sessionRevokeLogoutAll      = "logout_all"
+	sessionRevokeEmailUndo      = "email_change_undone"
+	sessionRevokeSuspended      = "suspended"
+	sessionRevokeAdmin          = "admin_logout"
+)
+
+// startSession records a new signed-in device and issues its first token
+// pair. It is the last step of every login flow, so it is also where
+// suspended accounts are turned away.
+func startSession(ctx context.Context, svcCtx *svc.ServiceContext, user *models.UserBasic, deviceName string) (*types.LoginReply, error) {
+	if user.SuspendedAt != nil {
+		audit.Record(ctx, svcCtx.DB, audit.Entry{Subject: user.Identity, Action: audit.ActionLoginFailed,
+			TargetType: audit.TargetUser, Target: user.Identity, Reason: "suspended"})
+		return nil, errAccountSuspended
+	}
+	meta := helper.ClientMetaFromContext(ctx)
+	deviceName = strings.TrimSpace(deviceName)
+	if deviceName == "" {
+		deviceName = deviceNameFromUserAgent(meta.UserAgent)
+	}
+	now := time.Now()
+	s := &models.UserSession{
+		Identity:       helper.UUID(),
+		UserIdentity:   user.Identity,
+		DeviceName:     deviceName,
+		IP:             meta.IP,
+		UserAgent:      meta.UserAgent,
+		RefreshTokenID: helper.UUID(),
+		LastUsedAt:     now,
+		ExpiresAt:      now.Add(time.Duration(define.RefreshTokenExpire) * time.Second),
+	}
+	if err := svcCtx.DB.WithContext(ctx).Create(s).Error; err != nil {
+		return nil, err
+	}
+	audit.Record(ctx, svcCtx.DB, audit.Entry{Actor: user.Identity, Action: audit.ActionLogin,
+		TargetType: audit.TargetSession, Target: s.Identity, Detail: map[string]interface{}{"device": deviceName}})
+	return sessionTokens(define.UserClaim{
+		Id:           int(user.ID),
+		Identity:     user.Identity,
+		Name:         user.Name,
+		TokenVersion: user.TokenVersion,
+	}, s)
+}
+
+// sessionTokens signs an access token and the session's current refresh
+// token. base carries the user fields and token version.
+func sessionTokens(base define.UserClaim, s *models.UserSession) (*types.LoginReply, error) {
+	base.SessionIdentity = s.Identity
+
+	access := base
+	access.TokenType = define.TokenTypeAccess
+	access.ID = helper.UUID()
+	token, err := helper.GenerateClaimsToken(access, define.TokenExpire)
+	if err != nil {
+		return nil, err
+	}
+
+	refresh := base
+	refresh.TokenType = define.TokenTypeRefresh
+	refresh.ID = s.RefreshTokenID
+	refreshToken, err := helper.GenerateClaimsToken(refresh, define.RefreshTokenExpire)
+	if err != nil {
+		return nil, err
+	}
+	return &types.LoginReply{Token: token, RefreshToken: refreshToken}, nil
+}
+
+// revokeSessions ends the active sessions that match the condition. The
+// Redis mark makes AuthMiddleware reject their access tokens right away
+// instead of when they expire.
+func revokeSessions(ctx context.Context, svcCtx *svc.ServiceContext, reason string, query string, args ...interface{}) error {
+	var identities []string
+	err := svcCtx.DB.WithContext(ctx).Model(&models.UserSession{}).
+		Where("revoked_at IS NULL").
+		Where(query, args...).
+		Pluck("identity", &identities).Error
+	if err != nil || len(identities) == 0 {
+		return err
+	}
+
+	err = svcCtx.DB.WithContext(ctx).Model(&models.UserSession{}).
+		Where("identity IN ? AND revoked_at IS NULL", identities).
+		Updates(map[string]interface{}{"revoked_at": time.Now(), "revoke_reason": reason}).Error
+	if err != nil {
+		return err
+	}
+
+	ttl := time.Duration(define.TokenExpire) * time.Second
+	for _, sid := range identities {
+		if err := svcCtx.RDB.Set(ctx, define.SessionRevokedPrefix+sid, reason, ttl).Err(); err != nil {
+			log.Printf("[Session] Failed to mark session %s revoked: %v", sid, err)
+		}
+	}
+	return nil
+}
+
+// logoutEverywhere invalidates every access and refresh token the user holds
+// by bumping their token version, and closes all their sessions
+func logoutEverywhere(ctx context.Context, svcCtx *svc.ServiceContext, userIdentity, reason string) error {
+	if err := svcCtx.TokenVersions.Bump(ctx, userIdentity); err != nil {
+		return err
+	}
+	return revokeSessions(ctx, svcCtx, reason, "user_identity = ?", userIdentity)
+}
+
+// deviceNameFromUserAgent turns a user agent into a label such as
+// "Firefox on Windows" for the session list
+func deviceNameFromUserAgent(ua string) string {
+	if ua == "" {
+		return "Unknown device"
+	}
+
+	browser := ""
+	switch {
+	case strings.Contains(ua, "Edg/"):
+		browser = "Edge"
+	case strings.Contains(ua, "OPR/"):
+		browser = "Opera"
+	case strings.Contains(ua, "Firefox/"):
+		browser = "Firefox"
+	case strings.Contains(ua, "Chrome/"):
+		browser = "Chrome"
+	case strings.Contains(ua, "Safari/"):
+		browser = "Safari"
+	}
+
+	platform := ""
+	switch {
+	case strings.Contains(ua, "iPhone"), strings.Contains(ua, "iPad"):
+		platform = "iOS"
+	case strings.Contains(ua, "Android"):
+		platform = "Android"
+	case strings.Contains(ua, "Windows"):
+		platform = "Windows"
+	case strings.Contains(ua, "Mac OS X"):
+		platform = "macOS"
+	case strings.Contains(ua, "Linux"):
+		platform = "Linux"
+	}
+
+	switch {
+	case browser != "" && platform != "":
+		return browser + " on " + platform
+	case browser != "":
+		return browser
+	case platform != "":
+		return platform
+	}
+	// Scripts and CLI tools, e.g. "curl/8.4.0"
+	name, _, _ := strings.Cut(ua, "/")
+	return strings.TrimSpace(name)
+}
@@ -0,0 +1,53 @@
+package logic
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"cloud-dist/core/audit"
+	"cloud-dist/core/helper"
+	"cloud-dist/core/models"
+	"cloud-dist/core/svc"
+)
+
+// Share access events
+const (
+	shareEventView     = "view"
+	shareEventDownload = "download"
+	shareEventPreview  = "preview"
+)
+
+// checkShareAvailable returns a refusal reason when the share can no longer be
+// accessed, or an empty string when it is still valid.
+func checkShareAvailable(sb *models.ShareBasic) string {
+	if sb.ExpiredTime > 0 {
+		expiredAt := sb.CreatedAt.Add(time.Duration(sb.ExpiredTime) * time.Second)
+		if time.Now().After(expiredAt) {
+			return "expired"
+		}
+	}
+	return ""
+}
+
+// recordShareAccess appends an access event for the share owner's analytics.
+// Failures are logged only, so that auditing never blocks the visitor.
+func recordShareAccess(ctx context.Context, svcCtx *svc.ServiceContext, sb *models.ShareBasic, event, userIdentity, reason string) {
+	meta := helper.ClientMetaFromContext(ctx)
+	entry := &models.ShareAccessLog{
+		Identity:      helper.UUID(),
+		ShareIdentity: sb.Identity,
+		OwnerIdentity: sb.UserIdentity,
+		UserIdentity:  userIdentity,
+		Event:         event,
+		Success:       reason == "",
+		Reason:        reason,
+		IP:            meta.IP,
+		UserAgent:     meta.UserAgent,
+	}
+	if err := svcCtx.DB.WithContext(ctx).Create(entry).Error; err != nil {
+		log.Printf("[ShareAccessLog] Failed to record %s event for share %s: %v", event, sb.Identity, err)
+	}
+	audit.Record(ctx, svcCtx.DB, audit.Entry{Actor: userIdentity, Subject: sb.UserIdentity, Action: audit.ActionShareAccess,
+		TargetType: audit.TargetShare, Target: sb.Identity, Reason: reason, Detail: map[string]interface{}{"event": event}})
+}
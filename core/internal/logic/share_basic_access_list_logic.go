@@ -0,0 +1,114 @@
+package logic
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"cloud-dist/core/define"
+	"cloud-dist/core/internal/types"
+	"cloud-dist/core/models"
+	"cloud-dist/core/svc"
+
+	"gorm.io/gorm"
+)
+
+type ShareBasicAccessListLogic struct {
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewShareBasicAccessListLogic(ctx context.Context, svcCtx *svc.ServiceContext) *ShareBasicAccessListLogic {
+	return &ShareBasicAccessListLogic{
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *ShareBasicAccessListLogic) ShareBasicAccessList(req *types.ShareBasicAccessListRequest, userIdentity, spaceIdentity, spaceRole string) (resp *types.ShareBasicAccessListReply, err error) {
+	// Only the owner can see who accessed the share
+	sb := new(models.ShareBasic)
+	db := l.svcCtx.DB.WithContext(l.ctx).Where("share_basic.identity = ?", req.Identity)
+	err = managedShares(db, userIdentity, spaceIdentity, spaceRole).First(sb).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, errors.New("share not found")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	size := req.Size
+	if size == 0 {
+		size = define.PageSize
+	}
+	page := req.Page
+	if page == 0 {
+		page = 1
+	}
+	offset := (page - 1) * size
+
+	resp = new(types.ShareBasicAccessListReply)
+	resp.List = make([]*types.ShareAccessItem, 0)
+
+	// Aggregate counts over the whole history of the share
+	err = l.svcCtx.DB.WithContext(l.ctx).Model(&models.ShareAccessLog{}).
+		Select("COALESCE(SUM(event = ? AND success), 0) AS views, "+
+			"COALESCE(SUM(event = ? AND success), 0) AS downloads, "+
+			"COALESCE(SUM(event = ? AND success), 0) AS previews, "+
+			"COALESCE(SUM(NOT success), 0) AS failed, "+
+			"COUNT(DISTINCT CASE WHEN success THEN IF(user_identity != '', user_identity, ip) END) AS unique_visitors",
+			shareEventView, shareEventDownload, shareEventPreview).
+		Where("share_identity = ?", sb.Identity).
+		Scan(&resp.Stats).Error
+	if err != nil {
+		return nil, err
+	}
+
+	query := l.svcCtx.DB.WithContext(l.ctx).Table("share_access_log").
+		Joins("LEFT JOIN user_basic ON share_access_log.user_identity = user_basic.identity").
+		Where("share_access_log.share_identity = ?", sb.Identity)
+	if req.Event != "" {
+		query = query.Where("share_access_log.event = ?", req.Event)
+	}
+
+	if err = query.Count(&resp.Count).Error; err != nil {
+		return nil, err
+	}
+
+	var results []struct {
+		Event        string
+		Success      bool
+		Reason       string
+		UserIdentity string
+		UserName     string
+		Ip           string
+		UserAgent    string
+		CreatedAt    time.Time
+	}
+	err = query.
+		Select("share_access_log.event, share_access_log.success, share_access_log.reason, " +
+			"share_access_log.user_identity, user_basic.name as user_name, " +
+			"share_access_log.ip, share_access_log.user_agent, share_access_log.created_at").
+		Order("share_access_log.created_at DESC").
+		Limit(size).
+		Offset(offset).
+		Scan(&results).Error
+	if err != nil {
+		return nil, err
+	}
+
+	for _, r := range results {
+		resp.List = append(resp.List, &types.ShareAccessItem{
+			Event:        r.Event,
+			Success:      r.Success,
+			Reason:       r.Reason,
+			UserIdentity: r.UserIdentity,
+			UserName:     r.UserName,
+			Ip:           r.Ip,
+			UserAgent:    r.UserAgent,
+			CreatedAt:    r.CreatedAt.Format(define.Datetime),
+		})
+	}
+
+	return
+}
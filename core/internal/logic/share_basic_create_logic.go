@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 
+	"cloud-dist/core/audit"
 	"cloud-dist/core/helper"
 	"cloud-dist/core/svc"
 	"cloud-dist/core/internal/types"
@@ -24,10 +25,16 @@ func NewShareBasicCreateLogic(ctx context.Context, svcCtx *svc.ServiceContext) *
 	}
 }
 
-func (l *ShareBasicCreateLogic) ShareBasicCreate(req *types.ShareBasicCreateRequest, userIdentity string) (resp *types.ShareBasicCreateReply, err error) {
+func (l *ShareBasicCreateLogic) ShareBasicCreate(req *types.ShareBasicCreateRequest, userIdentity, ownerIdentity string) (resp *types.ShareBasicCreateReply, err error) {
+	if req.DownloadLimit < 0 {
+		return nil, errors.New("download limit cannot be negative")
+	}
+
 	uuid := helper.UUID()
 	ur := new(models.UserRepository)
-	err = l.svcCtx.DB.WithContext(l.ctx).Where("identity = ?", req.UserRepositoryIdentity).First(ur).Error
+	err = l.svcCtx.DB.WithContext(l.ctx).
+		Where("identity = ? AND user_identity = ?", req.UserRepositoryIdentity, ownerIdentity).
+		First(ur).Error
 	if errors.Is(err, gorm.ErrRecordNotFound) {
 		return nil, errors.New("user repository not found")
 	}
@@ -41,10 +48,17 @@ func (l *ShareBasicCreateLogic) ShareBasicCreate(req *types.ShareBasicCreateRequ
 		UserRepositoryIdentity: req.UserRepositoryIdentity,
 		RepositoryIdentity:     ur.RepositoryIdentity,
 		ExpiredTime:            req.ExpiredTime,
+		DownloadLimit:          req.DownloadLimit,
 	}
 	if err = l.svcCtx.DB.WithContext(l.ctx).Create(data).Error; err != nil {
 		return
 	}
+	audit.Record(l.ctx, l.svcCtx.DB, audit.Entry{Actor: userIdentity, Action: audit.ActionShareCreate,
+		TargetType: audit.TargetShare, Target: uuid, Detail: map[string]interface{}{
+			"file":           req.UserRepositoryIdentity,
+			"expired_time":   req.ExpiredTime,
+			"download_limit": req.DownloadLimit,
+		}})
 	resp = &types.ShareBasicCreateReply{
 		Identity: uuid,
 	}
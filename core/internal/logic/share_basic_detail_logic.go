@@ -3,12 +3,17 @@ package logic
 import (
 	"context"
 	"errors"
+	"log"
+	"net/url"
 	"time"
 
+	"cloud-dist/core/define"
 	"cloud-dist/core/helper"
 	"cloud-dist/core/internal/types"
 	"cloud-dist/core/models"
 	"cloud-dist/core/svc"
+
+	"gorm.io/gorm"
 )
 
 type ShareBasicDetailLogic struct {
@@ -23,7 +28,7 @@ func NewShareBasicDetailLogic(ctx context.Context, svcCtx *svc.ServiceContext) *
 	}
 }
 
-func (l *ShareBasicDetailLogic) ShareBasicDetail(req *types.ShareBasicDetailRequest) (resp *types.ShareBasicDetailReply, err error) {
+func (l *ShareBasicDetailLogic) ShareBasicDetail(req *types.ShareBasicDetailRequest, userIdentity string) (resp *types.ShareBasicDetailReply, err error) {
 	// Verify share link exists and not expired
 	sb := new(models.ShareBasic)
 	err = l.svcCtx.DB.WithContext(l.ctx).
@@ -34,12 +39,9 @@ func (l *ShareBasicDetailLogic) ShareBasicDetail(req *types.ShareBasicDetailRequ
 	}
 
 	// Check if share link has expired
-	if sb.ExpiredTime > 0 {
-		createdAt := sb.CreatedAt
-		expiredAt := createdAt.Add(time.Duration(sb.ExpiredTime) * time.Second)
-		if time.Now().After(expiredAt) {
-			return nil, errors.New("share link has expired")
-		}
+	if reason := checkShareAvailable(sb); reason != "" {
+		recordShareAccess(l.ctx, l.svcCtx, sb, shareEventView, userIdentity, reason)
+		return nil, errors.New("share link has expired")
 	}
 
 	resp = new(types.ShareBasicDetailReply)
@@ -53,17 +55,21 @@ func (l *ShareBasicDetailLogic) ShareBasicDetail(req *types.ShareBasicDetailRequ
 		return
 	}
 
-	// Generate presigned URLs for preview and download
-	// Presigned URL expiration should match share link expiration (3 days = 72 hours)
-	// This allows preview and download without going through backend
+	recordShareAccess(l.ctx, l.svcCtx, sb, shareEventView, userIdentity, "")
+	if err = l.svcCtx.DB.WithContext(l.ctx).Model(&models.ShareBasic{}).
+		Where("identity = ?", sb.Identity).
+		UpdateColumn("click_num", gorm.Expr("click_num + 1")).Error; err != nil {
+		log.Printf("[ShareBasicDetail] Failed to update click count: %v", err)
+		err = nil
+	}
+
+	// Preview and download go through /share/basic/download with a short-lived
+	// signed token, so revoking or expiring the share cuts off access immediately
 	if resp.Path != "" {
-		// Path is S3 key
-		s3Key := resp.Path
-		// Generate presigned URL for preview (no Content-Disposition)
-		resp.Path = helper.S3PresignedURL(s3Key, 72)
-		// Generate presigned URL for download (with Content-Disposition: attachment)
-		fileName := resp.Name + resp.Ext
-		resp.DownloadUrl = helper.S3PresignedURLWithDisposition(s3Key, 72, fileName)
+		token := url.QueryEscape(helper.SignShareToken(sb.Identity,
+			time.Now().Add(time.Duration(define.ShareDownloadTokenTTL)*time.Second)))
+		resp.DownloadUrl = "/share/basic/download?identity=" + sb.Identity + "&token=" + token
+		resp.Path = resp.DownloadUrl + "&inline=1"
 	}
 
 	return
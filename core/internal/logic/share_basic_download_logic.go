@@ -0,0 +1,173 @@
+package logic
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"log"
+	"time"
+
+	"cloud-dist/core/define"
+	"cloud-dist/core/events"
+	"cloud-dist/core/helper"
+	"cloud-dist/core/internal/types"
+	"cloud-dist/core/models"
+	"cloud-dist/core/svc"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"gorm.io/gorm"
+)
+
+type ShareBasicDownloadLogic struct {
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewShareBasicDownloadLogic(ctx context.Context, svcCtx *svc.ServiceContext) *ShareBasicDownloadLogic {
+	return &ShareBasicDownloadLogic{
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// ShareDownloadResult is either a redirect target (presigned mode) or an
+// object body to stream back to the client (proxy mode).
+type ShareDownloadResult struct {
+	RedirectURL   string
+	Body          io.ReadCloser
+	FileName      string
+	ContentType   string
+	ContentLength int64
+	ContentRange  string // Set when a partial range was served
+}
+
+// ShareBasicDownload validates the download token and re-checks the share on
+// every request, so an expired, revoked or exhausted share stops working even
+// when a link has leaked.
+func (l *ShareBasicDownloadLogic) ShareBasicDownload(req *types.ShareBasicDownloadRequest, userIdentity string) (*ShareDownloadResult, error) {
+	if err := helper.VerifyShareToken(req.Identity, req.Token); err != nil {
+		return nil, err
+	}
+
+	// Revoked shares are soft-deleted and therefore not found here
+	sb := new(models.ShareBasic)
+	err := l.svcCtx.DB.WithContext(l.ctx).
+		Where("identity = ?", req.Identity).
+		First(sb).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, errors.New("share not found")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if reason := checkShareAvailable(sb); reason != "" {
+		recordShareAccess(l.ctx, l.svcCtx, sb, shareEventDownload, userIdentity, reason)
+		return nil, errors.New("share link has expired")
+	}
+
+	var file struct {
+		Name string
+		Ext  string
+		Path string
+	}
+	err = l.svcCtx.DB.WithContext(l.ctx).Table("share_basic").
+		Select("user_repository.name, repository_pool.ext, repository_pool.path").
+		Joins("LEFT JOIN repository_pool ON share_basic.repository_identity = repository_pool.identity").
+		Joins("LEFT JOIN user_repository ON user_repository.identity = share_basic.user_repository_identity").
+		Where("share_basic.identity = ?", sb.Identity).
+		Take(&file).Error
+	if err != nil {
+		return nil, err
+	}
+	if file.Path == "" {
+		return nil, errors.New("file path is empty")
+	}
+	fileName := file.Name + file.Ext
+
+	// Previews do not use up the download limit, otherwise opening the share
+	// page would spend a one-time link. They are logged once per download
+	// token, since a player fetches the file with many range requests.
+	if req.Inline {
+		if sb.DownloadLimit > 0 && sb.DownloadNum >= sb.DownloadLimit {
+			recordShareAccess(l.ctx, l.svcCtx, sb, shareEventPreview, userIdentity, "limit_reached")
+			return nil, errors.New("share download limit reached")
+		}
+		if l.firstInlineFetch(req) {
+			recordShareAccess(l.ctx, l.svcCtx, sb, shareEventPreview, userIdentity, "")
+		}
+	} else if err = l.countDownload(sb, fileName, userIdentity); err != nil {
+		return nil, err
+	}
+
+	if define.ShareDownloadMode == define.ShareDownloadModePresigned {
+		disposition := fileName
+		if req.Inline {
+			disposition = ""
+		}
+		return &ShareDownloadResult{
+			RedirectURL: helper.S3PresignedURLWithExpiry(file.Path, define.SharePresignTTL, disposition),
+		}, nil
+	}
+
+	obj, err := helper.S3GetObject(file.Path, req.Range)
+	if err != nil {
+		log.Printf("[ShareBasicDownload] Failed to download from S3: %v, key=%s", err, file.Path)
+		return nil, err
+	}
+
+	contentType := aws.ToString(obj.ContentType)
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	return &ShareDownloadResult{
+		Body:          obj.Body,
+		FileName:      fileName,
+		ContentType:   contentType,
+		ContentLength: aws.ToInt64(obj.ContentLength),
+		ContentRange:  aws.ToString(obj.ContentRange),
+	}, nil
+}
+
+// countDownload takes one download from the share's limit and records it
+func (l *ShareBasicDownloadLogic) countDownload(sb *models.ShareBasic, fileName, userIdentity string) error {
+	result := l.svcCtx.DB.WithContext(l.ctx).Model(&models.ShareBasic{}).
+		Where("identity = ?", sb.Identity).
+		Where("download_limit = 0 OR download_num < download_limit").
+		UpdateColumn("download_num", gorm.Expr("download_num + 1"))
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		recordShareAccess(l.ctx, l.svcCtx, sb, shareEventDownload, userIdentity, "limit_reached")
+		return errors.New("share download limit reached")
+	}
+	recordShareAccess(l.ctx, l.svcCtx, sb, shareEventDownload, userIdentity, "")
+
+	body := fileName + " was downloaded through your share link."
+	if sb.DownloadLimit > 0 && sb.DownloadNum+1 >= sb.DownloadLimit {
+		body += " The link has reached its download limit."
+	}
+	notify(l.ctx, l.svcCtx, sb.UserIdentity, events.ShareLinkActivity, "Share link downloaded", body,
+		map[string]interface{}{
+			"share_identity": sb.Identity,
+			"user_identity":  userIdentity,
+		})
+	return nil
+}
+
+// firstInlineFetch reports whether this is the first inline fetch made with
+// the request's download token. When Redis cannot tell, the fetch is logged.
+func (l *ShareBasicDownloadLogic) firstInlineFetch(req *types.ShareBasicDownloadRequest) bool {
+	sum := sha256.Sum256([]byte(req.Token))
+	key := "share:inline:" + req.Identity + ":" + hex.EncodeToString(sum[:])
+	ttl := time.Duration(define.ShareDownloadTokenTTL) * time.Second
+	first, err := l.svcCtx.RDB.SetNX(l.ctx, key, 1, ttl).Result()
+	if err != nil {
+		log.Printf("[ShareBasicDownload] Failed to check inline fetch for share %s: %v", req.Identity, err)
+		return true
+	}
+	return first
+}
@@ -0,0 +1,37 @@
+package logic
+
+import (
+	"context"
+	"errors"
+
+	"cloud-dist/core/internal/types"
+	"cloud-dist/core/models"
+	"cloud-dist/core/svc"
+)
+
+type ShareBasicRevokeLogic struct {
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewShareBasicRevokeLogic(ctx context.Context, svcCtx *svc.ServiceContext) *ShareBasicRevokeLogic {
+	return &ShareBasicRevokeLogic{
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *ShareBasicRevokeLogic) ShareBasicRevoke(req *types.ShareBasicRevokeRequest, userIdentity, spaceIdentity, spaceRole string) (resp *types.ShareBasicRevokeReply, err error) {
+	// Soft-delete the share; detail and download lookups no longer find it
+	db := l.svcCtx.DB.WithContext(l.ctx).Where("share_basic.identity = ?", req.Identity)
+	result := managedShares(db, userIdentity, spaceIdentity, spaceRole).Delete(&models.ShareBasic{})
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, errors.New("share not found")
+	}
+
+	resp = &types.ShareBasicRevokeReply{}
+	return
+}
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"log"
 
+	"cloud-dist/core/audit"
 	"cloud-dist/core/helper"
 	"cloud-dist/core/internal/types"
 	"cloud-dist/core/models"
@@ -25,9 +26,9 @@ func NewShareBasicSaveLogic(ctx context.Context, svcCtx *svc.ServiceContext) *Sh
 	}
 }
 
-func (l *ShareBasicSaveLogic) ShareBasicSave(req *types.ShareBasicSaveRequest, userIdentity string) (resp *types.ShareBasicSaveReply, err error) {
+func (l *ShareBasicSaveLogic) ShareBasicSave(req *types.ShareBasicSaveRequest, ownerIdentity, billingIdentity string) (resp *types.ShareBasicSaveReply, err error) {
 	// Verify user identity is provided (should be checked in handler, but double-check here)
-	if userIdentity == "" {
+	if ownerIdentity == "" {
 		return nil, errors.New("unauthorized: user identity is required")
 	}
 
@@ -44,7 +45,7 @@ func (l *ShareBasicSaveLogic) ShareBasicSave(req *types.ShareBasicSaveRequest, u
 	// Check if file already exists in user's repository (global deduplication - user level)
 	var existingUR models.UserRepository
 	err = l.svcCtx.DB.WithContext(l.ctx).
-		Where("user_identity = ?", userIdentity).
+		Where("user_identity = ?", ownerIdentity).
 		Where("repository_identity = ?", req.RepositoryIdentity).
 		Where("deleted_at IS NULL").
 		First(&existingUR).Error
@@ -60,7 +61,7 @@ func (l *ShareBasicSaveLogic) ShareBasicSave(req *types.ShareBasicSaveRequest, u
 	ub := new(models.UserBasic)
 	err = l.svcCtx.DB.WithContext(l.ctx).
 		Select("now_volume", "total_volume").
-		Where("identity = ?", userIdentity).First(ub).Error
+		Where("identity = ?", billingIdentity).First(ub).Error
 	if err != nil {
 		return nil, err
 	}
@@ -72,7 +73,7 @@ func (l *ShareBasicSaveLogic) ShareBasicSave(req *types.ShareBasicSaveRequest, u
 	// Create user repository entry
 	ur := &models.UserRepository{
 		Identity:           helper.UUID(),
-		UserIdentity:       userIdentity,
+		UserIdentity:       ownerIdentity,
 		ParentId:           req.ParentId,
 		RepositoryIdentity: req.RepositoryIdentity,
 		Ext:                rp.Ext,
@@ -81,17 +82,20 @@ func (l *ShareBasicSaveLogic) ShareBasicSave(req *types.ShareBasicSaveRequest, u
 	if err = l.svcCtx.DB.WithContext(l.ctx).Create(ur).Error; err != nil {
 		return nil, err
 	}
+	audit.Record(l.ctx, l.svcCtx.DB, audit.Entry{Subject: ownerIdentity, Action: audit.ActionFileCreate,
+		TargetType: audit.TargetFile, Target: ur.Identity, Detail: map[string]interface{}{"name": ur.Name, "source": "share"}})
 
 	// Update user storage capacity
 	log.Printf("[ShareBasicSave] Updating user capacity: user=%s, file size=%d, current used=%d, after update=%d",
-		userIdentity, rp.Size, ub.NowVolume, ub.NowVolume+rp.Size)
+		billingIdentity, rp.Size, ub.NowVolume, ub.NowVolume+rp.Size)
 	if err = l.svcCtx.DB.WithContext(l.ctx).Model(&models.UserBasic{}).
-		Where("identity = ?", userIdentity).
+		Where("identity = ?", billingIdentity).
 		UpdateColumn("now_volume", gorm.Expr("now_volume + ?", rp.Size)).Error; err != nil {
 		log.Printf("[ShareBasicSave] Failed to update capacity: %v", err)
 		return nil, err
 	}
 	log.Printf("[ShareBasicSave] Capacity updated successfully")
+	notifyQuotaThreshold(l.ctx, l.svcCtx, billingIdentity, ub.NowVolume, ub.NowVolume+rp.Size, ub.TotalVolume)
 
 	resp = &types.ShareBasicSaveReply{Identity: ur.Identity}
 	return
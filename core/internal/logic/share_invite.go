@@ -0,0 +1,120 @@
+package logic
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"cloud-dist/core/audit"
+	"cloud-dist/core/events"
+	"cloud-dist/core/helper"
+	"cloud-dist/core/models"
+	"cloud-dist/core/svc"
+
+	"gorm.io/gorm"
+)
+
+// Share invite states
+const (
+	shareInviteStatusPending  = "pending"
+	shareInviteStatusAccepted = "accepted"
+	shareInviteStatusRevoked  = "revoked"
+)
+
+// claimShareInvites turns every pending, unexpired invite sent to the user's
+// email into a FriendShare, so the files show up in their share list.
+// Failures are logged only, so that a bad invite never blocks registration.
+func claimShareInvites(ctx context.Context, svcCtx *svc.ServiceContext, user *models.UserBasic) {
+	var invites []*models.ShareInvite
+	err := svcCtx.DB.WithContext(ctx).
+		Where("email = ? AND status = ? AND expires_at > ?", user.Email, shareInviteStatusPending, time.Now()).
+		Find(&invites).Error
+	if err != nil {
+		log.Printf("[ShareInvite] Failed to load invites for %s: %v", user.Email, err)
+		return
+	}
+	for _, invite := range invites {
+		if _, err = claimShareInvite(ctx, svcCtx, user, invite); err != nil {
+			log.Printf("[ShareInvite] Failed to claim invite %s: %v", invite.Identity, err)
+		}
+	}
+}
+
+// claimShareInvite turns one pending invite into a FriendShare for the user
+// and returns it. It returns nil when the invite was revoked meanwhile.
+func claimShareInvite(ctx context.Context, svcCtx *svc.ServiceContext, user *models.UserBasic, invite *models.ShareInvite) (*models.FriendShare, error) {
+	fs := &models.FriendShare{
+		Identity:               helper.UUID(),
+		FromUserIdentity:       invite.FromUserIdentity,
+		ToUserIdentity:         user.Identity,
+		RepositoryIdentity:     invite.RepositoryIdentity,
+		UserRepositoryIdentity: invite.UserRepositoryIdentity,
+		Message:                invite.Message,
+		Permission:             invite.Permission,
+		InviteIdentity:         invite.Identity,
+	}
+	claimed := false
+	err := svcCtx.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// Guard on status so a concurrent revoke wins
+		result := tx.Model(&models.ShareInvite{}).
+			Where("identity = ? AND status = ?", invite.Identity, shareInviteStatusPending).
+			Updates(map[string]interface{}{
+				"status":                shareInviteStatusAccepted,
+				"to_user_identity":      user.Identity,
+				"friend_share_identity": fs.Identity,
+			})
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+		claimed = true
+		return tx.Create(fs).Error
+	})
+	if err != nil || !claimed {
+		return nil, err
+	}
+	audit.Record(ctx, svcCtx.DB, audit.Entry{Actor: fs.FromUserIdentity, Action: audit.ActionShareCreate,
+		TargetType: audit.TargetShare, Target: fs.Identity, Detail: map[string]interface{}{
+			"file":       fs.UserRepositoryIdentity,
+			"friend":     fs.ToUserIdentity,
+			"permission": fs.Permission,
+			"invite":     invite.Identity,
+		}})
+	notify(ctx, svcCtx, user.Identity, events.FriendShareReceived,
+		"New shared item",
+		displayName(ctx, svcCtx, fs.FromUserIdentity)+" shared an item with you.",
+		map[string]interface{}{
+			"identity":           fs.Identity,
+			"from_user_identity": fs.FromUserIdentity,
+			"message":            fs.Message,
+		})
+	return fs, nil
+}
+
+// deliverShareInvite tells a recipient who already has an account about an
+// invite. Nothing changes on the invite until they accept it, so the sender
+// cannot tell a registered address from an unregistered one. Recipients who
+// blocked the sender are not told. Failures are logged only.
+func deliverShareInvite(ctx context.Context, svcCtx *svc.ServiceContext, invite *models.ShareInvite, recipient *models.UserBasic, senderName, fileName string) {
+	if recipient.Identity == invite.FromUserIdentity {
+		return
+	}
+	var blocked int64
+	err := svcCtx.DB.WithContext(ctx).Model(&models.Friend{}).
+		Where("user_identity = ? AND friend_identity = ? AND status = ?", recipient.Identity, invite.FromUserIdentity, friendStatusBlocked).
+		Count(&blocked).Error
+	if err != nil {
+		log.Printf("[ShareInvite] Failed to check blocks for invite %s: %v", invite.Identity, err)
+		return
+	}
+	if blocked > 0 {
+		return
+	}
+	notify(ctx, svcCtx, recipient.Identity, events.ShareInviteReceived,
+		"New share invite",
+		senderName+" invited you to open "+fileName+".",
+		map[string]interface{}{
+			"identity":           invite.Identity,
+			"from_user_identity": invite.FromUserIdentity,
+			"message":            invite.Message,
+		})
+}
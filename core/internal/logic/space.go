@@ -0,0 +1,57 @@
+package logic
+
+import (
+	"context"
+	"errors"
+
+	"cloud-dist/core/define"
+	"cloud-dist/core/models"
+	"cloud-dist/core/svc"
+
+	"gorm.io/gorm"
+)
+
+// findSpaceMember loads the caller's membership of a space. Non-members get the
+// same error as a missing space so that space identities cannot be probed.
+func findSpaceMember(ctx context.Context, svcCtx *svc.ServiceContext, spaceIdentity, userIdentity string) (*models.SpaceMember, error) {
+	member := new(models.SpaceMember)
+	err := svcCtx.DB.WithContext(ctx).
+		Where("space_identity = ? AND user_identity = ?", spaceIdentity, userIdentity).
+		First(member).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, errors.New("space not found")
+	}
+	if err != nil {
+		return nil, err
+	}
+	return member, nil
+}
+
+// requireSpaceOwner returns an error unless the caller owns the space
+func requireSpaceOwner(ctx context.Context, svcCtx *svc.ServiceContext, spaceIdentity, userIdentity string) error {
+	member, err := findSpaceMember(ctx, svcCtx, spaceIdentity, userIdentity)
+	if err != nil {
+		return err
+	}
+	if member.Role != define.SpaceRoleOwner {
+		return errors.New("only the space owner can manage the space")
+	}
+	return nil
+}
+
+// managedShares limits a share_basic query to the links the caller may manage:
+// their own, plus every link made from the space's files when they own the
+// space selected for the request
+func managedShares(db *gorm.DB, userIdentity, spaceIdentity, spaceRole string) *gorm.DB {
+	if spaceIdentity == "" || spaceRole != define.SpaceRoleOwner {
+		return db.Where("share_basic.user_identity = ?", userIdentity)
+	}
+	files := db.Session(&gorm.Session{NewDB: true}).Model(&models.UserRepository{}).
+		Select("identity").Where("user_identity = ?", spaceIdentity)
+	return db.Where("share_basic.user_identity = ? OR share_basic.user_repository_identity IN (?)", userIdentity, files)
+}
+
+// validMemberRole reports whether role can be granted to a non-owner member
+func validMemberRole(role string) bool {
+	return role == define.SpaceRoleEditor || role == define.SpaceRoleViewer
+}
@@ -0,0 +1,59 @@
+package logic
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"cloud-dist/core/define"
+	"cloud-dist/core/helper"
+	"cloud-dist/core/internal/types"
+	"cloud-dist/core/models"
+	"cloud-dist/core/svc"
+
+	"gorm.io/gorm"
+)
+
+type SpaceCreateLogic struct {
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewSpaceCreateLogic(ctx context.Context, svcCtx *svc.ServiceContext) *SpaceCreateLogic {
+	return &SpaceCreateLogic{
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *SpaceCreateLogic) SpaceCreate(req *types.SpaceCreateRequest, userIdentity string) (resp *types.SpaceCreateReply, err error) {
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return nil, errors.New("space name is required")
+	}
+
+	space := &models.Space{
+		Identity:      helper.UUID(),
+		Name:          name,
+		OwnerIdentity: userIdentity,
+	}
+	err = l.svcCtx.DB.WithContext(l.ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(space).Error; err != nil {
+			return err
+		}
+		return tx.Create(&models.SpaceMember{
+			Identity:      helper.UUID(),
+			SpaceIdentity: space.Identity,
+			UserIdentity:  userIdentity,
+			Role:          define.SpaceRoleOwner,
+		}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	resp = &types.SpaceCreateReply{
+		Identity: space.Identity,
+	}
+	return
+}
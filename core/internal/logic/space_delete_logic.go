@@ -0,0 +1,36 @@
+package logic
+
+import (
+	"context"
+	"log"
+
+	"cloud-dist/core/account"
+	"cloud-dist/core/helper"
+	"cloud-dist/core/internal/types"
+	"cloud-dist/core/svc"
+)
+
+type SpaceDeleteLogic struct {
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewSpaceDeleteLogic(ctx context.Context, svcCtx *svc.ServiceContext) *SpaceDeleteLogic {
+	return &SpaceDeleteLogic{
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// SpaceDelete lets the owner delete a space together with its files, members
+// and every share made from its files
+func (l *SpaceDeleteLogic) SpaceDelete(req *types.SpaceDeleteRequest, userIdentity string) (resp *types.SpaceDeleteReply, err error) {
+	if err = requireSpaceOwner(l.ctx, l.svcCtx, req.SpaceIdentity, userIdentity); err != nil {
+		return nil, err
+	}
+	if err = account.DeleteSpace(l.ctx, l.svcCtx.DB, req.SpaceIdentity, helper.S3Delete); err != nil {
+		log.Printf("[SpaceDelete] Failed to delete space %s: %v", req.SpaceIdentity, err)
+		return nil, err
+	}
+	return &types.SpaceDeleteReply{}, nil
+}
@@ -0,0 +1,65 @@
+package logic
+
+import (
+	"context"
+	"time"
+
+	"cloud-dist/core/define"
+	"cloud-dist/core/internal/types"
+	"cloud-dist/core/svc"
+)
+
+type SpaceInviteListLogic struct {
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewSpaceInviteListLogic(ctx context.Context, svcCtx *svc.ServiceContext) *SpaceInviteListLogic {
+	return &SpaceInviteListLogic{
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// SpaceInviteList lists the caller's pending invitations to join a space
+func (l *SpaceInviteListLogic) SpaceInviteList(req *types.SpaceInviteListRequest, userIdentity string) (resp *types.SpaceInviteListReply, err error) {
+	var results []struct {
+		Identity        string
+		SpaceIdentity   string
+		SpaceName       string
+		InviterIdentity string
+		InviterName     string
+		Role            string
+		ExpiresAt       time.Time
+		CreatedAt       time.Time
+	}
+	err = l.svcCtx.DB.WithContext(l.ctx).Table("space_invite").
+		Select("space_invite.identity, space_invite.space_identity, space.name as space_name, "+
+			"space_invite.inviter_identity, user_basic.name as inviter_name, "+
+			"space_invite.role, space_invite.expires_at, space_invite.created_at").
+		Joins("JOIN space ON space.identity = space_invite.space_identity AND space.deleted_at IS NULL").
+		Joins("LEFT JOIN user_basic ON user_basic.identity = space_invite.inviter_identity").
+		Where("space_invite.user_identity = ? AND space_invite.expires_at > ?", userIdentity, time.Now()).
+		Where("space_invite.deleted_at IS NULL").
+		Order("space_invite.created_at DESC").
+		Scan(&results).Error
+	if err != nil {
+		return nil, err
+	}
+
+	resp = new(types.SpaceInviteListReply)
+	resp.List = make([]*types.SpaceInviteItem, 0, len(results))
+	for _, r := range results {
+		resp.List = append(resp.List, &types.SpaceInviteItem{
+			Identity:        r.Identity,
+			SpaceIdentity:   r.SpaceIdentity,
+			SpaceName:       r.SpaceName,
+			InviterIdentity: r.InviterIdentity,
+			InviterName:     r.InviterName,
+			Role:            r.Role,
+			ExpiresAt:       r.ExpiresAt.Format(define.Datetime),
+			CreatedAt:       r.CreatedAt.Format(define.Datetime),
+		})
+	}
+	return
+}
@@ -0,0 +1,85 @@
+package logic
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"cloud-dist/core/helper"
+	"cloud-dist/core/internal/types"
+	"cloud-dist/core/models"
+	"cloud-dist/core/svc"
+
+	"gorm.io/gorm"
+)
+
+type SpaceInviteRespondLogic struct {
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewSpaceInviteRespondLogic(ctx context.Context, svcCtx *svc.ServiceContext) *SpaceInviteRespondLogic {
+	return &SpaceInviteRespondLogic{
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// SpaceInviteRespond accepts or declines an invitation to join a space. The
+// invite is used up either way.
+func (l *SpaceInviteRespondLogic) SpaceInviteRespond(req *types.SpaceInviteRespondRequest, userIdentity string) (resp *types.SpaceInviteRespondReply, err error) {
+	if req.Action != "accept" && req.Action != "decline" {
+		return nil, errors.New("action must be accept or decline")
+	}
+
+	invite := new(models.SpaceInvite)
+	err = l.svcCtx.DB.WithContext(l.ctx).
+		Where("identity = ? AND user_identity = ? AND expires_at > ?", req.Identity, userIdentity, time.Now()).
+		First(invite).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, errors.New("invite not found")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	err = l.svcCtx.DB.WithContext(l.ctx).Transaction(func(tx *gorm.DB) error {
+		// Guard on the row so that a concurrent response only counts once
+		result := tx.Where("identity = ?", invite.Identity).Delete(&models.SpaceInvite{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errors.New("invite not found")
+		}
+		if req.Action != "accept" {
+			return nil
+		}
+
+		var cnt int64
+		if err := tx.Model(&models.Space{}).Where("identity = ?", invite.SpaceIdentity).Count(&cnt).Error; err != nil {
+			return err
+		}
+		if cnt == 0 {
+			return errors.New("space not found")
+		}
+		if err := tx.Model(&models.SpaceMember{}).
+			Where("space_identity = ? AND user_identity = ?", invite.SpaceIdentity, userIdentity).
+			Count(&cnt).Error; err != nil {
+			return err
+		}
+		if cnt > 0 {
+			return nil
+		}
+		return tx.Create(&models.SpaceMember{
+			Identity:      helper.UUID(),
+			SpaceIdentity: invite.SpaceIdentity,
+			UserIdentity:  userIdentity,
+			Role:          invite.Role,
+		}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &types.SpaceInviteRespondReply{}, nil
+}
@@ -0,0 +1,60 @@
+package logic
+
+import (
+	"context"
+	"time"
+
+	"cloud-dist/core/define"
+	"cloud-dist/core/internal/types"
+	"cloud-dist/core/svc"
+)
+
+type SpaceListLogic struct {
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewSpaceListLogic(ctx context.Context, svcCtx *svc.ServiceContext) *SpaceListLogic {
+	return &SpaceListLogic{
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *SpaceListLogic) SpaceList(req *types.SpaceListRequest, userIdentity string) (resp *types.SpaceListReply, err error) {
+	resp = new(types.SpaceListReply)
+	resp.List = make([]*types.SpaceItem, 0)
+
+	var results []struct {
+		Identity      string
+		Name          string
+		OwnerIdentity string
+		OwnerName     string
+		Role          string
+		CreatedAt     time.Time
+	}
+	err = l.svcCtx.DB.WithContext(l.ctx).Table("space_member").
+		Select("space.identity, space.name, space.owner_identity, owner.name as owner_name, "+
+			"space_member.role, space.created_at").
+		Joins("JOIN space ON space_member.space_identity = space.identity AND space.deleted_at IS NULL").
+		Joins("LEFT JOIN user_basic as owner ON space.owner_identity = owner.identity").
+		Where("space_member.user_identity = ?", userIdentity).
+		Where("space_member.deleted_at IS NULL").
+		Order("space.created_at DESC").
+		Scan(&results).Error
+	if err != nil {
+		return nil, err
+	}
+
+	for _, r := range results {
+		resp.List = append(resp.List, &types.SpaceItem{
+			Identity:      r.Identity,
+			Name:          r.Name,
+			OwnerIdentity: r.OwnerIdentity,
+			OwnerName:     r.OwnerName,
+			Role:          r.Role,
+			CreatedAt:     r.CreatedAt.Format(define.Datetime),
+		})
+	}
+	return
+}
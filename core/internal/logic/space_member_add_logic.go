@@ -0,0 +1,109 @@
+package logic
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"cloud-dist/core/define"
+	"cloud-dist/core/events"
+	"cloud-dist/core/helper"
+	"cloud-dist/core/internal/types"
+	"cloud-dist/core/models"
+	"cloud-dist/core/svc"
+
+	"gorm.io/gorm"
+)
+
+type SpaceMemberAddLogic struct {
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewSpaceMemberAddLogic(ctx context.Context, svcCtx *svc.ServiceContext) *SpaceMemberAddLogic {
+	return &SpaceMemberAddLogic{
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// SpaceMemberAdd invites a user to the space; they join once they accept.
+// The reply is the same whether or not the user exists, is already a member
+// or has blocked the owner, so identities cannot be probed through it.
+func (l *SpaceMemberAddLogic) SpaceMemberAdd(req *types.SpaceMemberAddRequest, userIdentity string) (resp *types.SpaceMemberAddReply, err error) {
+	if err = requireSpaceOwner(l.ctx, l.svcCtx, req.SpaceIdentity, userIdentity); err != nil {
+		return nil, err
+	}
+	if !validMemberRole(req.Role) {
+		return nil, errors.New("role must be editor or viewer")
+	}
+	resp = &types.SpaceMemberAddReply{}
+	if req.UserIdentity == userIdentity {
+		return resp, nil
+	}
+
+	invitee := new(models.UserBasic)
+	err = l.svcCtx.DB.WithContext(l.ctx).Select("identity").Where("identity = ?", req.UserIdentity).First(invitee).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return resp, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	blocked, err := hasBlocked(l.ctx, l.svcCtx, req.UserIdentity, userIdentity)
+	if err != nil {
+		return nil, err
+	}
+	if blocked {
+		return resp, nil
+	}
+
+	var cnt int64
+	if err = l.svcCtx.DB.WithContext(l.ctx).Model(&models.SpaceMember{}).
+		Where("space_identity = ? AND user_identity = ?", req.SpaceIdentity, req.UserIdentity).
+		Count(&cnt).Error; err != nil {
+		return nil, err
+	}
+	if cnt > 0 {
+		return resp, nil
+	}
+
+	// Inviting again refreshes the pending invite instead of piling up a second one
+	expiresAt := time.Now().AddDate(0, 0, define.SpaceInviteExpireDays)
+	result := l.svcCtx.DB.WithContext(l.ctx).Model(&models.SpaceInvite{}).
+		Where("space_identity = ? AND user_identity = ?", req.SpaceIdentity, req.UserIdentity).
+		Updates(map[string]interface{}{"role": req.Role, "inviter_identity": userIdentity, "expires_at": expiresAt})
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected > 0 {
+		return resp, nil
+	}
+
+	invite := &models.SpaceInvite{
+		Identity:        helper.UUID(),
+		SpaceIdentity:   req.SpaceIdentity,
+		InviterIdentity: userIdentity,
+		UserIdentity:    req.UserIdentity,
+		Role:            req.Role,
+		ExpiresAt:       expiresAt,
+	}
+	if err = l.svcCtx.DB.WithContext(l.ctx).Create(invite).Error; err != nil {
+		return nil, err
+	}
+
+	space := new(models.Space)
+	if err = l.svcCtx.DB.WithContext(l.ctx).Select("name").Where("identity = ?", req.SpaceIdentity).First(space).Error; err != nil {
+		log.Printf("[SpaceMemberAdd] Failed to load space %s: %v", req.SpaceIdentity, err)
+	}
+	notify(l.ctx, l.svcCtx, req.UserIdentity, events.SpaceInviteReceived,
+		"Team space invitation",
+		displayName(l.ctx, l.svcCtx, userIdentity)+" invited you to join "+space.Name+".",
+		map[string]interface{}{
+			"identity":       invite.Identity,
+			"space_identity": req.SpaceIdentity,
+			"role":           req.Role,
+		})
+	return resp, nil
+}
@@ -0,0 +1,59 @@
+package logic
+
+import (
+	"context"
+	"time"
+
+	"cloud-dist/core/define"
+	"cloud-dist/core/internal/types"
+	"cloud-dist/core/svc"
+)
+
+type SpaceMemberListLogic struct {
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewSpaceMemberListLogic(ctx context.Context, svcCtx *svc.ServiceContext) *SpaceMemberListLogic {
+	return &SpaceMemberListLogic{
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *SpaceMemberListLogic) SpaceMemberList(req *types.SpaceMemberListRequest, userIdentity string) (resp *types.SpaceMemberListReply, err error) {
+	// Any member can see who else is in the space
+	if _, err = findSpaceMember(l.ctx, l.svcCtx, req.SpaceIdentity, userIdentity); err != nil {
+		return nil, err
+	}
+
+	resp = new(types.SpaceMemberListReply)
+	resp.List = make([]*types.SpaceMemberItem, 0)
+
+	var results []struct {
+		UserIdentity string
+		UserName     string
+		Role         string
+		CreatedAt    time.Time
+	}
+	err = l.svcCtx.DB.WithContext(l.ctx).Table("space_member").
+		Select("space_member.user_identity, user_basic.name as user_name, space_member.role, space_member.created_at").
+		Joins("LEFT JOIN user_basic ON space_member.user_identity = user_basic.identity").
+		Where("space_member.space_identity = ?", req.SpaceIdentity).
+		Where("space_member.deleted_at IS NULL").
+		Order("space_member.created_at ASC").
+		Scan(&results).Error
+	if err != nil {
+		return nil, err
+	}
+
+	for _, r := range results {
+		resp.List = append(resp.List, &types.SpaceMemberItem{
+			UserIdentity: r.UserIdentity,
+			UserName:     r.UserName,
+			Role:         r.Role,
+			CreatedAt:    r.CreatedAt.Format(define.Datetime),
+		})
+	}
+	return
+}
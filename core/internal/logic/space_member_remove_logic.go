@@ -0,0 +1,62 @@
+package logic
+
+import (
+	"context"
+	"errors"
+
+	"cloud-dist/core/define"
+	"cloud-dist/core/internal/types"
+	"cloud-dist/core/models"
+	"cloud-dist/core/svc"
+
+	"gorm.io/gorm"
+)
+
+type SpaceMemberRemoveLogic struct {
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewSpaceMemberRemoveLogic(ctx context.Context, svcCtx *svc.ServiceContext) *SpaceMemberRemoveLogic {
+	return &SpaceMemberRemoveLogic{
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// SpaceMemberRemove lets the owner remove a member, or a member leave the space
+func (l *SpaceMemberRemoveLogic) SpaceMemberRemove(req *types.SpaceMemberRemoveRequest, userIdentity string) (resp *types.SpaceMemberRemoveReply, err error) {
+	caller, err := findSpaceMember(l.ctx, l.svcCtx, req.SpaceIdentity, userIdentity)
+	if err != nil {
+		return nil, err
+	}
+	if req.UserIdentity != userIdentity && caller.Role != define.SpaceRoleOwner {
+		return nil, errors.New("only the space owner can manage members")
+	}
+	if req.UserIdentity == userIdentity && caller.Role == define.SpaceRoleOwner {
+		return nil, errors.New("the owner cannot leave the space")
+	}
+
+	// Links and friend shares the member made from space files go with them
+	err = l.svcCtx.DB.WithContext(l.ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("space_identity = ? AND user_identity = ?", req.SpaceIdentity, req.UserIdentity).
+			Delete(&models.SpaceMember{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errors.New("member not found")
+		}
+		files := tx.Model(&models.UserRepository{}).Select("identity").Where("user_identity = ?", req.SpaceIdentity)
+		if err := tx.Where("user_identity = ? AND user_repository_identity IN (?)", req.UserIdentity, files).
+			Delete(&models.ShareBasic{}).Error; err != nil {
+			return err
+		}
+		return tx.Where("space_identity = ? AND from_user_identity = ?", req.SpaceIdentity, req.UserIdentity).
+			Delete(&models.FriendShare{}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &types.SpaceMemberRemoveReply{}, nil
+}
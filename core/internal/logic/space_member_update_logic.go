@@ -0,0 +1,55 @@
+package logic
+
+import (
+	"context"
+	"errors"
+
+	"cloud-dist/core/define"
+	"cloud-dist/core/internal/types"
+	"cloud-dist/core/models"
+	"cloud-dist/core/svc"
+)
+
+type SpaceMemberUpdateLogic struct {
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewSpaceMemberUpdateLogic(ctx context.Context, svcCtx *svc.ServiceContext) *SpaceMemberUpdateLogic {
+	return &SpaceMemberUpdateLogic{
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *SpaceMemberUpdateLogic) SpaceMemberUpdate(req *types.SpaceMemberUpdateRequest, userIdentity string) (resp *types.SpaceMemberUpdateReply, err error) {
+	if err = requireSpaceOwner(l.ctx, l.svcCtx, req.SpaceIdentity, userIdentity); err != nil {
+		return nil, err
+	}
+	if !validMemberRole(req.Role) {
+		return nil, errors.New("role must be editor or viewer")
+	}
+
+	// The owner's role is fixed, since the space is billed to them
+	result := l.svcCtx.DB.WithContext(l.ctx).Model(&models.SpaceMember{}).
+		Where("space_identity = ? AND user_identity = ? AND role != ?", req.SpaceIdentity, req.UserIdentity, define.SpaceRoleOwner).
+		Update("role", req.Role)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		var cnt int64
+		if err = l.svcCtx.DB.WithContext(l.ctx).Model(&models.SpaceMember{}).
+			Where("space_identity = ? AND user_identity = ?", req.SpaceIdentity, req.UserIdentity).
+			Count(&cnt).Error; err != nil {
+			return nil, err
+		}
+		if cnt == 0 {
+			return nil, errors.New("member not found")
+		}
+		if req.UserIdentity == userIdentity {
+			return nil, errors.New("the owner's role cannot be changed")
+		}
+	}
+	return &types.SpaceMemberUpdateReply{}, nil
+}
@@ -90,6 +90,8 @@ func (l *StoragePurchaseSyncLogic) StoragePurchaseSync(req *types.StoragePurchas
 		resp.StorageAmount = order.StorageAmount
 		resp.Message = "Payment confirmed, storage capacity increased"
 		log.Printf("[StoragePurchaseSync] Order %s synced and marked as paid", order.Identity)
+		recordPayment(l.ctx, l.svcCtx, order)
+		notifyStorageOrderPaid(l.ctx, l.svcCtx, order)
 	} else if stripeSession.PaymentStatus == stripe.CheckoutSessionPaymentStatusUnpaid {
 		resp.Status = "pending"
 		resp.Message = "Payment is still pending"
@@ -98,6 +100,8 @@ func (l *StoragePurchaseSyncLogic) StoragePurchaseSync(req *types.StoragePurchas
 		order.Status = "failed"
 		if err = l.svcCtx.DB.WithContext(l.ctx).Save(order).Error; err != nil {
 			log.Printf("[StoragePurchaseSync] Failed to update order status: %v", err)
+		} else {
+			recordPayment(l.ctx, l.svcCtx, order)
 		}
 		resp.Status = "failed"
 		resp.Message = "Payment failed or was cancelled"
@@ -7,7 +7,9 @@ import (
 	"fmt"
 	"log"
 
+	"cloud-dist/core/audit"
 	"cloud-dist/core/define"
+	"cloud-dist/core/events"
 	"cloud-dist/core/svc"
 	"cloud-dist/core/models"
 
@@ -149,6 +151,8 @@ func (l *StoragePurchaseWebhookLogic) handleCheckoutSessionCompleted(event strip
 	}
 
 	log.Printf("[StoragePurchaseWebhook] Order %s marked as paid, user storage updated", order.Identity)
+	recordPayment(l.ctx, l.svcCtx, order)
+	notifyStorageOrderPaid(l.ctx, l.svcCtx, order)
 	return nil
 }
 
@@ -234,6 +238,8 @@ func (l *StoragePurchaseWebhookLogic) handlePaymentIntentSucceeded(event stripe.
 		}
 
 		log.Printf("[StoragePurchaseWebhook] Order %s marked as paid, user storage updated", order.Identity)
+		recordPayment(l.ctx, l.svcCtx, order)
+		notifyStorageOrderPaid(l.ctx, l.svcCtx, order)
 	} else if order.Status == "paid" {
 		log.Printf("[StoragePurchaseWebhook] Order %s is already paid, skipping", order.Identity)
 	}
@@ -268,6 +274,14 @@ func (l *StoragePurchaseWebhookLogic) handlePaymentIntentFailed(event stripe.Eve
 			return err
 		}
 		log.Printf("[StoragePurchaseWebhook] Order %s marked as failed", order.Identity)
+		recordPayment(l.ctx, l.svcCtx, order)
+		notify(l.ctx, l.svcCtx, order.UserIdentity, events.StorageOrderFailed,
+			"Payment failed",
+			"Your storage purchase could not be completed. You have not been charged.",
+			map[string]interface{}{
+				"identity":       order.Identity,
+				"storage_amount": order.StorageAmount,
+			})
 	}
 
 	return nil
@@ -285,3 +299,18 @@ func (l *StoragePurchaseWebhookLogic) updateUserStorage(userIdentity string, add
 	log.Printf("[StoragePurchaseWebhook] User %s storage increased by %d bytes", userIdentity, additionalStorage)
 	return nil
 }
+
+// recordPayment adds a settled storage order to its buyer's audit log
+func recordPayment(ctx context.Context, svcCtx *svc.ServiceContext, order *models.StorageOrder) {
+	e := audit.Entry{Subject: order.UserIdentity, Action: audit.ActionPayment, TargetType: audit.TargetOrder,
+		Target: order.Identity, Detail: map[string]interface{}{
+			"status":         order.Status,
+			"storage_amount": order.StorageAmount,
+			"price_amount":   order.PriceAmount,
+			"currency":       order.Currency,
+		}}
+	if order.Status != "paid" {
+		e.Reason = order.Status
+	}
+	audit.Record(ctx, svcCtx.DB, e)
+}
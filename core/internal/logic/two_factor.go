@@ -0,0 +1,150 @@
+package logic
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"cloud-dist/core/helper"
+	"cloud-dist/core/internal/types"
+	"cloud-dist/core/models"
+	"cloud-dist/core/svc"
+
+	"gorm.io/gorm"
+)
+
+const (
+	totpIssuer                = "CloudDist"
+	recoveryCodeCount         = 10
+	loginChallengeTTL         = 5 * time.Minute
+	loginChallengeMaxAttempts = 5
+)
+
+var (
+	errTwoFactorRequired  = errors.New("two-factor code is required")
+	errTwoFactorIncorrect = errors.New("two-factor code is incorrect")
+)
+
+func loginChallengeKey(token string) string {
+	return "login_challenge:" + token
+}
+
+func loginChallengeAttemptsKey(token string) string {
+	return "login_challenge_attempts:" + token
+}
+
+// newLoginChallenge stores a short-lived token that proves the password step
+// passed. It is exchanged for real tokens once the second factor checks out.
+func newLoginChallenge(ctx context.Context, svcCtx *svc.ServiceContext, user *models.UserBasic) (*types.LoginReply, error) {
+	challenge := helper.UUID()
+	if err := svcCtx.RDB.Set(ctx, loginChallengeKey(challenge), user.Identity, loginChallengeTTL).Err(); err != nil {
+		return nil, err
+	}
+	var passkeys int64
+	svcCtx.DB.WithContext(ctx).Model(&models.WebAuthnCredential{}).Where("user_identity = ?", user.Identity).Count(&passkeys)
+	return &types.LoginReply{TwoFactorRequired: true, ChallengeToken: challenge, PasskeyAvailable: passkeys > 0}, nil
+}
+
+// verifySecondFactor accepts a current TOTP code or an unused recovery code.
+// Both are single use: a TOTP time step is recorded, a recovery code is burned.
+func verifySecondFactor(ctx context.Context, svcCtx *svc.ServiceContext, user *models.UserBasic, code string) error {
+	code = strings.TrimSpace(code)
+	if code == "" {
+		return errTwoFactorRequired
+	}
+
+	if counter, ok := helper.TOTPVerify(user.TOTPSecret, code, time.Now()); ok {
+		// The conditional update also stops two concurrent logins from
+		// sharing one code
+		result := svcCtx.DB.WithContext(ctx).Model(&models.UserBasic{}).
+			Where("identity = ? AND totp_last_counter < ?", user.Identity, counter).
+			Update("totp_last_counter", counter)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errTwoFactorIncorrect
+		}
+		return nil
+	}
+
+	result := svcCtx.DB.WithContext(ctx).Model(&models.UserRecoveryCode{}).
+		Where("user_identity = ? AND code_hash = ? AND used_at IS NULL", user.Identity, helper.HashRecoveryCode(code)).
+		Update("used_at", time.Now())
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errTwoFactorIncorrect
+	}
+	return nil
+}
+
+// requireSecondFactor is for sensitive account changes: it only asks for a
+// code when the user has 2FA enabled
+func requireSecondFactor(ctx context.Context, svcCtx *svc.ServiceContext, user *models.UserBasic, code string) error {
+	if !user.TOTPEnabled {
+		return nil
+	}
+	return verifySecondFactor(ctx, svcCtx, user, code)
+}
+
+// reauthenticate confirms the signed-in user before a change that cannot be
+// taken back. A password works when the account has one; any account can use
+// a code mailed to its address instead, so SSO and passkey-only accounts are
+// not locked out. The second factor is required on top when enabled.
+func reauthenticate(ctx context.Context, svcCtx *svc.ServiceContext, user *models.UserBasic, password, emailCode, twoFactorCode string) error {
+	switch {
+	case password != "":
+		if !helper.CheckPasswordHash(password, user.Password) {
+			return errors.New("password is incorrect")
+		}
+	case emailCode != "":
+		if err := checkVerificationCode(ctx, svcCtx, codePurposeReauth, user.Email, emailCode); err != nil {
+			return err
+		}
+	default:
+		return errors.New("enter your password or the code sent to your email")
+	}
+	if err := requireSecondFactor(ctx, svcCtx, user, twoFactorCode); err != nil {
+		return err
+	}
+	if password == "" {
+		return consumeVerificationCode(ctx, svcCtx, codePurposeReauth, user.Email)
+	}
+	return nil
+}
+
+// replaceRecoveryCodes drops the user's old recovery codes and returns a new
+// set in plain text. Only hashes are stored.
+func replaceRecoveryCodes(tx *gorm.DB, userIdentity string) ([]string, error) {
+	codes, err := helper.RecoveryCodes(recoveryCodeCount)
+	if err != nil {
+		return nil, err
+	}
+	if err = tx.Where("user_identity = ?", userIdentity).Delete(&models.UserRecoveryCode{}).Error; err != nil {
+		return nil, err
+	}
+	rows := make([]*models.UserRecoveryCode, 0, len(codes))
+	for _, c := range codes {
+		rows = append(rows, &models.UserRecoveryCode{UserIdentity: userIdentity, CodeHash: helper.HashRecoveryCode(c)})
+	}
+	if err = tx.Create(&rows).Error; err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// findUserByIdentity loads the caller's account for two-factor changes
+func findUserByIdentity(ctx context.Context, svcCtx *svc.ServiceContext, userIdentity string) (*models.UserBasic, error) {
+	user := new(models.UserBasic)
+	err := svcCtx.DB.WithContext(ctx).Where("identity = ?", userIdentity).First(user).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, errors.New("user not found")
+	}
+	if err != nil {
+		return nil, err
+	}
+	return user, nil
+}
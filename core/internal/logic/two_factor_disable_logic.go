@@ -0,0 +1,59 @@
+package logic
+
+import (
+	"context"
+	"errors"
+
+	"cloud-dist/core/audit"
+	"cloud-dist/core/helper"
+	"cloud-dist/core/internal/types"
+	"cloud-dist/core/models"
+	"cloud-dist/core/svc"
+
+	"gorm.io/gorm"
+)
+
+type TwoFactorDisableLogic struct {
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewTwoFactorDisableLogic(ctx context.Context, svcCtx *svc.ServiceContext) *TwoFactorDisableLogic {
+	return &TwoFactorDisableLogic{
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// TwoFactorDisable turns 2FA off. It needs both the password and a second
+// factor, so a stolen session alone cannot remove it.
+func (l *TwoFactorDisableLogic) TwoFactorDisable(req *types.TwoFactorDisableRequest, userIdentity string) (resp *types.TwoFactorDisableReply, err error) {
+	user, err := findUserByIdentity(l.ctx, l.svcCtx, userIdentity)
+	if err != nil {
+		return nil, err
+	}
+	if !user.TOTPEnabled {
+		return nil, errors.New("two-factor authentication is not enabled")
+	}
+	if !helper.CheckPasswordHash(req.Password, user.Password) {
+		return nil, errors.New("password incorrect")
+	}
+	if err = verifySecondFactor(l.ctx, l.svcCtx, user, req.Code); err != nil {
+		return nil, err
+	}
+
+	err = l.svcCtx.DB.WithContext(l.ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.UserBasic{}).
+			Where("identity = ?", userIdentity).
+			Updates(map[string]interface{}{"totp_enabled": false, "totp_secret": "", "totp_last_counter": 0}).Error; err != nil {
+			return err
+		}
+		return tx.Where("user_identity = ?", userIdentity).Delete(&models.UserRecoveryCode{}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	audit.Record(l.ctx, l.svcCtx.DB, audit.Entry{Actor: userIdentity, Action: audit.ActionTwoFactorDisable,
+		TargetType: audit.TargetUser, Target: userIdentity})
+	return &types.TwoFactorDisableReply{}, nil
+}
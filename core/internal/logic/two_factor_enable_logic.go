@@ -0,0 +1,65 @@
+package logic
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"cloud-dist/core/audit"
+	"cloud-dist/core/helper"
+	"cloud-dist/core/internal/types"
+	"cloud-dist/core/models"
+	"cloud-dist/core/svc"
+
+	"gorm.io/gorm"
+)
+
+type TwoFactorEnableLogic struct {
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewTwoFactorEnableLogic(ctx context.Context, svcCtx *svc.ServiceContext) *TwoFactorEnableLogic {
+	return &TwoFactorEnableLogic{
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *TwoFactorEnableLogic) TwoFactorEnable(req *types.TwoFactorEnableRequest, userIdentity string) (resp *types.TwoFactorEnableReply, err error) {
+	user, err := findUserByIdentity(l.ctx, l.svcCtx, userIdentity)
+	if err != nil {
+		return nil, err
+	}
+	if user.TOTPEnabled {
+		return nil, errors.New("two-factor authentication is already enabled")
+	}
+	if user.TOTPSecret == "" {
+		return nil, errors.New("start two-factor setup first")
+	}
+	counter, ok := helper.TOTPVerify(user.TOTPSecret, req.Code, time.Now())
+	if !ok {
+		return nil, errTwoFactorIncorrect
+	}
+
+	resp = new(types.TwoFactorEnableReply)
+	err = l.svcCtx.DB.WithContext(l.ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.UserBasic{}).
+			Where("identity = ?", userIdentity).
+			Updates(map[string]interface{}{"totp_enabled": true, "totp_last_counter": counter}).Error; err != nil {
+			return err
+		}
+		codes, err := replaceRecoveryCodes(tx, userIdentity)
+		if err != nil {
+			return err
+		}
+		resp.RecoveryCodes = codes
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	audit.Record(l.ctx, l.svcCtx.DB, audit.Entry{Actor: userIdentity, Action: audit.ActionTwoFactorEnable,
+		TargetType: audit.TargetUser, Target: userIdentity})
+	return resp, nil
+}
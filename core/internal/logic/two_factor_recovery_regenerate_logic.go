@@ -0,0 +1,51 @@
+package logic
+
+import (
+	"context"
+	"errors"
+
+	"cloud-dist/core/audit"
+	"cloud-dist/core/internal/types"
+	"cloud-dist/core/svc"
+
+	"gorm.io/gorm"
+)
+
+type TwoFactorRecoveryRegenerateLogic struct {
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewTwoFactorRecoveryRegenerateLogic(ctx context.Context, svcCtx *svc.ServiceContext) *TwoFactorRecoveryRegenerateLogic {
+	return &TwoFactorRecoveryRegenerateLogic{
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// TwoFactorRecoveryRegenerate replaces all recovery codes, used or not
+func (l *TwoFactorRecoveryRegenerateLogic) TwoFactorRecoveryRegenerate(req *types.TwoFactorRecoveryRegenerateRequest, userIdentity string) (resp *types.TwoFactorRecoveryRegenerateReply, err error) {
+	user, err := findUserByIdentity(l.ctx, l.svcCtx, userIdentity)
+	if err != nil {
+		return nil, err
+	}
+	if !user.TOTPEnabled {
+		return nil, errors.New("two-factor authentication is not enabled")
+	}
+	if err = verifySecondFactor(l.ctx, l.svcCtx, user, req.Code); err != nil {
+		return nil, err
+	}
+
+	resp = new(types.TwoFactorRecoveryRegenerateReply)
+	err = l.svcCtx.DB.WithContext(l.ctx).Transaction(func(tx *gorm.DB) error {
+		codes, err := replaceRecoveryCodes(tx, userIdentity)
+		resp.RecoveryCodes = codes
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	audit.Record(l.ctx, l.svcCtx.DB, audit.Entry{Actor: userIdentity, Action: audit.ActionRecoveryCodesRenew,
+		TargetType: audit.TargetUser, Target: userIdentity})
+	return resp, nil
+}
@@ -0,0 +1,55 @@
+package logic
+
+import (
+	"context"
+	"errors"
+
+	"cloud-dist/core/helper"
+	"cloud-dist/core/internal/types"
+	"cloud-dist/core/models"
+	"cloud-dist/core/svc"
+)
+
+type TwoFactorSetupLogic struct {
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewTwoFactorSetupLogic(ctx context.Context, svcCtx *svc.ServiceContext) *TwoFactorSetupLogic {
+	return &TwoFactorSetupLogic{
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// TwoFactorSetup starts enrollment with a fresh secret. 2FA stays off until
+// the first code is confirmed through TwoFactorEnable.
+func (l *TwoFactorSetupLogic) TwoFactorSetup(req *types.TwoFactorSetupRequest, userIdentity string) (resp *types.TwoFactorSetupReply, err error) {
+	user, err := findUserByIdentity(l.ctx, l.svcCtx, userIdentity)
+	if err != nil {
+		return nil, err
+	}
+	if user.TOTPEnabled {
+		return nil, errors.New("two-factor authentication is already enabled")
+	}
+
+	secret, err := helper.TOTPGenerateSecret()
+	if err != nil {
+		return nil, err
+	}
+	err = l.svcCtx.DB.WithContext(l.ctx).Model(&models.UserBasic{}).
+		Where("identity = ?", userIdentity).
+		Updates(map[string]interface{}{"totp_secret": secret, "totp_last_counter": 0}).Error
+	if err != nil {
+		return nil, err
+	}
+
+	account := user.Email
+	if account == "" {
+		account = user.Name
+	}
+	return &types.TwoFactorSetupReply{
+		Secret:          secret,
+		ProvisioningURI: helper.TOTPProvisioningURI(totpIssuer, account, secret),
+	}, nil
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 
+	"cloud-dist/core/define"
 	"cloud-dist/core/svc"
 	"cloud-dist/core/internal/types"
 	"cloud-dist/core/models"
@@ -37,5 +38,11 @@ func (l *UserDetailLogic) UserDetail(req *types.UserDetailRequest) (resp *types.
 	resp.Email = ub.Email
 	resp.NowVolume = ub.NowVolume
 	resp.TotalVolume = ub.TotalVolume
+	resp.DiscoverableByName = ub.DiscoverableByName
+	resp.DiscoverableByEmail = ub.DiscoverableByEmail
+	resp.TwoFactorEnabled = ub.TOTPEnabled
+	if ub.DeleteAfter != nil {
+		resp.DeleteAfter = ub.DeleteAfter.Format(define.Datetime)
+	}
 	return
 }
@@ -0,0 +1,99 @@
+package logic
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"cloud-dist/core/internal/types"
+	"cloud-dist/core/models"
+	"cloud-dist/core/svc"
+
+	"gorm.io/gorm"
+)
+
+// errUserNotFound is returned for missing and undiscoverable accounts alike,
+// so a caller cannot probe whether an email or username is registered.
+var errUserNotFound = errors.New("user not found")
+
+// findDiscoverableUser resolves an email, username or identity to a user who
+// allows being found that way. Emails only match exactly.
+func findDiscoverableUser(ctx context.Context, svcCtx *svc.ServiceContext, query string) (*models.UserBasic, error) {
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return nil, errUserNotFound
+	}
+
+	db := svcCtx.DB.WithContext(ctx)
+	if strings.Contains(query, "@") {
+		db = db.Where("email = ? AND discoverable_by_email = ?", query, true)
+	} else {
+		// Identities come from search results and suggestions, so they are
+		// accepted for anyone who is discoverable at all
+		db = db.Where("(name = ? AND discoverable_by_name = ?) OR "+
+			"(identity = ? AND (discoverable_by_name = ? OR discoverable_by_email = ?))",
+			query, true, query, true, true)
+	}
+
+	ub := new(models.UserBasic)
+	err := db.First(ub).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, errUserNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return ub, nil
+}
+
+// fillDirectoryRelations marks which listed users are already friends of the
+// caller or have a friend request pending with them
+func fillDirectoryRelations(ctx context.Context, svcCtx *svc.ServiceContext, userIdentity string, items []*types.UserDirectoryItem) error {
+	if len(items) == 0 {
+		return nil
+	}
+	identities := make([]string, 0, len(items))
+	for _, item := range items {
+		identities = append(identities, item.Identity)
+	}
+
+	var friends []string
+	err := svcCtx.DB.WithContext(ctx).Model(&models.Friend{}).
+		Where("user_identity = ? AND friend_identity IN ? AND status = ?", userIdentity, identities, friendStatusActive).
+		Pluck("friend_identity", &friends).Error
+	if err != nil {
+		return err
+	}
+
+	// Requests parked by a block look pending to their sender, as elsewhere
+	var requests []models.FriendRequest
+	err = svcCtx.DB.WithContext(ctx).
+		Where("((from_user_identity = ? AND to_user_identity IN ? AND status IN ?) OR "+
+			"(to_user_identity = ? AND from_user_identity IN ? AND status = ?))",
+			userIdentity, identities, []string{friendRequestStatusPending, friendRequestStatusBlocked},
+			userIdentity, identities, friendRequestStatusPending).
+		Find(&requests).Error
+	if err != nil {
+		return err
+	}
+
+	isFriend := make(map[string]bool, len(friends))
+	for _, f := range friends {
+		isFriend[f] = true
+	}
+	pending := make(map[string]bool, len(requests))
+	for _, r := range requests {
+		pending[r.FromUserIdentity] = true
+		pending[r.ToUserIdentity] = true
+	}
+	for _, item := range items {
+		item.IsFriend = isFriend[item.Identity]
+		item.RequestPending = pending[item.Identity]
+	}
+	return nil
+}
+
+// escapeLike escapes LIKE wildcards so user input only matches literally
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(s)
+}
@@ -0,0 +1,93 @@
+package logic
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/url"
+	"time"
+
+	"cloud-dist/core/audit"
+	"cloud-dist/core/define"
+	"cloud-dist/core/helper"
+	"cloud-dist/core/internal/types"
+	"cloud-dist/core/models"
+	"cloud-dist/core/svc"
+
+	"gorm.io/gorm"
+)
+
+type UserEmailChangeConfirmLogic struct {
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewUserEmailChangeConfirmLogic(ctx context.Context, svcCtx *svc.ServiceContext) *UserEmailChangeConfirmLogic {
+	return &UserEmailChangeConfirmLogic{
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// UserEmailChangeConfirm commits a pending email change once the code sent to
+// the new address checks out, and mails an undo link to the old address
+func (l *UserEmailChangeConfirmLogic) UserEmailChangeConfirm(req *types.UserEmailChangeConfirmRequest, userIdentity string) (resp *types.UserEmailChangeConfirmReply, err error) {
+	newEmail, err := l.svcCtx.RDB.Get(l.ctx, emailChangeKey(userIdentity)).Result()
+	if err != nil {
+		return nil, errors.New("no email change is pending, please start again")
+	}
+	if err = checkVerificationCode(l.ctx, l.svcCtx, codePurposeEmailChange, newEmail, req.Code); err != nil {
+		return nil, err
+	}
+	user, err := findUserByIdentity(l.ctx, l.svcCtx, userIdentity)
+	if err != nil {
+		return nil, err
+	}
+	// The address may have been taken while the code was in flight
+	inUse, err := emailInUse(l.ctx, l.svcCtx, newEmail, userIdentity)
+	if err != nil {
+		return nil, err
+	}
+	if inUse {
+		return nil, errEmailInUse
+	}
+	if err = consumeVerificationCode(l.ctx, l.svcCtx, codePurposeEmailChange, newEmail); err != nil {
+		return nil, err
+	}
+	l.svcCtx.RDB.Del(l.ctx, emailChangeKey(userIdentity))
+
+	token, err := helper.NewLinkToken()
+	if err != nil {
+		return nil, err
+	}
+	change := &models.EmailChange{
+		Identity:      helper.UUID(),
+		UserIdentity:  userIdentity,
+		OldEmail:      user.Email,
+		NewEmail:      newEmail,
+		UndoTokenHash: helper.HashLinkToken(token),
+		UndoExpiresAt: time.Now().Add(emailChangeUndoTTL),
+		IP:            helper.ClientMetaFromContext(l.ctx).IP,
+	}
+	err = l.svcCtx.DB.WithContext(l.ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.UserBasic{}).Where("identity = ?", userIdentity).
+			Update("email", newEmail).Error; err != nil {
+			return err
+		}
+		return tx.Create(change).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("[UserEmailChange] User %s changed email from %s to %s", userIdentity, user.Email, newEmail)
+	audit.Record(l.ctx, l.svcCtx.DB, audit.Entry{Actor: userIdentity, Action: audit.ActionEmailChange,
+		TargetType: audit.TargetUser, Target: userIdentity, Detail: map[string]interface{}{"old": user.Email, "new": newEmail}})
+
+	if user.Email != "" {
+		link := define.FrontendBaseURL + "/email/change/undo?token=" + url.QueryEscape(token)
+		if err := helper.MailSendEmailChanged(user.Email, newEmail, link, change.UndoExpiresAt); err != nil {
+			log.Printf("[UserEmailChange] Send undo link failed: %v", err)
+		}
+	}
+	return &types.UserEmailChangeConfirmReply{Email: newEmail}, nil
+}
@@ -0,0 +1,77 @@
+package logic
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/mail"
+	"strings"
+	"time"
+
+	"cloud-dist/core/define"
+	"cloud-dist/core/helper"
+	"cloud-dist/core/internal/types"
+	"cloud-dist/core/svc"
+)
+
+type UserEmailChangeLogic struct {
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewUserEmailChangeLogic(ctx context.Context, svcCtx *svc.ServiceContext) *UserEmailChangeLogic {
+	return &UserEmailChangeLogic{
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// UserEmailChange starts an email change: after re-authentication, a code goes to the new address and a notice to the old one.
+// Nothing changes until the code is confirmed.
+func (l *UserEmailChangeLogic) UserEmailChange(req *types.UserEmailChangeRequest, userIdentity string) (resp *types.UserEmailChangeReply, err error) {
+	newEmail := strings.TrimSpace(req.NewEmail)
+	if addr, err := mail.ParseAddress(newEmail); err != nil || addr.Address != newEmail {
+		return nil, errors.New("new email is not a valid address")
+	}
+
+	user, err := findUserByIdentity(l.ctx, l.svcCtx, userIdentity)
+	if err != nil {
+		return nil, err
+	}
+	if strings.EqualFold(user.Email, newEmail) {
+		return nil, errors.New("new email must be different from the current one")
+	}
+	if err = reauthenticate(l.ctx, l.svcCtx, user, req.Password, req.EmailCode, req.TwoFactorCode); err != nil {
+		return nil, err
+	}
+
+	inUse, err := emailInUse(l.ctx, l.svcCtx, newEmail, user.Identity)
+	if err != nil {
+		return nil, err
+	}
+	if inUse {
+		return nil, errEmailInUse
+	}
+
+	if err = throttleCodeSend(l.ctx, l.svcCtx, codePurposeEmailChange, newEmail); err != nil {
+		return nil, err
+	}
+	ttl := time.Second * time.Duration(define.CodeExpire)
+	if err = l.svcCtx.RDB.Set(l.ctx, emailChangeKey(user.Identity), newEmail, ttl).Err(); err != nil {
+		return nil, err
+	}
+	if err = sendVerificationCode(l.ctx, l.svcCtx, codePurposeEmailChange, newEmail); err != nil {
+		l.svcCtx.RDB.Del(l.ctx, emailChangeKey(user.Identity))
+		return nil, err
+	}
+
+	if user.Email != "" {
+		meta := helper.ClientMetaFromContext(l.ctx)
+		if err := helper.MailSendNotification(user.Email, "Email change requested",
+			"Someone signed in to your account from "+meta.IP+" asked to change its email to "+newEmail+
+				". If this was not you, change your password now."); err != nil {
+			log.Printf("[UserEmailChange] Send notice to old address failed: %v", err)
+		}
+	}
+	return &types.UserEmailChangeReply{}, nil
+}
@@ -0,0 +1,85 @@
+package logic
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strings"
+	"time"
+
+	"cloud-dist/core/audit"
+	"cloud-dist/core/helper"
+	"cloud-dist/core/internal/types"
+	"cloud-dist/core/models"
+	"cloud-dist/core/svc"
+
+	"gorm.io/gorm"
+)
+
+type UserEmailChangeUndoLogic struct {
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewUserEmailChangeUndoLogic(ctx context.Context, svcCtx *svc.ServiceContext) *UserEmailChangeUndoLogic {
+	return &UserEmailChangeUndoLogic{
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// UserEmailChangeUndo restores the old address from the emailed link. It
+// needs no login, since whoever changed the email may hold the account; all
+// sessions and passkeys are ended so the owner can take it back with a
+// password reset.
+func (l *UserEmailChangeUndoLogic) UserEmailChangeUndo(req *types.UserEmailChangeUndoRequest) (resp *types.UserEmailChangeUndoReply, err error) {
+	token := strings.TrimSpace(req.Token)
+	if token == "" {
+		return nil, errors.New("undo token is required")
+	}
+	change := new(models.EmailChange)
+	err = l.svcCtx.DB.WithContext(l.ctx).
+		Where("undo_token_hash = ? AND undone_at IS NULL AND undo_expires_at > ?", helper.HashLinkToken(token), time.Now()).
+		First(change).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, errors.New("undo link is invalid or has expired")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	inUse, err := emailInUse(l.ctx, l.svcCtx, change.OldEmail, change.UserIdentity)
+	if err != nil {
+		return nil, err
+	}
+	if inUse {
+		return nil, errEmailInUse
+	}
+
+	now := time.Now()
+	err = l.svcCtx.DB.WithContext(l.ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(change).Where("undone_at IS NULL").Update("undone_at", now)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errors.New("undo link is invalid or has expired")
+		}
+		if err := tx.Model(&models.UserBasic{}).Where("identity = ?", change.UserIdentity).
+			Update("email", change.OldEmail).Error; err != nil {
+			return err
+		}
+		return deletePasskeys(tx, change.UserIdentity)
+	})
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("[UserEmailChange] User %s restored email %s", change.UserIdentity, change.OldEmail)
+	audit.Record(l.ctx, l.svcCtx.DB, audit.Entry{Actor: change.UserIdentity, Action: audit.ActionEmailRestore,
+		TargetType: audit.TargetUser, Target: change.UserIdentity, Detail: map[string]interface{}{"old": change.NewEmail, "new": change.OldEmail}})
+
+	if err = logoutEverywhere(l.ctx, l.svcCtx, change.UserIdentity, sessionRevokeEmailUndo); err != nil {
+		return nil, err
+	}
+	return &types.UserEmailChangeUndoReply{Email: change.OldEmail}, nil
+}
@@ -41,7 +41,8 @@ func (l *UserFileListLogic) UserFileList(req *types.UserFileListRequest, userIde
 	var parentID int64
 	if req.Identity != "" {
 		ur := new(models.UserRepository)
-		err = l.svcCtx.DB.WithContext(l.ctx).Select("id").Where("identity = ?", req.Identity).First(ur).Error
+		err = l.svcCtx.DB.WithContext(l.ctx).Select("id").
+			Where("identity = ? AND user_identity = ?", req.Identity, userIdentity).First(ur).Error
 		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, err
 		}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 
+	"cloud-dist/core/audit"
 	"cloud-dist/core/internal/types"
 	"cloud-dist/core/models"
 	"cloud-dist/core/svc"
@@ -42,8 +43,13 @@ func (l *UserFileMoveLogic) UserFileMove(req *types.UserFileMoveRequest, userIde
 	}
 	// If parent_identity is empty, parentID remains 0 (root directory)
 
-	err = l.svcCtx.DB.WithContext(l.ctx).Model(&models.UserRepository{}).
+	res := l.svcCtx.DB.WithContext(l.ctx).Model(&models.UserRepository{}).
 		Where("identity = ? AND user_identity = ?", req.Idnetity, userIdentity).
-		Update("parent_id", parentID).Error
+		Update("parent_id", parentID)
+	if err = res.Error; err != nil || res.RowsAffected == 0 {
+		return
+	}
+	audit.Record(l.ctx, l.svcCtx.DB, audit.Entry{Subject: userIdentity, Action: audit.ActionFileMove,
+		TargetType: audit.TargetFile, Target: req.Idnetity, Detail: map[string]interface{}{"parent_identity": req.ParentIdnetity}})
 	return
 }
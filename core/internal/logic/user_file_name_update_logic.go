@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 
+	"cloud-dist/core/audit"
 	"cloud-dist/core/svc"
 	"cloud-dist/core/internal/types"
 	"cloud-dist/core/models"
@@ -23,11 +24,11 @@ func NewUserFileNameUpdateLogic(ctx context.Context, svcCtx *svc.ServiceContext)
 
 func (l *UserFileNameUpdateLogic) UserFileNameUpdate(req *types.UserFileNameUpdateRequest, userIdentity string) (resp *types.UserFileNameUpdateReply, err error) {
 	parentQuery := l.svcCtx.DB.WithContext(l.ctx).Model(&models.UserRepository{}).
-		Select("parent_id").Where("identity = ?", req.Identity).Limit(1)
+		Select("parent_id").Where("identity = ? AND user_identity = ?", req.Identity, userIdentity).Limit(1)
 
 	var cnt int64
 	if err = l.svcCtx.DB.WithContext(l.ctx).Model(&models.UserRepository{}).
-		Where("name = ?", req.Name).
+		Where("name = ? AND user_identity = ?", req.Name, userIdentity).
 		Where("parent_id = (?)", parentQuery).
 		Count(&cnt).Error; err != nil {
 		return nil, err
@@ -36,8 +37,13 @@ func (l *UserFileNameUpdateLogic) UserFileNameUpdate(req *types.UserFileNameUpda
 		return nil, errors.New("name already exists")
 	}
 
-	err = l.svcCtx.DB.WithContext(l.ctx).Model(&models.UserRepository{}).
+	res := l.svcCtx.DB.WithContext(l.ctx).Model(&models.UserRepository{}).
 		Where("identity = ? AND user_identity = ?", req.Identity, userIdentity).
-		Update("name", req.Name).Error
+		Update("name", req.Name)
+	if err = res.Error; err != nil || res.RowsAffected == 0 {
+		return
+	}
+	audit.Record(l.ctx, l.svcCtx.DB, audit.Entry{Subject: userIdentity, Action: audit.ActionFileRename,
+		TargetType: audit.TargetFile, Target: req.Identity, Detail: map[string]interface{}{"name": req.Name}})
 	return
 }
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 
+	"cloud-dist/core/audit"
 	"cloud-dist/core/helper"
 	"cloud-dist/core/svc"
 	"cloud-dist/core/internal/types"
@@ -43,6 +44,8 @@ func (l *UserFolderCreateLogic) UserFolderCreate(req *types.UserFolderCreateRequ
 	}
 	err = l.svcCtx.DB.WithContext(l.ctx).Create(data).Error
 	if err == nil {
+		audit.Record(l.ctx, l.svcCtx.DB, audit.Entry{Subject: userIdentity, Action: audit.ActionFileCreate,
+			TargetType: audit.TargetFile, Target: data.Identity, Detail: map[string]interface{}{"name": data.Name, "folder": true}})
 		resp = &types.UserFolderCreateReply{
 			Identity: data.Identity,
 		}
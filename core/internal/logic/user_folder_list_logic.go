@@ -23,7 +23,7 @@ func NewUserFolderListLogic(ctx context.Context, svcCtx *svc.ServiceContext) *Us
 	}
 }
 
-func (l *UserFolderListLogic) UserFolderList(req *types.UserFolderListRequest) (resp *types.UserFolderListReply, err error) {
+func (l *UserFolderListLogic) UserFolderList(req *types.UserFolderListRequest, userIdentity string) (resp *types.UserFolderListReply, err error) {
 	resp = new(types.UserFolderListReply)
 	folders := make([]*types.UserFolder, 0)
 
@@ -31,7 +31,7 @@ func (l *UserFolderListLogic) UserFolderList(req *types.UserFolderListRequest) (
 	if req.Identity != "" {
 		ur := new(models.UserRepository)
 		err = l.svcCtx.DB.WithContext(l.ctx).Select("id").
-			Where("identity = ?", req.Identity).First(ur).Error
+			Where("identity = ? AND user_identity = ?", req.Identity, userIdentity).First(ur).Error
 		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, err
 		}
@@ -42,6 +42,7 @@ func (l *UserFolderListLogic) UserFolderList(req *types.UserFolderListRequest) (
 
 	err = l.svcCtx.DB.WithContext(l.ctx).Table("user_repository").
 		Select("identity, name").
+		Where("user_identity = ?", userIdentity).
 		Where("parent_id = ?", parentID).
 		Where("deleted_at IS NULL").
 		Find(&folders).Error
@@ -0,0 +1,99 @@
+package logic
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+
+	"cloud-dist/core/define"
+	"cloud-dist/core/helper"
+	"cloud-dist/core/internal/types"
+	"cloud-dist/core/svc"
+)
+
+type UserLoginEmailLogic struct {
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewUserLoginEmailLogic(ctx context.Context, svcCtx *svc.ServiceContext) *UserLoginEmailLogic {
+	return &UserLoginEmailLogic{
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// UserLoginEmail redeems an emailed sign-in link or code. Either one works
+// once, and using one also burns the other.
+func (l *UserLoginEmailLogic) UserLoginEmail(req *types.LoginEmailRequest) (resp *types.LoginReply, err error) {
+	token := strings.TrimSpace(req.Token)
+	email := strings.TrimSpace(req.Email)
+	code := strings.TrimSpace(req.Code)
+
+	tokenHash := ""
+	if token != "" {
+		tokenHash = helper.HashLinkToken(token)
+		if email, err = l.svcCtx.RDB.Get(l.ctx, loginLinkTokenKey(tokenHash)).Result(); err != nil {
+			return nil, errLoginLinkInvalid
+		}
+	} else if email == "" || code == "" {
+		return nil, errors.New("sign-in token, or email and code, are required")
+	}
+
+	raw, err := l.svcCtx.RDB.Get(l.ctx, loginLinkKey(email)).Result()
+	if err != nil {
+		return nil, errLoginLinkInvalid
+	}
+	var record loginLinkRecord
+	if err = json.Unmarshal([]byte(raw), &record); err != nil {
+		return nil, errLoginLinkInvalid
+	}
+	user, err := findUserByIdentity(l.ctx, l.svcCtx, record.UserIdentity)
+	if err != nil || !strings.EqualFold(user.Email, email) {
+		return nil, errLoginLinkInvalid
+	}
+
+	// A locked account stays locked for passwordless login too
+	target := newLoginTarget(l.ctx, email, user)
+	if err = checkLoginAllowed(l.ctx, l.svcCtx, target); err != nil {
+		return nil, err
+	}
+
+	var ok bool
+	if tokenHash != "" {
+		ok = subtle.ConstantTimeCompare([]byte(tokenHash), []byte(record.TokenHash)) == 1
+	} else {
+		ok = subtle.ConstantTimeCompare([]byte(code), []byte(record.Code)) == 1
+	}
+	if !ok {
+		if lockErr := recordLoginFailure(l.ctx, l.svcCtx, target, "wrong_code"); lockErr != nil {
+			return nil, lockErr
+		}
+		// A code is only six digits, so it dies after a few wrong guesses
+		attempts, _ := l.svcCtx.RDB.Incr(l.ctx, loginLinkAttemptsKey(email)).Result()
+		l.svcCtx.RDB.Expire(l.ctx, loginLinkAttemptsKey(email), time.Second*time.Duration(define.CodeExpire))
+		if attempts >= loginLinkMaxAttempts {
+			l.svcCtx.RDB.Del(l.ctx, loginLinkKey(email), loginLinkTokenKey(record.TokenHash), loginLinkAttemptsKey(email))
+		}
+		return nil, errLoginLinkInvalid
+	}
+
+	// Only the request that actually deletes the record may sign in
+	deleted, err := l.svcCtx.RDB.Del(l.ctx, loginLinkKey(email)).Result()
+	if err != nil {
+		return nil, err
+	}
+	if deleted == 0 {
+		return nil, errLoginLinkInvalid
+	}
+	l.svcCtx.RDB.Del(l.ctx, loginLinkTokenKey(record.TokenHash), loginLinkAttemptsKey(email))
+
+	if user.TOTPEnabled {
+		return newLoginChallenge(l.ctx, l.svcCtx, user)
+	}
+	clearLoginFailures(l.ctx, l.svcCtx, target)
+	return startSession(l.ctx, l.svcCtx, user, req.DeviceName)
+}
@@ -3,14 +3,13 @@ package logic
 import (
 	"context"
 	"errors"
+	"sort"
+	"strings"
 
-	"cloud-dist/core/define"
 	"cloud-dist/core/helper"
 	"cloud-dist/core/svc"
 	"cloud-dist/core/internal/types"
 	"cloud-dist/core/models"
-
-	"gorm.io/gorm"
 )
 
 type UserLoginLogic struct {
@@ -26,33 +25,81 @@ func NewUserLoginLogic(ctx context.Context, svcCtx *svc.ServiceContext) *UserLog
 }
 
 func (l *UserLoginLogic) UserLogin(req *types.LoginRequest) (resp *types.LoginReply, err error) {
-	user := new(models.UserBasic)
-	err = l.svcCtx.DB.WithContext(l.ctx).
-		Where("name = ?", req.Name).
-		First(user).Error
-	if errors.Is(err, gorm.ErrRecordNotFound) {
-		return nil, errors.New("user not registered")
+	account := strings.TrimSpace(req.Name)
+	if account == "" || req.Password == "" {
+		return nil, errors.New("username or email and password are required")
 	}
+
+	// An email may belong to more than one account, so every match is a
+	// candidate; an exact username match is tried first
+	var candidates []*models.UserBasic
+	err = l.svcCtx.DB.WithContext(l.ctx).
+		Where("name = ? OR email = ?", account, account).
+		Order("id ASC").
+		Find(&candidates).Error
 	if err != nil {
 		return nil, err
 	}
-	
-	// Verify password using bcrypt
-	if !helper.CheckPasswordHash(req.Password, user.Password) {
-		return nil, errors.New("password incorrect")
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].Name == account && candidates[j].Name != account
+	})
+
+	// Each candidate is locked on its own, so a lockout on one account does
+	// not block the others that share its email
+	var targets []*loginTarget
+	var lockErr error
+	for _, c := range candidates {
+		t := newLoginTarget(l.ctx, account, c)
+		if err := checkLoginAllowed(l.ctx, l.svcCtx, t); err != nil {
+			if lockErr == nil {
+				lockErr = err
+			}
+			continue
+		}
+		targets = append(targets, t)
+	}
+	if len(candidates) == 0 {
+		t := newLoginTarget(l.ctx, account, nil)
+		if err = checkLoginAllowed(l.ctx, l.svcCtx, t); err != nil {
+			return nil, err
+		}
+		targets = append(targets, t)
+	}
+	if len(targets) == 0 {
+		return nil, lockErr
 	}
 
-	token, err := helper.GenerateToken(int(user.ID), user.Identity, user.Name, define.TokenExpire)
-	if err != nil {
-		return nil, err
+	// Verify password using bcrypt
+	var user *models.UserBasic
+	var target *loginTarget
+	for _, t := range targets {
+		if t.user != nil && helper.CheckPasswordHash(req.Password, t.user.Password) {
+			user, target = t.user, t
+			break
+		}
 	}
-	refreshToken, err := helper.GenerateToken(int(user.ID), user.Identity, user.Name, define.RefreshTokenExpire)
-	if err != nil {
-		return nil, err
+	if user == nil {
+		// Every candidate was tried, so each counts the failure, while the IP
+		// counts the guess only once. Unknown accounts and wrong passwords look
+		// the same to the caller.
+		lockErr = nil
+		for i, t := range targets {
+			t.skipIP = i > 0
+			if err := recordLoginFailure(l.ctx, l.svcCtx, t, "wrong_password"); err != nil && lockErr == nil {
+				lockErr = err
+			}
+		}
+		if lockErr != nil {
+			return nil, lockErr
+		}
+		return nil, errors.New("incorrect username, email or password")
 	}
 
-	resp = new(types.LoginReply)
-	resp.Token = token
-	resp.RefreshToken = refreshToken
-	return
+	// With 2FA on, the password alone only earns a challenge token, and the
+	// failure counters stay until the second factor is through as well
+	if user.TOTPEnabled {
+		return newLoginChallenge(l.ctx, l.svcCtx, user)
+	}
+	clearLoginFailures(l.ctx, l.svcCtx, target)
+	return startSession(l.ctx, l.svcCtx, user, req.DeviceName)
 }
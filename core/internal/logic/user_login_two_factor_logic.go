@@ -0,0 +1,68 @@
+package logic
+
+import (
+	"context"
+	"errors"
+
+	"cloud-dist/core/internal/types"
+	"cloud-dist/core/models"
+	"cloud-dist/core/svc"
+)
+
+type UserLoginTwoFactorLogic struct {
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewUserLoginTwoFactorLogic(ctx context.Context, svcCtx *svc.ServiceContext) *UserLoginTwoFactorLogic {
+	return &UserLoginTwoFactorLogic{
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// UserLoginTwoFactor finishes a login that stopped at the password step
+func (l *UserLoginTwoFactorLogic) UserLoginTwoFactor(req *types.LoginTwoFactorRequest) (resp *types.LoginReply, err error) {
+	if req.ChallengeToken == "" {
+		return nil, errors.New("challenge token is required")
+	}
+	key := loginChallengeKey(req.ChallengeToken)
+	userIdentity, err := l.svcCtx.RDB.Get(l.ctx, key).Result()
+	if err != nil {
+		return nil, errors.New("login challenge is expired or not found, please log in again")
+	}
+
+	// Each challenge allows a few guesses, then the password step is needed again
+	attemptsKey := loginChallengeAttemptsKey(req.ChallengeToken)
+	attempts, err := l.svcCtx.RDB.Incr(l.ctx, attemptsKey).Result()
+	if err != nil {
+		return nil, err
+	}
+	l.svcCtx.RDB.Expire(l.ctx, attemptsKey, loginChallengeTTL)
+	if attempts > loginChallengeMaxAttempts {
+		l.svcCtx.RDB.Del(l.ctx, key, attemptsKey)
+		return nil, errors.New("too many attempts, please log in again")
+	}
+
+	user := new(models.UserBasic)
+	if err = l.svcCtx.DB.WithContext(l.ctx).Where("identity = ?", userIdentity).First(user).Error; err != nil {
+		return nil, errors.New("login challenge is expired or not found, please log in again")
+	}
+
+	// Wrong codes count towards the account lockout like wrong passwords, so
+	// fresh challenges cannot be used to keep guessing
+	target := newLoginTarget(l.ctx, user.Name, user)
+	if err = checkLoginAllowed(l.ctx, l.svcCtx, target); err != nil {
+		return nil, err
+	}
+	if err = verifySecondFactor(l.ctx, l.svcCtx, user, req.Code); err != nil {
+		if lockErr := recordLoginFailure(l.ctx, l.svcCtx, target, "wrong_second_factor"); lockErr != nil {
+			return nil, lockErr
+		}
+		return nil, err
+	}
+
+	l.svcCtx.RDB.Del(l.ctx, key, attemptsKey)
+	clearLoginFailures(l.ctx, l.svcCtx, target)
+	return startSession(l.ctx, l.svcCtx, user, req.DeviceName)
+}
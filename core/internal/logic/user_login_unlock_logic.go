@@ -0,0 +1,58 @@
+package logic
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strings"
+	"time"
+
+	"cloud-dist/core/helper"
+	"cloud-dist/core/internal/types"
+	"cloud-dist/core/models"
+	"cloud-dist/core/svc"
+)
+
+var errUnlockLinkInvalid = errors.New("unlock link is invalid or has expired")
+
+type UserLoginUnlockLogic struct {
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewUserLoginUnlockLogic(ctx context.Context, svcCtx *svc.ServiceContext) *UserLoginUnlockLogic {
+	return &UserLoginUnlockLogic{
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// UserLoginUnlock lifts an account lockout from the link emailed to the owner.
+// The link works once and only while the lock lasts.
+func (l *UserLoginUnlockLogic) UserLoginUnlock(req *types.UserLoginUnlockRequest) (resp *types.UserLoginUnlockReply, err error) {
+	token := strings.TrimSpace(req.Token)
+	if token == "" {
+		return nil, errors.New("unlock token is required")
+	}
+	lockout := new(models.LoginLockout)
+	err = l.svcCtx.DB.WithContext(l.ctx).
+		Where("unlock_token_hash = ? AND scope = ? AND unlocked_at IS NULL AND locked_until > ?",
+			helper.HashLinkToken(token), loginScopeAccount, time.Now()).
+		First(lockout).Error
+	if err != nil || lockout.UserIdentity == "" {
+		return nil, errUnlockLinkInvalid
+	}
+
+	result := l.svcCtx.DB.WithContext(l.ctx).Model(lockout).Where("unlocked_at IS NULL").Update("unlocked_at", time.Now())
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, errUnlockLinkInvalid
+	}
+	if err = unlockLoginAccount(l.ctx, l.svcCtx, lockout.UserIdentity); err != nil {
+		return nil, err
+	}
+	log.Printf("[LoginGuard] User %s lifted the lockout from the emailed link", lockout.UserIdentity)
+	return &types.UserLoginUnlockReply{}, nil
+}
@@ -0,0 +1,28 @@
+package logic
+
+import (
+	"context"
+
+	"cloud-dist/core/internal/types"
+	"cloud-dist/core/svc"
+)
+
+type UserLogoutAllLogic struct {
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewUserLogoutAllLogic(ctx context.Context, svcCtx *svc.ServiceContext) *UserLogoutAllLogic {
+	return &UserLogoutAllLogic{
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// UserLogoutAll signs the user out on every device, including this one
+func (l *UserLogoutAllLogic) UserLogoutAll(req *types.UserLogoutAllRequest, userIdentity string) (resp *types.UserLogoutAllReply, err error) {
+	if err = logoutEverywhere(l.ctx, l.svcCtx, userIdentity, sessionRevokeLogoutAll); err != nil {
+		return nil, err
+	}
+	return &types.UserLogoutAllReply{}, nil
+}
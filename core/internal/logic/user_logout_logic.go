@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"cloud-dist/core/define"
+	"cloud-dist/core/helper"
 	"cloud-dist/core/svc"
 	"cloud-dist/core/internal/types"
 )
@@ -38,6 +39,15 @@ func (l *UserLogoutLogic) UserLogout(req *types.UserLogoutRequest, refreshToken
 		return nil, err
 	}
 	
+	// End the session the refresh token belongs to
+	if uc, err := helper.AnalyzeToken(refreshToken); err == nil && uc.SessionIdentity != "" {
+		err = revokeSessions(l.ctx, l.svcCtx, sessionRevokeLogout,
+			"identity = ? AND user_identity = ?", uc.SessionIdentity, uc.Identity)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	resp = &types.UserLogoutReply{}
 	return
 }
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 
+	"cloud-dist/core/audit"
 	"cloud-dist/core/helper"
 	"cloud-dist/core/svc"
 	"cloud-dist/core/internal/types"
@@ -25,26 +26,27 @@ func NewUserPasswordResetLogic(ctx context.Context, svcCtx *svc.ServiceContext)
 }
 
 func (l *UserPasswordResetLogic) UserPasswordReset(req *types.UserPasswordResetRequest) (resp *types.UserPasswordResetReply, err error) {
+	// Verify the code first; an unknown email never has one, so it fails the
+	// same way as a wrong code
+	if err = checkVerificationCode(l.ctx, l.svcCtx, codePurposePasswordReset, req.Email, req.Code); err != nil {
+		return nil, err
+	}
+
 	// Get user by email
 	user := new(models.UserBasic)
 	err = l.svcCtx.DB.WithContext(l.ctx).
 		Where("email = ?", req.Email).
 		First(user).Error
 	if errors.Is(err, gorm.ErrRecordNotFound) {
-		return nil, errors.New("email not found")
+		return nil, errCodeIncorrect
 	}
 	if err != nil {
 		return nil, err
 	}
 
-	// Verify email verification code
-	redisKey := "password_reset:" + req.Email
-	storedCode, err := l.svcCtx.RDB.Get(l.ctx, redisKey).Result()
-	if err != nil {
-		return nil, errors.New("verification code is expired or not found, please request a new code")
-	}
-	if storedCode != req.Code {
-		return nil, errors.New("verification code is incorrect")
+	// Accounts with 2FA also need the second factor
+	if err = requireSecondFactor(l.ctx, l.svcCtx, user, req.TwoFactorCode); err != nil {
+		return nil, err
 	}
 
 	// Validate new password
@@ -61,16 +63,29 @@ func (l *UserPasswordResetLogic) UserPasswordReset(req *types.UserPasswordResetR
 		return nil, errors.New("failed to hash new password")
 	}
 
-	// Update password
-	err = l.svcCtx.DB.WithContext(l.ctx).Model(&models.UserBasic{}).
-		Where("email = ?", req.Email).
-		Update("password", newPasswordHash).Error
+	if err = consumeVerificationCode(l.ctx, l.svcCtx, codePurposePasswordReset, req.Email); err != nil {
+		return nil, err
+	}
+
+	// Update password; passkeys go too, since one may have been added by
+	// whoever knew the old password
+	err = l.svcCtx.DB.WithContext(l.ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.UserBasic{}).Where("identity = ?", user.Identity).
+			Update("password", newPasswordHash).Error; err != nil {
+			return err
+		}
+		return deletePasskeys(tx, user.Identity)
+	})
 	if err != nil {
 		return nil, err
 	}
 
-	// Delete verification code after successful password reset
-	l.svcCtx.RDB.Del(l.ctx, redisKey)
+	// Whoever held the old password loses their sessions
+	if err = logoutEverywhere(l.ctx, l.svcCtx, user.Identity, sessionRevokePasswordReset); err != nil {
+		return nil, err
+	}
+	audit.Record(l.ctx, l.svcCtx.DB, audit.Entry{Actor: user.Identity, Action: audit.ActionPasswordReset,
+		TargetType: audit.TargetUser, Target: user.Identity})
 
 	resp = &types.UserPasswordResetReply{}
 	return
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 
+	"cloud-dist/core/audit"
 	"cloud-dist/core/helper"
 	"cloud-dist/core/internal/types"
 	"cloud-dist/core/models"
@@ -38,20 +39,22 @@ func (l *UserPasswordUpdateLogic) UserPasswordUpdate(req *types.UserPasswordUpda
 	}
 
 	// Verify email verification code
-	redisKey := "password_update:" + user.Email
-	storedCode, err := l.svcCtx.RDB.Get(l.ctx, redisKey).Result()
-	if err != nil {
-		return nil, errors.New("verification code is expired or not found, please request a new code")
-	}
-	if storedCode != req.Code {
-		return nil, errors.New("verification code is incorrect")
+	if err = checkVerificationCode(l.ctx, l.svcCtx, codePurposePasswordUpdate, user.Email, req.Code); err != nil {
+		return nil, err
 	}
 
 	// Verify old password using bcrypt
 	if !helper.CheckPasswordHash(req.OldPassword, user.Password) {
+		audit.Record(l.ctx, l.svcCtx.DB, audit.Entry{Actor: userIdentity, Action: audit.ActionPasswordChange,
+			TargetType: audit.TargetUser, Target: userIdentity, Reason: "wrong_password"})
 		return nil, errors.New("old password is incorrect")
 	}
 
+	// Accounts with 2FA also need the second factor
+	if err = requireSecondFactor(l.ctx, l.svcCtx, user, req.TwoFactorCode); err != nil {
+		return nil, err
+	}
+
 	// Validate new password
 	if req.NewPassword == "" {
 		return nil, errors.New("new password cannot be empty")
@@ -71,6 +74,10 @@ func (l *UserPasswordUpdateLogic) UserPasswordUpdate(req *types.UserPasswordUpda
 		return nil, errors.New("failed to hash new password")
 	}
 
+	if err = consumeVerificationCode(l.ctx, l.svcCtx, codePurposePasswordUpdate, user.Email); err != nil {
+		return nil, err
+	}
+
 	// Update password
 	err = l.svcCtx.DB.WithContext(l.ctx).Model(&models.UserBasic{}).
 		Where("identity = ?", userIdentity).
@@ -79,8 +86,12 @@ func (l *UserPasswordUpdateLogic) UserPasswordUpdate(req *types.UserPasswordUpda
 		return nil, err
 	}
 
-	// Delete verification code after successful password update
-	l.svcCtx.RDB.Del(l.ctx, redisKey)
+	// Tokens issued under the old password stop working, including this one
+	if err = logoutEverywhere(l.ctx, l.svcCtx, userIdentity, sessionRevokePasswordChange); err != nil {
+		return nil, err
+	}
+	audit.Record(l.ctx, l.svcCtx.DB, audit.Entry{Actor: userIdentity, Action: audit.ActionPasswordChange,
+		TargetType: audit.TargetUser, Target: userIdentity})
 
 	resp = &types.UserPasswordUpdateReply{}
 	return
@@ -0,0 +1,51 @@
+package logic
+
+import (
+	"context"
+	"errors"
+
+	"cloud-dist/core/internal/types"
+	"cloud-dist/core/models"
+	"cloud-dist/core/svc"
+)
+
+type UserPrivacyUpdateLogic struct {
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewUserPrivacyUpdateLogic(ctx context.Context, svcCtx *svc.ServiceContext) *UserPrivacyUpdateLogic {
+	return &UserPrivacyUpdateLogic{
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *UserPrivacyUpdateLogic) UserPrivacyUpdate(req *types.UserPrivacyUpdateRequest, userIdentity string) (resp *types.UserPrivacyUpdateReply, err error) {
+	updates := map[string]interface{}{}
+	if req.DiscoverableByName != nil {
+		updates["discoverable_by_name"] = *req.DiscoverableByName
+	}
+	if req.DiscoverableByEmail != nil {
+		updates["discoverable_by_email"] = *req.DiscoverableByEmail
+	}
+	if len(updates) == 0 {
+		return nil, errors.New("nothing to update")
+	}
+
+	err = l.svcCtx.DB.WithContext(l.ctx).Model(&models.UserBasic{}).
+		Where("identity = ?", userIdentity).
+		Updates(updates).Error
+	if err != nil {
+		return nil, err
+	}
+
+	ub := new(models.UserBasic)
+	if err = l.svcCtx.DB.WithContext(l.ctx).Where("identity = ?", userIdentity).First(ub).Error; err != nil {
+		return nil, err
+	}
+	return &types.UserPrivacyUpdateReply{
+		DiscoverableByName:  ub.DiscoverableByName,
+		DiscoverableByEmail: ub.DiscoverableByEmail,
+	}, nil
+}
@@ -25,13 +25,8 @@ func NewUserRegisterLogic(ctx context.Context, svcCtx *svc.ServiceContext) *User
 
 func (l *UserRegisterLogic) UserRegister(req *types.UserRegisterRequest) (resp *types.UserRegisterReply, err error) {
 	// Verify code
-	code, err := l.svcCtx.RDB.Get(l.ctx, req.Email).Result()
-	if err != nil {
-		return nil, errors.New("verification code is empty for this email")
-	}
-	if code != req.Code {
-		err = errors.New("verification code is incorrect")
-		return
+	if err = checkVerificationCode(l.ctx, l.svcCtx, codePurposeRegister, req.Email, req.Code); err != nil {
+		return nil, err
 	}
 	var cnt int64
 	if err = l.svcCtx.DB.WithContext(l.ctx).Model(&models.UserBasic{}).
@@ -42,6 +37,17 @@ func (l *UserRegisterLogic) UserRegister(req *types.UserRegisterRequest) (resp *
 		err = errors.New("username already exists")
 		return
 	}
+	// The address may have been taken by an email change since the code was sent
+	inUse, err := emailInUse(l.ctx, l.svcCtx, req.Email, "")
+	if err != nil {
+		return nil, err
+	}
+	if inUse {
+		return nil, errEmailInUse
+	}
+	if err = consumeVerificationCode(l.ctx, l.svcCtx, codePurposeRegister, req.Email); err != nil {
+		return nil, err
+	}
 	// Save user data
 	hashedPassword, err := helper.HashPassword(req.Password)
 	if err != nil {
@@ -59,5 +65,6 @@ func (l *UserRegisterLogic) UserRegister(req *types.UserRegisterRequest) (resp *
 		return nil, err
 	}
 	log.Println("insert user row:", user.ID)
+	claimShareInvites(l.ctx, l.svcCtx, user)
 	return
 }
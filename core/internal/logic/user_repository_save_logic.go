@@ -5,6 +5,7 @@ import (
 	"errors"
 	"log"
 
+	"cloud-dist/core/audit"
 	"cloud-dist/core/helper"
 	"cloud-dist/core/svc"
 	"cloud-dist/core/internal/types"
@@ -25,21 +26,21 @@ func NewUserRepositorySaveLogic(ctx context.Context, svcCtx *svc.ServiceContext)
 	}
 }
 
-func (l *UserRepositorySaveLogic) UserRepositorySave(req *types.UserRepositorySaveRequest, userIdentity string) (resp *types.UserRepositorySaveReply, err error) {
+func (l *UserRepositorySaveLogic) UserRepositorySave(req *types.UserRepositorySaveRequest, ownerIdentity, billingIdentity string) (resp *types.UserRepositorySaveReply, err error) {
 	// Check if this file already exists in user_repository for this user (global deduplication - user level, not folder level)
 	log.Printf("[UserRepositorySave] Checking for duplicate: user=%s, repository_identity=%s",
-		userIdentity, req.RepositoryIdentity)
+		ownerIdentity, req.RepositoryIdentity)
 
 	existingUr := new(models.UserRepository)
 	err = l.svcCtx.DB.WithContext(l.ctx).
-		Where("user_identity = ?", userIdentity).
+		Where("user_identity = ?", ownerIdentity).
 		Where("repository_identity = ?", req.RepositoryIdentity).
 		Where("deleted_at IS NULL").
 		First(existingUr).Error
 	if err == nil {
 		// File already exists in user's repository (anywhere)
 		log.Printf("[UserRepositorySave] File already exists in user repository: user=%s, repository_identity=%s, existing_identity=%s, existing_id=%d, existing_parent_id=%d",
-			userIdentity, req.RepositoryIdentity, existingUr.Identity, existingUr.ID, existingUr.ParentId)
+			ownerIdentity, req.RepositoryIdentity, existingUr.Identity, existingUr.ID, existingUr.ParentId)
 		// Return error to inform frontend that file already exists
 		err = errors.New("file already exists")
 		return
@@ -62,7 +63,7 @@ func (l *UserRepositorySaveLogic) UserRepositorySave(req *types.UserRepositorySa
 	}
 	ub := new(models.UserBasic)
 	if err = l.svcCtx.DB.WithContext(l.ctx).
-		Select("now_volume", "total_volume").Where("identity = ?", userIdentity).First(ub).Error; err != nil {
+		Select("now_volume", "total_volume").Where("identity = ?", billingIdentity).First(ub).Error; err != nil {
 		return
 	}
 	if ub.NowVolume+rp.Size > ub.TotalVolume {
@@ -72,23 +73,28 @@ func (l *UserRepositorySaveLogic) UserRepositorySave(req *types.UserRepositorySa
 
 	// Update current capacity
 	log.Printf("[UserRepositorySave] Updating user capacity: user=%s, file size=%d, current used=%d, after update=%d",
-		userIdentity, rp.Size, ub.NowVolume, ub.NowVolume+rp.Size)
+		billingIdentity, rp.Size, ub.NowVolume, ub.NowVolume+rp.Size)
 	if err = l.svcCtx.DB.WithContext(l.ctx).Model(&models.UserBasic{}).
-		Where("identity = ?", userIdentity).
+		Where("identity = ?", billingIdentity).
 		UpdateColumn("now_volume", gorm.Expr("now_volume + ?", rp.Size)).Error; err != nil {
 		log.Printf("[UserRepositorySave] Failed to update capacity: %v", err)
 		return
 	}
 	log.Printf("[UserRepositorySave] Capacity updated successfully")
+	notifyQuotaThreshold(l.ctx, l.svcCtx, billingIdentity, ub.NowVolume, ub.NowVolume+rp.Size, ub.TotalVolume)
 	// Create association record
 	ur := &models.UserRepository{
 		Identity:           helper.UUID(),
-		UserIdentity:       userIdentity,
+		UserIdentity:       ownerIdentity,
 		ParentId:           req.ParentId,
 		RepositoryIdentity: req.RepositoryIdentity,
 		Ext:                req.Ext,
 		Name:               req.Name,
 	}
-	err = l.svcCtx.DB.WithContext(l.ctx).Create(ur).Error
+	if err = l.svcCtx.DB.WithContext(l.ctx).Create(ur).Error; err != nil {
+		return
+	}
+	audit.Record(l.ctx, l.svcCtx.DB, audit.Entry{Subject: ownerIdentity, Action: audit.ActionFileCreate,
+		TargetType: audit.TargetFile, Target: ur.Identity, Detail: map[string]interface{}{"name": ur.Name, "size": rp.Size}})
 	return
 }
@@ -0,0 +1,67 @@
+package logic
+
+import (
+	"context"
+	"strings"
+
+	"cloud-dist/core/define"
+	"cloud-dist/core/internal/types"
+	"cloud-dist/core/svc"
+)
+
+type UserSearchLogic struct {
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewUserSearchLogic(ctx context.Context, svcCtx *svc.ServiceContext) *UserSearchLogic {
+	return &UserSearchLogic{
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// Searches cap the page size so the directory cannot be dumped in one call
+const maxUserSearchSize = 50
+
+func (l *UserSearchLogic) UserSearch(req *types.UserSearchRequest, userIdentity string) (resp *types.UserSearchReply, err error) {
+	resp = new(types.UserSearchReply)
+	resp.List = make([]*types.UserDirectoryItem, 0)
+
+	keyword := strings.TrimSpace(req.Keyword)
+	if len(keyword) < 2 {
+		return resp, nil
+	}
+
+	size := req.Size
+	if size <= 0 {
+		size = define.PageSize
+	}
+	if size > maxUserSearchSize {
+		size = maxUserSearchSize
+	}
+	page := req.Page
+	if page <= 0 {
+		page = 1
+	}
+	offset := (page - 1) * size
+
+	query := l.svcCtx.DB.WithContext(l.ctx).Table("user_basic").
+		Select("identity, name").
+		Where("deleted_at IS NULL AND identity != ?", userIdentity)
+	if strings.Contains(keyword, "@") {
+		// Emails only match exactly, and only for users who opted in
+		query = query.Where("email = ? AND discoverable_by_email = ?", keyword, true)
+	} else {
+		query = query.Where("name LIKE ? AND discoverable_by_name = ?", escapeLike(keyword)+"%", true)
+	}
+
+	err = query.Order("name ASC").Limit(size).Offset(offset).Scan(&resp.List).Error
+	if err != nil {
+		return nil, err
+	}
+	if err = fillDirectoryRelations(l.ctx, l.svcCtx, userIdentity, resp.List); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
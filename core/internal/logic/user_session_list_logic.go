@@ -0,0 +1,49 @@
+package logic
+
+import (
+	"context"
+	"time"
+
+	"cloud-dist/core/define"
+	"cloud-dist/core/internal/types"
+	"cloud-dist/core/models"
+	"cloud-dist/core/svc"
+)
+
+type UserSessionListLogic struct {
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewUserSessionListLogic(ctx context.Context, svcCtx *svc.ServiceContext) *UserSessionListLogic {
+	return &UserSessionListLogic{
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *UserSessionListLogic) UserSessionList(req *types.UserSessionListRequest, userIdentity, sessionIdentity string) (resp *types.UserSessionListReply, err error) {
+	var sessions []*models.UserSession
+	err = l.svcCtx.DB.WithContext(l.ctx).
+		Where("user_identity = ? AND revoked_at IS NULL AND expires_at > ?", userIdentity, time.Now()).
+		Order("last_used_at DESC").
+		Find(&sessions).Error
+	if err != nil {
+		return nil, err
+	}
+
+	resp = new(types.UserSessionListReply)
+	resp.List = make([]*types.UserSessionItem, 0, len(sessions))
+	for _, s := range sessions {
+		resp.List = append(resp.List, &types.UserSessionItem{
+			Identity:   s.Identity,
+			DeviceName: s.DeviceName,
+			IP:         s.IP,
+			UserAgent:  s.UserAgent,
+			LastUsedAt: s.LastUsedAt.Format(define.Datetime),
+			CreatedAt:  s.CreatedAt.Format(define.Datetime),
+			Current:    s.Identity == sessionIdentity,
+		})
+	}
+	return resp, nil
+}
@@ -0,0 +1,43 @@
+package logic
+
+import (
+	"context"
+	"errors"
+
+	"cloud-dist/core/internal/types"
+	"cloud-dist/core/models"
+	"cloud-dist/core/svc"
+)
+
+type UserSessionRevokeLogic struct {
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewUserSessionRevokeLogic(ctx context.Context, svcCtx *svc.ServiceContext) *UserSessionRevokeLogic {
+	return &UserSessionRevokeLogic{
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// UserSessionRevoke signs one device out. Revoking the current session works
+// like logging out.
+func (l *UserSessionRevokeLogic) UserSessionRevoke(req *types.UserSessionRevokeRequest, userIdentity string) (resp *types.UserSessionRevokeReply, err error) {
+	var cnt int64
+	err = l.svcCtx.DB.WithContext(l.ctx).Model(&models.UserSession{}).
+		Where("identity = ? AND user_identity = ? AND revoked_at IS NULL", req.Identity, userIdentity).
+		Count(&cnt).Error
+	if err != nil {
+		return nil, err
+	}
+	if cnt == 0 {
+		return nil, errors.New("session not found")
+	}
+
+	err = revokeSessions(l.ctx, l.svcCtx, sessionRevokeManual, "identity = ? AND user_identity = ?", req.Identity, userIdentity)
+	if err != nil {
+		return nil, err
+	}
+	return &types.UserSessionRevokeReply{}, nil
+}
@@ -0,0 +1,38 @@
+package logic
+
+import (
+	"context"
+
+	"cloud-dist/core/internal/types"
+	"cloud-dist/core/models"
+	"cloud-dist/core/svc"
+)
+
+type UserSessionRevokeOthersLogic struct {
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewUserSessionRevokeOthersLogic(ctx context.Context, svcCtx *svc.ServiceContext) *UserSessionRevokeOthersLogic {
+	return &UserSessionRevokeOthersLogic{
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// UserSessionRevokeOthers signs out every device except the one asking
+func (l *UserSessionRevokeOthersLogic) UserSessionRevokeOthers(req *types.UserSessionRevokeOthersRequest, userIdentity, sessionIdentity string) (resp *types.UserSessionRevokeOthersReply, err error) {
+	var cnt int64
+	err = l.svcCtx.DB.WithContext(l.ctx).Model(&models.UserSession{}).
+		Where("user_identity = ? AND identity != ? AND revoked_at IS NULL", userIdentity, sessionIdentity).
+		Count(&cnt).Error
+	if err != nil {
+		return nil, err
+	}
+
+	err = revokeSessions(l.ctx, l.svcCtx, sessionRevokeManual, "user_identity = ? AND identity != ?", userIdentity, sessionIdentity)
+	if err != nil {
+		return nil, err
+	}
+	return &types.UserSessionRevokeOthersReply{Revoked: cnt}, nil
+}
@@ -0,0 +1,61 @@
+package logic
+
+import (
+	"context"
+
+	"cloud-dist/core/internal/types"
+	"cloud-dist/core/svc"
+)
+
+type UserSuggestionListLogic struct {
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewUserSuggestionListLogic(ctx context.Context, svcCtx *svc.ServiceContext) *UserSuggestionListLogic {
+	return &UserSuggestionListLogic{
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+const maxUserSuggestions = 50
+
+// UserSuggestionList suggests friends of friends, ranked by how many friends
+// they have in common with the caller
+func (l *UserSuggestionListLogic) UserSuggestionList(req *types.UserSuggestionListRequest, userIdentity string) (resp *types.UserSuggestionListReply, err error) {
+	resp = new(types.UserSuggestionListReply)
+	resp.List = make([]*types.UserDirectoryItem, 0)
+
+	size := req.Size
+	if size <= 0 {
+		size = 10
+	}
+	if size > maxUserSuggestions {
+		size = maxUserSuggestions
+	}
+
+	// Skip anyone the caller already has a friend row with (friends and
+	// blocks in either direction) or an open request with
+	err = l.svcCtx.DB.WithContext(l.ctx).Table("friend AS mine").
+		Select("candidate.identity, candidate.name, COUNT(*) AS mutual_count").
+		Joins("JOIN friend AS theirs ON theirs.user_identity = mine.friend_identity AND theirs.status = ? AND theirs.deleted_at IS NULL", friendStatusActive).
+		Joins("JOIN user_basic AS candidate ON candidate.identity = theirs.friend_identity AND candidate.deleted_at IS NULL").
+		Where("mine.user_identity = ? AND mine.status = ? AND mine.deleted_at IS NULL", userIdentity, friendStatusActive).
+		Where("candidate.identity != ? AND candidate.discoverable_by_name = ?", userIdentity, true).
+		Where("NOT EXISTS (SELECT 1 FROM friend WHERE friend.deleted_at IS NULL AND "+
+			"((friend.user_identity = ? AND friend.friend_identity = candidate.identity) OR "+
+			"(friend.user_identity = candidate.identity AND friend.friend_identity = ?)))", userIdentity, userIdentity).
+		Where("NOT EXISTS (SELECT 1 FROM friend_request WHERE friend_request.deleted_at IS NULL AND friend_request.status IN ? AND "+
+			"((friend_request.from_user_identity = ? AND friend_request.to_user_identity = candidate.identity) OR "+
+			"(friend_request.from_user_identity = candidate.identity AND friend_request.to_user_identity = ?)))",
+			[]string{friendRequestStatusPending, friendRequestStatusBlocked}, userIdentity, userIdentity).
+		Group("candidate.identity, candidate.name").
+		Order("mutual_count DESC, candidate.name ASC").
+		Limit(size).
+		Scan(&resp.List).Error
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
@@ -0,0 +1,143 @@
+package logic
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"cloud-dist/core/define"
+	"cloud-dist/core/helper"
+	"cloud-dist/core/svc"
+)
+
+// What an emailed verification code is for; codes for one purpose cannot be
+// used for another
+const (
+	codePurposeRegister       = "register"
+	codePurposePasswordReset  = "password_reset"
+	codePurposePasswordUpdate = "password_update"
+	codePurposeLogin          = "login"
+	codePurposeEmailChange    = "email_change"
+	codePurposeReauth         = "reauth"
+)
+
+// Sending is throttled per email and per IP; checking allows a few wrong
+// guesses before the code is thrown away
+const (
+	codeResendInterval = 60 * time.Second
+	codeSendWindow     = time.Hour
+	codeSendPerEmail   = 5
+	codeSendPerIP      = 20
+	codeMaxAttempts    = 5
+)
+
+var (
+	errCodeSendTooSoon = errors.New("please wait 60 seconds before requesting another code")
+	errCodeSendLimit   = errors.New("too many codes requested, please try again later")
+	errCodeIncorrect   = errors.New("verification code is incorrect or has expired")
+)
+
+func codeKey(purpose, email string) string {
+	return "verify_code:" + purpose + ":" + strings.ToLower(email)
+}
+
+func codeSentKey(purpose, email string) string {
+	return "verify_code_sent:" + purpose + ":" + strings.ToLower(email)
+}
+
+func codeAttemptsKey(purpose, email string) string {
+	return "verify_code_attempts:" + purpose + ":" + strings.ToLower(email)
+}
+
+// throttleCodeSend counts a send request against the email and the client IP.
+// It runs whether or not a mail is actually sent, so limits do not reveal
+// which emails have accounts.
+func throttleCodeSend(ctx context.Context, svcCtx *svc.ServiceContext, purpose, email string) error {
+	ok, err := svcCtx.RDB.SetNX(ctx, codeSentKey(purpose, email), 1, codeResendInterval).Result()
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return errCodeSendTooSoon
+	}
+
+	counters := map[string]int64{"verify_code_count:email:" + strings.ToLower(email): codeSendPerEmail}
+	if ip := helper.ClientMetaFromContext(ctx).IP; ip != "" {
+		counters["verify_code_count:ip:"+ip] = codeSendPerIP
+	}
+	for key, limit := range counters {
+		n, err := svcCtx.RDB.Incr(ctx, key).Result()
+		if err != nil {
+			return err
+		}
+		if n == 1 {
+			svcCtx.RDB.Expire(ctx, key, codeSendWindow)
+		}
+		if n > limit {
+			log.Printf("[VerificationCode] Send limit reached for %s", key)
+			return errCodeSendLimit
+		}
+	}
+	return nil
+}
+
+// sendVerificationCode stores a new code for purpose and email and mails it,
+// replacing any earlier code and its failed attempts
+func sendVerificationCode(ctx context.Context, svcCtx *svc.ServiceContext, purpose, email string) error {
+	code := helper.RandCode()
+	pipe := svcCtx.RDB.TxPipeline()
+	pipe.Set(ctx, codeKey(purpose, email), code, time.Second*time.Duration(define.CodeExpire))
+	pipe.Del(ctx, codeAttemptsKey(purpose, email))
+	if _, err := pipe.Exec(ctx); err != nil {
+		return err
+	}
+	if err := helper.MailSendCode(email, code); err != nil {
+		// If sending fails, delete stored verification code so a retry may follow at once
+		svcCtx.RDB.Del(ctx, codeKey(purpose, email), codeSentKey(purpose, email))
+		log.Printf("[VerificationCode] Send %s code failed: %v", purpose, err)
+		return fmt.Errorf("failed to send verification code: %v", err)
+	}
+	log.Printf("[VerificationCode] Sent %s code to %s", purpose, email)
+	return nil
+}
+
+// checkVerificationCode compares code in constant time. After codeMaxAttempts
+// wrong guesses the code is deleted and a new one must be requested. A correct
+// code stays valid until consumeVerificationCode.
+func checkVerificationCode(ctx context.Context, svcCtx *svc.ServiceContext, purpose, email, code string) error {
+	stored, err := svcCtx.RDB.Get(ctx, codeKey(purpose, email)).Result()
+	if err != nil || code == "" {
+		return errCodeIncorrect
+	}
+	if subtle.ConstantTimeCompare([]byte(stored), []byte(strings.TrimSpace(code))) == 1 {
+		return nil
+	}
+	attemptsKey := codeAttemptsKey(purpose, email)
+	attempts, err := svcCtx.RDB.Incr(ctx, attemptsKey).Result()
+	if err != nil {
+		return err
+	}
+	svcCtx.RDB.Expire(ctx, attemptsKey, time.Second*time.Duration(define.CodeExpire))
+	if attempts >= codeMaxAttempts {
+		svcCtx.RDB.Del(ctx, codeKey(purpose, email), attemptsKey)
+	}
+	return errCodeIncorrect
+}
+
+// consumeVerificationCode deletes a checked code. Only one of two concurrent
+// requests with the same code gets past it.
+func consumeVerificationCode(ctx context.Context, svcCtx *svc.ServiceContext, purpose, email string) error {
+	deleted, err := svcCtx.RDB.Del(ctx, codeKey(purpose, email)).Result()
+	if err != nil {
+		return err
+	}
+	if deleted == 0 {
+		return errCodeIncorrect
+	}
+	svcCtx.RDB.Del(ctx, codeAttemptsKey(purpose, email))
+	return nil
+}
@@ -1,17 +1,27 @@
 package middleware
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
 	"net/http"
 	"strings"
+	"time"
 
+	"cloud-dist/core/define"
 	"cloud-dist/core/helper"
+	"cloud-dist/core/models"
+	"cloud-dist/core/tokenversion"
 
 	"github.com/gin-gonic/gin"
 	"github.com/go-redis/redis/v8"
+	"gorm.io/gorm"
 )
 
 type AuthMiddleware struct {
-	RDB *redis.Client
+	RDB           *redis.Client
+	DB            *gorm.DB
+	TokenVersions *tokenversion.Store
 }
 
 func NewAuthMiddleware() *AuthMiddleware {
@@ -23,6 +33,17 @@ func (m *AuthMiddleware) SetRedisClient(rdb *redis.Client) {
 	m.RDB = rdb
 }
 
+// SetDB enables personal access tokens, which are looked up in the database
+func (m *AuthMiddleware) SetDB(db *gorm.DB) {
+	m.DB = db
+}
+
+// SetTokenVersions enables rejecting tokens issued before the user's last
+// password change or "log out everywhere"
+func (m *AuthMiddleware) SetTokenVersions(store *tokenversion.Store) {
+	m.TokenVersions = store
+}
+
 func (m *AuthMiddleware) Handle(c *gin.Context) {
 	auth := c.GetHeader("Authorization")
 	if auth == "" {
@@ -33,11 +54,22 @@ func (m *AuthMiddleware) Handle(c *gin.Context) {
 	token := strings.TrimPrefix(auth, "Bearer ")
 	token = strings.TrimSpace(token)
 
-	// Note: Access token blacklist check removed
-	// Access tokens are short-lived (1 hour), so we rely on refresh token blacklist instead
-	// This improves performance by avoiding Redis lookup on every request
+	if strings.HasPrefix(token, define.AccessTokenPrefix) {
+		uc, scopes, err := m.verifyAccessToken(c.Request.Context(), token)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+			return
+		}
+		c.Set("UserId", uc.Id)
+		c.Set("UserIdentity", uc.Identity)
+		c.Set("UserName", uc.Name)
+		c.Set("TokenScopes", scopes)
+		setContextUser(c, uc.Identity)
+		c.Next()
+		return
+	}
 
-	uc, err := helper.AnalyzeToken(token)
+	uc, err := m.verify(c.Request.Context(), token)
 	if err != nil {
 		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
 		return
@@ -49,10 +81,191 @@ func (m *AuthMiddleware) Handle(c *gin.Context) {
 		return
 	}
 
-	// Set user information in context
+	setSessionUser(c, uc)
+	c.Next()
+}
+
+// setSessionUser puts the user of a login session token in the context
+func setSessionUser(c *gin.Context, uc *define.UserClaim) {
 	c.Set("UserId", uc.Id)
 	c.Set("UserIdentity", uc.Identity)
 	c.Set("UserName", uc.Name)
+	c.Set("SessionIdentity", uc.SessionIdentity)
+	c.Set("TokenClaim", uc)
+	setContextUser(c, uc.Identity)
+}
+
+// verify parses an access token and rejects refresh tokens and tokens whose
+// session was revoked. Only tokens bound to a session cost a Redis lookup;
+// a revoked session is marked for as long as its access tokens live.
+func (m *AuthMiddleware) verify(ctx context.Context, token string) (*define.UserClaim, error) {
+	uc, err := helper.AnalyzeToken(token)
+	if err != nil {
+		return nil, err
+	}
+	if uc.TokenType == define.TokenTypeRefresh {
+		return nil, errors.New("refresh token cannot be used for API access")
+	}
+	if err = m.Revalidate(ctx, uc); err != nil {
+		return nil, err
+	}
+	return uc, nil
+}
+
+// Revalidate repeats the checks a token can fail after it was issued: its
+// session was revoked, or the user's token generation moved on through a
+// password change, "log out everywhere" or a suspension. Long-lived
+// connections call it periodically.
+func (m *AuthMiddleware) Revalidate(ctx context.Context, uc *define.UserClaim) error {
+	if uc.SessionIdentity != "" && m.RDB != nil {
+		n, err := m.RDB.Exists(ctx, define.SessionRevokedPrefix+uc.SessionIdentity).Result()
+		if err == nil && n > 0 {
+			return errors.New("session has been revoked")
+		}
+	}
+	if m.TokenVersions != nil {
+		if err := m.TokenVersions.Check(ctx, uc.Identity, uc.TokenVersion); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// verifyAccessToken looks up a personal access token and returns its owner
+// and scopes. last_used_at is written at most once a minute per token.
+func (m *AuthMiddleware) verifyAccessToken(ctx context.Context, token string) (*define.UserClaim, []string, error) {
+	if m.DB == nil {
+		return nil, nil, errors.New("personal access tokens are not enabled")
+	}
+	pat := new(models.PersonalAccessToken)
+	err := m.DB.WithContext(ctx).
+		Where("token_hash = ? AND revoked_at IS NULL", helper.HashAccessToken(token)).
+		First(pat).Error
+	if err != nil {
+		return nil, nil, errors.New("invalid access token")
+	}
+	now := time.Now()
+	if pat.ExpiresAt != nil && now.After(*pat.ExpiresAt) {
+		return nil, nil, errors.New("access token has expired")
+	}
+
+	user := new(models.UserBasic)
+	if err = m.DB.WithContext(ctx).Where("identity = ?", pat.UserIdentity).First(user).Error; err != nil {
+		return nil, nil, errors.New("invalid access token")
+	}
+	if user.SuspendedAt != nil {
+		return nil, nil, errors.New("account is suspended")
+	}
+
+	if pat.LastUsedAt == nil || now.Sub(*pat.LastUsedAt) > time.Minute {
+		m.DB.WithContext(ctx).Model(&models.PersonalAccessToken{}).
+			Where("id = ?", pat.ID).
+			UpdateColumn("last_used_at", now)
+	}
+
+	uc := &define.UserClaim{Id: int(user.ID), Identity: user.Identity, Name: user.Name}
+	return uc, strings.Split(pat.Scopes, ","), nil
+}
+
+// RequireScope lets personal access tokens through only when they carry all
+// of the given scopes. Login sessions carry every scope.
+func RequireScope(scopes ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		granted, ok := c.Get("TokenScopes")
+		if !ok {
+			c.Next()
+			return
+		}
+		have, _ := granted.([]string)
+		for _, scope := range scopes {
+			found := false
+			for _, s := range have {
+				if s == scope {
+					found = true
+					break
+				}
+			}
+			if !found {
+				c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "access token is missing the " + scope + " scope"})
+				return
+			}
+		}
+		c.Next()
+	}
+}
+
+// RequireSession refuses personal access tokens, for account settings and
+// other endpoints no scope covers
+func RequireSession(c *gin.Context) {
+	if _, ok := c.Get("TokenScopes"); ok {
+		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "personal access tokens cannot use this endpoint"})
+		return
+	}
+	c.Next()
+}
+
+// HandleOptional sets user information when a valid token is supplied but
+// lets anonymous requests through, for public routes that behave differently
+// for signed-in users.
+func (m *AuthMiddleware) HandleOptional(c *gin.Context) {
+	auth := c.GetHeader("Authorization")
+	if auth == "" {
+		c.Next()
+		return
+	}
+	token := strings.TrimSpace(strings.TrimPrefix(auth, "Bearer "))
+
+	uc, err := m.verify(c.Request.Context(), token)
+	if err == nil && uc.Identity != "" {
+		setSessionUser(c, uc)
+	}
+
+	c.Next()
+}
+
+// setContextUser adds the caller to the client details on the request
+// context, so logic layers can name the actor in the audit log
+func setContextUser(c *gin.Context, userIdentity string) {
+	meta := helper.ClientMetaFromContext(c.Request.Context())
+	meta.UserIdentity = userIdentity
+	c.Request = c.Request.WithContext(helper.WithClientMeta(c.Request.Context(), meta))
+}
+
+// HandleStreamTicket is Handle for the browser EventSource API, which cannot
+// set headers. It redeems the single-use ticket query parameter; without one
+// the Authorization header is used. Access tokens are never read from the URL,
+// where they would end up in proxy and server logs.
+func (m *AuthMiddleware) HandleStreamTicket(c *gin.Context) {
+	ticket := c.Query("ticket")
+	if ticket == "" {
+		m.Handle(c)
+		return
+	}
+	if m.RDB == nil {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "stream tickets are not enabled"})
+		return
+	}
+
+	ctx := c.Request.Context()
+	data, err := m.RDB.GetDel(ctx, define.EventTicketPrefix+helper.HashLinkToken(ticket)).Bytes()
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "invalid or expired stream ticket"})
+		return
+	}
+	uc := new(define.UserClaim)
+	if err = json.Unmarshal(data, uc); err != nil || uc.Identity == "" {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "invalid or expired stream ticket"})
+		return
+	}
+	if uc.ExpiresAt != nil && time.Now().After(uc.ExpiresAt.Time) {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "token has expired"})
+		return
+	}
+	if err = m.Revalidate(ctx, uc); err != nil {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
 
+	setSessionUser(c, uc)
 	c.Next()
 }
@@ -0,0 +1,18 @@
+package middleware
+
+import (
+	"cloud-dist/core/helper"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ClientMeta copies the caller's IP and user agent into the request context
+// so logic layers can record them without extra parameters.
+func ClientMeta(c *gin.Context) {
+	ctx := helper.WithClientMeta(c.Request.Context(), helper.ClientMeta{
+		IP:        c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+	})
+	c.Request = c.Request.WithContext(ctx)
+	c.Next()
+}
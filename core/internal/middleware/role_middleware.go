@@ -0,0 +1,48 @@
+package middleware
+
+import (
+	"net/http"
+
+	"cloud-dist/core/define"
+	"cloud-dist/core/models"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type RoleMiddleware struct {
+	DB *gorm.DB
+}
+
+func NewRoleMiddleware(db *gorm.DB) *RoleMiddleware {
+	return &RoleMiddleware{DB: db}
+}
+
+// Require lets the request through only when the caller's account role is at
+// least role. The role is read on every request rather than kept in the token,
+// so taking it away works at once. It must run after authentication.
+func (m *RoleMiddleware) Require(role string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		// An outer Require on the same route already loaded the role
+		if have := c.GetString("UserRole"); have != "" {
+			if !define.UserRoleAllows(have, role) {
+				c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "your account does not allow this action"})
+				return
+			}
+			c.Next()
+			return
+		}
+
+		user := new(models.UserBasic)
+		err := m.DB.WithContext(c.Request.Context()).
+			Select("role", "suspended_at").
+			Where("identity = ?", c.GetString("UserIdentity")).
+			First(user).Error
+		if err != nil || user.SuspendedAt != nil || !define.UserRoleAllows(user.Role, role) {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "your account does not allow this action"})
+			return
+		}
+		c.Set("UserRole", user.Role)
+		c.Next()
+	}
+}
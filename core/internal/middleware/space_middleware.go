@@ -0,0 +1,76 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+
+	"cloud-dist/core/define"
+	"cloud-dist/core/models"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// SpaceHeader selects the team space a file API call operates on
+const SpaceHeader = "X-Space-Identity"
+
+type SpaceMiddleware struct {
+	DB *gorm.DB
+}
+
+func NewSpaceMiddleware(db *gorm.DB) *SpaceMiddleware {
+	return &SpaceMiddleware{DB: db}
+}
+
+// Require resolves the space context of a file API call and checks that the
+// caller holds at least the given role. It must run after authentication.
+//
+// Without a space it sets OwnerIdentity and BillingIdentity to the caller.
+// With a space (X-Space-Identity header, or space_identity query parameter for
+// plain download links) OwnerIdentity is the space and BillingIdentity its owner.
+func (m *SpaceMiddleware) Require(role string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userIdentity := c.GetString("UserIdentity")
+		spaceIdentity := c.GetHeader(SpaceHeader)
+		if spaceIdentity == "" {
+			spaceIdentity = c.Query("space_identity")
+		}
+		if spaceIdentity == "" {
+			c.Set("OwnerIdentity", userIdentity)
+			c.Set("BillingIdentity", userIdentity)
+			c.Next()
+			return
+		}
+
+		member := new(models.SpaceMember)
+		err := m.DB.WithContext(c.Request.Context()).
+			Where("space_identity = ? AND user_identity = ?", spaceIdentity, userIdentity).
+			First(member).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "you are not a member of this space"})
+			return
+		}
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		if !define.SpaceRoleAllows(member.Role, role) {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "your role in this space does not allow this action"})
+			return
+		}
+
+		space := new(models.Space)
+		err = m.DB.WithContext(c.Request.Context()).
+			Select("owner_identity").Where("identity = ?", spaceIdentity).First(space).Error
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "space not found"})
+			return
+		}
+
+		c.Set("SpaceIdentity", spaceIdentity)
+		c.Set("SpaceRole", member.Role)
+		c.Set("OwnerIdentity", spaceIdentity)
+		c.Set("BillingIdentity", space.OwnerIdentity)
+		c.Next()
+	}
+}
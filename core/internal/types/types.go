@@ -48,14 +48,96 @@ type RefreshAuthorizationReply struct {
 }
 
 type UserPasswordUpdateRequest struct {
-	OldPassword string `json:"old_password"`
-	NewPassword string `json:"new_password"`
-	Code        string `json:"code"` // Email verification code
+	OldPassword   string `json:"old_password"`
+	NewPassword   string `json:"new_password"`
+	Code          string `json:"code"`                     // Email verification code
+	TwoFactorCode string `json:"two_factor_code,optional"` // TOTP or recovery code, required when 2FA is enabled
 }
 
 type UserPasswordUpdateReply struct {
 }
 
+type UserEmailChangeRequest struct {
+	NewEmail      string `json:"new_email"`
+	Password      string `json:"password,optional"`
+	EmailCode     string `json:"email_code,optional"`      // From /mail/code/send/reauth, instead of the password
+	TwoFactorCode string `json:"two_factor_code,optional"` // TOTP or recovery code, required when 2FA is enabled
+}
+
+type UserEmailChangeReply struct {
+}
+
+type UserEmailChangeConfirmRequest struct {
+	Code string `json:"code"` // Code sent to the new address
+}
+
+type UserEmailChangeConfirmReply struct {
+	Email string `json:"email"`
+}
+
+type UserEmailChangeUndoRequest struct {
+	Token string `json:"token"` // From the link sent to the old address
+}
+
+type UserEmailChangeUndoReply struct {
+	Email string `json:"email"` // The restored address
+}
+
+type UserLoginUnlockRequest struct {
+	Token string `json:"token"` // From the link in the lockout email
+}
+
+type UserLoginUnlockReply struct {
+}
+
+type AccountExportCreateRequest struct {
+}
+
+type AccountExportCreateReply struct {
+	Identity string `json:"identity"`
+}
+
+type AccountExportListRequest struct {
+}
+
+type AccountExportListReply struct {
+	List []*AccountExportItem `json:"list"`
+}
+
+type AccountExportItem struct {
+	Identity   string `json:"identity"`
+	Status     string `json:"status"` // pending, running, ready, failed, expired
+	Size       int64  `json:"size"`
+	Error      string `json:"error,omitempty"`
+	CreatedAt  string `json:"created_at"`
+	FinishedAt string `json:"finished_at,omitempty"`
+	ExpiresAt  string `json:"expires_at,omitempty"`
+}
+
+type AccountExportDownloadRequest struct {
+	Identity string `json:"identity"`
+}
+
+type AccountExportDownloadReply struct {
+	URL string `json:"url"`
+}
+
+type AccountDeleteRequest struct {
+	Password      string `json:"password,optional"`
+	EmailCode     string `json:"email_code,optional"`      // From /mail/code/send/reauth, instead of the password
+	TwoFactorCode string `json:"two_factor_code,optional"` // TOTP or recovery code, required when 2FA is enabled
+}
+
+type AccountDeleteReply struct {
+	DeleteAfter string `json:"delete_after"`
+}
+
+type AccountDeleteCancelRequest struct {
+}
+
+type AccountDeleteCancelReply struct {
+}
+
 type ShareBasicSaveRequest struct {
 	RepositoryIdentity string `json:"repository_identity"`
 	ParentId           int64  `json:"parent_id"`
@@ -74,13 +156,60 @@ type ShareBasicDetailReply struct {
 	Name               string `json:"name"`
 	Ext                string `json:"ext"`
 	Size               int64  `json:"size"`
-	Path               string `json:"path"`         // Presigned URL for preview
-	DownloadUrl        string `json:"download_url"` // Presigned URL for download (with Content-Disposition: attachment)
+	Path               string `json:"path"`         // Signed download endpoint URL for inline preview
+	DownloadUrl        string `json:"download_url"` // Signed download endpoint URL (served as an attachment)
+}
+
+type ShareBasicDownloadRequest struct {
+	Identity string `json:"identity"`
+	Token    string `json:"token"`           // Signed download token issued by /share/basic/detail
+	Inline   bool   `json:"inline,optional"` // Serve for preview instead of as an attachment
+	Range    string `json:"-"`               // HTTP Range header, forwarded to S3 in proxy mode
+}
+
+type ShareBasicRevokeRequest struct {
+	Identity string `json:"identity"`
+}
+
+type ShareBasicRevokeReply struct {
+}
+
+type ShareBasicAccessListRequest struct {
+	Identity string `json:"identity"`
+	Event    string `json:"event,optional"` // Filter by event: view, download, preview
+	Page     int    `json:"page,optional"`
+	Size     int    `json:"size,optional"`
+}
+
+type ShareBasicAccessListReply struct {
+	List  []*ShareAccessItem `json:"list"`
+	Count int64              `json:"count"`
+	Stats ShareAccessStats   `json:"stats"`
+}
+
+type ShareAccessItem struct {
+	Event        string `json:"event"`
+	Success      bool   `json:"success"`
+	Reason       string `json:"reason"`
+	UserIdentity string `json:"user_identity"` // Empty for anonymous visitors
+	UserName     string `json:"user_name"`
+	Ip           string `json:"ip"`
+	UserAgent    string `json:"user_agent"`
+	CreatedAt    string `json:"created_at"`
+}
+
+type ShareAccessStats struct {
+	Views          int64 `json:"views"`
+	Downloads      int64 `json:"downloads"`
+	Previews       int64 `json:"previews"`
+	Failed         int64 `json:"failed"`
+	UniqueVisitors int64 `json:"unique_visitors"`
 }
 
 type ShareBasicCreateRequest struct {
 	UserRepositoryIdentity string `json:"user_repository_identity"`
 	ExpiredTime            int    `json:"expired_time"`
+	DownloadLimit          int    `json:"download_limit,optional"` // Maximum number of downloads, 0 for unlimited
 }
 
 type ShareBasicCreateReply struct {
@@ -215,13 +344,140 @@ type UserRegisterReply struct {
 }
 
 type LoginRequest struct {
-	Name     string `json:"name"`
-	Password string `json:"password"`
+	Name       string `json:"name"` // Username or email
+	Password   string `json:"password"`
+	DeviceName string `json:"device_name,optional"` // Shown in the session list, derived from the user agent when empty
 }
 
 type LoginReply struct {
 	Token        string `json:"token"`
 	RefreshToken string `json:"refresh_token"`
+	// With 2FA enabled the password step only returns a challenge token,
+	// which is exchanged for tokens at /user/login/2fa
+	TwoFactorRequired bool   `json:"two_factor_required,omitempty"`
+	ChallengeToken    string `json:"challenge_token,omitempty"`
+	PasskeyAvailable  bool   `json:"passkey_available,omitempty"` // The challenge may also be met with a passkey
+}
+
+type LoginTwoFactorRequest struct {
+	ChallengeToken string `json:"challenge_token"`
+	Code           string `json:"code"` // TOTP or recovery code
+	DeviceName     string `json:"device_name,optional"`
+}
+
+type LoginEmailRequest struct {
+	// Either the token from the emailed link, or the email with its code
+	Token      string `json:"token,optional"`
+	Email      string `json:"email,optional"`
+	Code       string `json:"code,optional"`
+	DeviceName string `json:"device_name,optional"`
+}
+
+type PasskeyLoginBeginRequest struct {
+	// Set when the passkey is the second factor after a password; empty for
+	// a passwordless passkey login
+	ChallengeToken string `json:"challenge_token,optional"`
+}
+
+type PasskeyLoginBeginReply struct {
+	Session          string   `json:"session"` // Sent back with the assertion
+	Challenge        string   `json:"challenge"`
+	RPID             string   `json:"rp_id"`
+	AllowCredentials []string `json:"allow_credentials"` // Empty lets the browser offer any passkey for this site
+	UserVerification string   `json:"user_verification"`
+	Timeout          int64    `json:"timeout"` // Milliseconds
+}
+
+type PasskeyLoginFinishRequest struct {
+	Session string `json:"session"`
+	PasskeyAssertion
+	DeviceName string `json:"device_name,optional"`
+}
+
+// PasskeyAssertion is a navigator.credentials.get() result, binary fields base64url encoded
+type PasskeyAssertion struct {
+	CredentialID      string `json:"credential_id"`
+	ClientDataJSON    string `json:"client_data_json"`
+	AuthenticatorData string `json:"authenticator_data"`
+	Signature         string `json:"signature"`
+	UserHandle        string `json:"user_handle,optional"`
+}
+
+type OIDCLoginStartRequest struct {
+	DeviceName string `json:"device_name,optional"` // Applied to the session once the callback completes
+}
+
+type OIDCLoginStartReply struct {
+	AuthorizationURL string `json:"authorization_url"` // Send the browser here
+	State            string `json:"state"`
+}
+
+type OIDCLoginCallbackRequest struct {
+	Code  string `json:"code"`
+	State string `json:"state"`
+}
+
+type UserSessionListRequest struct {
+}
+
+type UserSessionListReply struct {
+	List []*UserSessionItem `json:"list"`
+}
+
+type UserSessionItem struct {
+	Identity   string `json:"identity"`
+	DeviceName string `json:"device_name"`
+	IP         string `json:"ip"`
+	UserAgent  string `json:"user_agent"`
+	LastUsedAt string `json:"last_used_at"`
+	CreatedAt  string `json:"created_at"`
+	Current    bool   `json:"current"` // The session making this request
+}
+
+type UserSessionRevokeRequest struct {
+	Identity string `json:"identity"`
+}
+
+type UserSessionRevokeReply struct {
+}
+
+type UserSessionRevokeOthersRequest struct {
+}
+
+type UserSessionRevokeOthersReply struct {
+	Revoked int64 `json:"revoked"`
+}
+
+type TwoFactorSetupRequest struct {
+}
+
+type TwoFactorSetupReply struct {
+	Secret          string `json:"secret"`
+	ProvisioningURI string `json:"provisioning_uri"` // otpauth:// URI, usually shown as a QR code
+}
+
+type TwoFactorEnableRequest struct {
+	Code string `json:"code"` // First code from the authenticator app
+}
+
+type TwoFactorEnableReply struct {
+	RecoveryCodes []string `json:"recovery_codes"` // Shown once; only hashes are kept
+}
+
+type TwoFactorDisableRequest struct {
+	Password string `json:"password"`
+	Code     string `json:"code"` // TOTP or recovery code
+}
+
+type TwoFactorDisableReply struct {
+}
+
+type TwoFactorRecoveryRegenerateRequest struct {
+	Code string `json:"code"` // TOTP or recovery code
+}
+
+type TwoFactorRecoveryRegenerateReply struct {
+	RecoveryCodes []string `json:"recovery_codes"`
 }
 
 type UserDetailRequest struct {
@@ -229,10 +485,50 @@ type UserDetailRequest struct {
 }
 
 type UserDetailReply struct {
-	Name        string `json:"name"`
-	Email       string `json:"email"`
-	NowVolume   int64  `json:"now_volume"`
-	TotalVolume int64  `json:"total_volume"`
+	Name                string `json:"name"`
+	Email               string `json:"email"`
+	NowVolume           int64  `json:"now_volume"`
+	TotalVolume         int64  `json:"total_volume"`
+	DiscoverableByName  bool   `json:"discoverable_by_name"`
+	DiscoverableByEmail bool   `json:"discoverable_by_email"`
+	TwoFactorEnabled    bool   `json:"two_factor_enabled"`
+	DeleteAfter         string `json:"delete_after,omitempty"` // Set while an account deletion is pending
+}
+
+type UserSearchRequest struct {
+	Keyword string `json:"keyword"` // Username prefix, or an exact email
+	Page    int    `json:"page,optional"`
+	Size    int    `json:"size,optional"`
+}
+
+type UserSearchReply struct {
+	List []*UserDirectoryItem `json:"list"`
+}
+
+type UserDirectoryItem struct {
+	Identity       string `json:"identity"`
+	Name           string `json:"name"`
+	IsFriend       bool   `json:"is_friend"`
+	RequestPending bool   `json:"request_pending"` // A friend request between the two users is waiting for an answer
+	MutualCount    int64  `json:"mutual_count"`    // Friends in common, only filled in by suggestions
+}
+
+type UserSuggestionListRequest struct {
+	Size int `json:"size,optional"`
+}
+
+type UserSuggestionListReply struct {
+	List []*UserDirectoryItem `json:"list"`
+}
+
+type UserPrivacyUpdateRequest struct {
+	DiscoverableByName  *bool `json:"discoverable_by_name,optional"`
+	DiscoverableByEmail *bool `json:"discoverable_by_email,optional"`
+}
+
+type UserPrivacyUpdateReply struct {
+	DiscoverableByName  bool `json:"discoverable_by_name"`
+	DiscoverableByEmail bool `json:"discoverable_by_email"`
 }
 
 type MailCodeSendRequest struct {
@@ -249,6 +545,12 @@ type MailCodeSendPasswordUpdateRequest struct {
 type MailCodeSendPasswordUpdateReply struct {
 }
 
+type MailCodeSendReauthRequest struct {
+}
+
+type MailCodeSendReauthReply struct {
+}
+
 type MailCodeSendPasswordResetRequest struct {
 	Email string `json:"email"`
 }
@@ -256,10 +558,18 @@ type MailCodeSendPasswordResetRequest struct {
 type MailCodeSendPasswordResetReply struct {
 }
 
+type MailCodeSendLoginRequest struct {
+	Email string `json:"email"`
+}
+
+type MailCodeSendLoginReply struct {
+}
+
 type UserPasswordResetRequest struct {
-	Email       string `json:"email"`
-	Code        string `json:"code"`
-	NewPassword string `json:"new_password"`
+	Email         string `json:"email"`
+	Code          string `json:"code"`
+	NewPassword   string `json:"new_password"`
+	TwoFactorCode string `json:"two_factor_code,optional"` // TOTP or recovery code, required when 2FA is enabled
 }
 
 type UserPasswordResetReply struct {
@@ -272,9 +582,113 @@ type UserLogoutRequest struct {
 type UserLogoutReply struct {
 }
 
+type UserLogoutAllRequest struct {
+}
+
+type UserLogoutAllReply struct {
+}
+
+type PasskeyRegisterBeginRequest struct {
+	Password      string `json:"password,optional"`
+	EmailCode     string `json:"email_code,optional"`      // From /mail/code/send/reauth, instead of the password
+	TwoFactorCode string `json:"two_factor_code,optional"` // TOTP or recovery code, required when 2FA is enabled
+}
+
+type PasskeyRegisterBeginReply struct {
+	Challenge          string   `json:"challenge"`
+	RPID               string   `json:"rp_id"`
+	RPName             string   `json:"rp_name"`
+	UserID             string   `json:"user_id"` // base64url user handle
+	UserName           string   `json:"user_name"`
+	UserDisplayName    string   `json:"user_display_name"`
+	Algorithms         []int    `json:"algorithms"`          // COSE algorithm IDs for pubKeyCredParams
+	ExcludeCredentials []string `json:"exclude_credentials"` // Already registered credential IDs
+	Timeout            int64    `json:"timeout"`             // Milliseconds
+}
+
+type PasskeyRegisterFinishRequest struct {
+	Name              string   `json:"name,optional"`
+	CredentialID      string   `json:"credential_id"`
+	ClientDataJSON    string   `json:"client_data_json"`
+	AttestationObject string   `json:"attestation_object"`
+	Transports        []string `json:"transports,optional"`
+}
+
+type PasskeyRegisterFinishReply struct {
+	Item *PasskeyItem `json:"item"`
+}
+
+type PasskeyListRequest struct {
+}
+
+type PasskeyListReply struct {
+	List []*PasskeyItem `json:"list"`
+}
+
+type PasskeyItem struct {
+	Identity       string `json:"identity"`
+	Name           string `json:"name"`
+	BackupEligible bool   `json:"backup_eligible"` // Synced passkey rather than a device-bound key
+	CreatedAt      string `json:"created_at"`
+	LastUsedAt     string `json:"last_used_at,omitempty"`
+}
+
+type PasskeyRenameRequest struct {
+	Identity string `json:"identity"`
+	Name     string `json:"name"`
+}
+
+type PasskeyRenameReply struct {
+}
+
+type PasskeyDeleteRequest struct {
+	Identity      string `json:"identity"`
+	Password      string `json:"password,optional"`
+	EmailCode     string `json:"email_code,optional"`      // From /mail/code/send/reauth, instead of the password
+	TwoFactorCode string `json:"two_factor_code,optional"` // TOTP or recovery code, required when 2FA is enabled
+}
+
+type PasskeyDeleteReply struct {
+}
+
+type AccessTokenCreateRequest struct {
+	Name          string   `json:"name"`
+	Scopes        []string `json:"scopes"`                   // files:read, files:write, shares:manage, friends
+	ExpiresInDays int      `json:"expires_in_days,optional"` // 0 = never expires
+}
+
+type AccessTokenCreateReply struct {
+	Token string           `json:"token"` // Shown once; only a hash is kept
+	Item  *AccessTokenItem `json:"item"`
+}
+
+type AccessTokenListRequest struct {
+}
+
+type AccessTokenListReply struct {
+	List []*AccessTokenItem `json:"list"`
+}
+
+type AccessTokenItem struct {
+	Identity   string   `json:"identity"`
+	Name       string   `json:"name"`
+	Prefix     string   `json:"prefix"`
+	Scopes     []string `json:"scopes"`
+	ExpiresAt  string   `json:"expires_at"`   // Empty when the token never expires
+	LastUsedAt string   `json:"last_used_at"` // Empty until first use
+	CreatedAt  string   `json:"created_at"`
+}
+
+type AccessTokenRevokeRequest struct {
+	Identity string `json:"identity"`
+}
+
+type AccessTokenRevokeReply struct {
+}
+
 // Friend request types
 type FriendRequestSendRequest struct {
-	ToUserIdentity string `json:"to_user_identity"` // Email or user identity
+	ToUserIdentity string `json:"to_user_identity"` // Username, email or identity from /user/search
 	Message        string `json:"message,optional"`
 }
 
@@ -326,11 +740,37 @@ type FriendItem struct {
 	CreatedAt    string `json:"created_at"`
 }
 
+type FriendRemoveRequest struct {
+	UserIdentity string `json:"user_identity"`
+}
+
+type FriendRemoveReply struct {
+}
+
+type FriendBlockRequest struct {
+	UserIdentity string `json:"user_identity"`
+}
+
+type FriendBlockReply struct {
+}
+
+type FriendUnblockRequest struct {
+	UserIdentity string `json:"user_identity"`
+}
+
+type FriendUnblockReply struct {
+}
+
+type FriendBlockListRequest struct {
+}
+
 // Friend share types
 type FriendShareCreateRequest struct {
 	ToUserIdentity         string `json:"to_user_identity"`         // Friend's user identity
-	UserRepositoryIdentity string `json:"user_repository_identity"` // File to share
+	UserRepositoryIdentity string `json:"user_repository_identity"` // File or folder to share
 	Message                string `json:"message,optional"`
+	Permission             string `json:"permission,optional"`   // view (default), edit
+	ExpiredTime            int    `json:"expired_time,optional"` // Seconds until the share expires, 0 = never
 }
 
 type FriendShareCreateReply struct {
@@ -338,7 +778,12 @@ type FriendShareCreateReply struct {
 }
 
 type FriendShareListRequest struct {
-	Type string `json:"type,optional"` // sent, received, all
+	Type             string `json:"type,optional"`               // sent, received, all
+	State            string `json:"state,optional"`              // Received shares: inbox (default), archived, dismissed, all
+	FromUserIdentity string `json:"from_user_identity,optional"` // Only shares from this sender
+	Read             string `json:"read,optional"`               // read, unread
+	StartDate        string `json:"start_date,optional"`         // YYYY-MM-DD, inclusive
+	EndDate          string `json:"end_date,optional"`           // YYYY-MM-DD, inclusive
 }
 
 type FriendShareListReply struct {
@@ -356,9 +801,16 @@ type FriendShareItem struct {
 	FileName               string `json:"file_name"`
 	FileExt                string `json:"file_ext"`
 	FileSize               int64  `json:"file_size"`
-	Path                   string `json:"path"` // Download URL
+	IsFolder               bool   `json:"is_folder"`
+	Permission             string `json:"permission"` // view, edit
+	Path                   string `json:"path"`       // Download URL, empty for folders
 	Message                string `json:"message"`
 	IsRead                 bool   `json:"is_read"`
+	State                  string `json:"state"`      // Recipient's inbox state: inbox, archived, dismissed
+	ExpiresAt              string `json:"expires_at"` // Empty when the share never expires
+	Expired                bool   `json:"expired"`
+	OpenedAt               string `json:"opened_at"` // Empty until the recipient opens the share
+	SavedAt                string `json:"saved_at"`  // Empty until the recipient saves the share
 	CreatedAt              string `json:"created_at"`
 }
 
@@ -371,6 +823,7 @@ type FriendShareMarkReadReply struct {
 
 type FriendShareDownloadRequest struct {
 	ShareIdentity string `json:"share_identity,optional"`
+	ItemIdentity  string `json:"item,optional"` // File inside a shared folder
 }
 
 type FriendShareDownloadReply struct {
@@ -378,6 +831,7 @@ type FriendShareDownloadReply struct {
 
 type FriendShareSaveRequest struct {
 	ShareIdentity string `json:"share_identity"`
+	ItemIdentity  string `json:"item,optional"` // File inside a shared folder
 	ParentId      int64  `json:"parent_id"`
 }
 
@@ -385,6 +839,320 @@ type FriendShareSaveReply struct {
 	Identity string `json:"identity"`
 }
 
+type FriendShareInviteCreateRequest struct {
+	Email                  string `json:"email"`                    // Recipient address; registered users are invited in-app
+	UserRepositoryIdentity string `json:"user_repository_identity"` // File or folder to share
+	Message                string `json:"message,optional"`
+	Permission             string `json:"permission,optional"`  // view (default), edit
+	ExpireDays             int    `json:"expire_days,optional"` // Default: 7
+}
+
+type FriendShareInviteCreateReply struct {
+	Identity  string `json:"identity"`
+	ExpiresAt string `json:"expires_at"`
+}
+
+type FriendShareInviteListRequest struct {
+	Status string `json:"status,optional"` // pending, accepted, revoked
+}
+
+type FriendShareInviteListReply struct {
+	List []*FriendShareInviteItem `json:"list"`
+}
+
+type FriendShareInviteItem struct {
+	Identity               string `json:"identity"`
+	Email                  string `json:"email"`
+	UserRepositoryIdentity string `json:"user_repository_identity"`
+	FileName               string `json:"file_name"`
+	Message                string `json:"message"`
+	Status                 string `json:"status"` // pending, accepted, revoked, expired
+	ToUserIdentity         string `json:"to_user_identity"`
+	ExpiresAt              string `json:"expires_at"`
+	CreatedAt              string `json:"created_at"`
+}
+
+type FriendShareInviteRevokeRequest struct {
+	Identity string `json:"identity"`
+}
+
+type FriendShareInviteRevokeReply struct {
+}
+
+type FriendShareInviteReceivedRequest struct {
+}
+
+type FriendShareInviteReceivedReply struct {
+	List []*FriendShareInviteReceivedItem `json:"list"`
+}
+
+type FriendShareInviteReceivedItem struct {
+	Identity         string `json:"identity"`
+	FromUserIdentity string `json:"from_user_identity"`
+	FromUserName     string `json:"from_user_name"`
+	FileName         string `json:"file_name"`
+	Message          string `json:"message"`
+	Permission       string `json:"permission"`
+	ExpiresAt        string `json:"expires_at"`
+	CreatedAt        string `json:"created_at"`
+}
+
+type FriendShareInviteAcceptRequest struct {
+	Identity string `json:"identity"`
+}
+
+type FriendShareInviteAcceptReply struct {
+	Identity string `json:"identity"` // The friend share the invite turned into
+}
+
+type FriendShareFolderListRequest struct {
+	ShareIdentity string `json:"share_identity"`
+	Identity      string `json:"identity,optional"` // Sub-folder of the share, default: the shared folder
+	Page          int    `json:"page,optional"`
+	Size          int    `json:"size,optional"`
+}
+
+type FriendShareFolderCreateRequest struct {
+	ShareIdentity  string `json:"share_identity"`
+	ParentIdentity string `json:"parent_identity,optional"` // Default: the shared folder
+	Name           string `json:"name"`
+}
+
+type FriendShareFolderCreateReply struct {
+	Identity string `json:"identity"`
+}
+
+type FriendShareFileSaveRequest struct {
+	ShareIdentity      string `json:"share_identity"`
+	ParentIdentity     string `json:"parent_identity,optional"` // Default: the shared folder
+	RepositoryIdentity string `json:"repository_identity"`      // Returned by /file/upload
+	Name               string `json:"name"`
+	Ext                string `json:"ext"`
+}
+
+type FriendShareFileSaveReply struct {
+}
+
+type FriendShareFileRenameRequest struct {
+	ShareIdentity string `json:"share_identity"`
+	Identity      string `json:"identity"`
+	Name          string `json:"name"`
+}
+
+type FriendShareFileRenameReply struct {
+}
+
+type FriendShareFileDeleteRequest struct {
+	ShareIdentity string `json:"share_identity"`
+	Identity      string `json:"identity"`
+}
+
+type FriendShareFileDeleteReply struct {
+}
+
+type FriendSharePermissionUpdateRequest struct {
+	Identity   string `json:"identity"`
+	Permission string `json:"permission"` // view, edit
+}
+
+type FriendSharePermissionUpdateReply struct {
+}
+
+type FriendShareExpiryUpdateRequest struct {
+	Identity    string `json:"identity"`
+	ExpiredTime int    `json:"expired_time"` // Seconds from now, 0 = never expires
+}
+
+type FriendShareExpiryUpdateReply struct {
+	ExpiresAt string `json:"expires_at"`
+}
+
+type FriendShareStateUpdateRequest struct {
+	Identity string `json:"identity"`
+	State    string `json:"state"` // inbox, archived, dismissed
+}
+
+type FriendShareStateUpdateReply struct {
+}
+
+type FriendShareRevokeRequest struct {
+	Identity string `json:"identity"`
+}
+
+type FriendShareRevokeReply struct {
+}
+
+// Team Space Types
+type SpaceCreateRequest struct {
+	Name string `json:"name"`
+}
+
+type SpaceCreateReply struct {
+	Identity string `json:"identity"`
+}
+
+type SpaceListRequest struct {
+}
+
+type SpaceListReply struct {
+	List []*SpaceItem `json:"list"`
+}
+
+type SpaceItem struct {
+	Identity      string `json:"identity"`
+	Name          string `json:"name"`
+	OwnerIdentity string `json:"owner_identity"`
+	OwnerName     string `json:"owner_name"`
+	Role          string `json:"role"` // Caller's role: owner, editor, viewer
+	CreatedAt     string `json:"created_at"`
+}
+
+type SpaceMemberListRequest struct {
+	SpaceIdentity string `json:"space_identity"`
+}
+
+type SpaceMemberListReply struct {
+	List []*SpaceMemberItem `json:"list"`
+}
+
+type SpaceMemberItem struct {
+	UserIdentity string `json:"user_identity"`
+	UserName     string `json:"user_name"`
+	Role         string `json:"role"`
+	CreatedAt    string `json:"created_at"`
+}
+
+type SpaceMemberAddRequest struct {
+	SpaceIdentity string `json:"space_identity"`
+	UserIdentity  string `json:"user_identity"` // Invited; joins on accepting
+	Role          string `json:"role"`          // editor, viewer
+}
+
+type SpaceMemberAddReply struct {
+}
+
+type SpaceMemberUpdateRequest struct {
+	SpaceIdentity string `json:"space_identity"`
+	UserIdentity  string `json:"user_identity"`
+	Role          string `json:"role"` // editor, viewer
+}
+
+type SpaceMemberUpdateReply struct {
+}
+
+type SpaceMemberRemoveRequest struct {
+	SpaceIdentity string `json:"space_identity"`
+	UserIdentity  string `json:"user_identity"` // Members may remove themselves to leave
+}
+
+type SpaceMemberRemoveReply struct {
+}
+
+type SpaceDeleteRequest struct {
+	SpaceIdentity string `json:"space_identity"`
+}
+
+type SpaceDeleteReply struct {
+}
+
+type SpaceInviteListRequest struct {
+}
+
+type SpaceInviteListReply struct {
+	List []*SpaceInviteItem `json:"list"`
+}
+
+type SpaceInviteItem struct {
+	Identity        string `json:"identity"`
+	SpaceIdentity   string `json:"space_identity"`
+	SpaceName       string `json:"space_name"`
+	InviterIdentity string `json:"inviter_identity"`
+	InviterName     string `json:"inviter_name"`
+	Role            string `json:"role"`
+	ExpiresAt       string `json:"expires_at"`
+	CreatedAt       string `json:"created_at"`
+}
+
+type SpaceInviteRespondRequest struct {
+	Identity string `json:"identity"`
+	Action   string `json:"action"` // accept, decline
+}
+
+type SpaceInviteRespondReply struct {
+}
+
+// Event Stream Types
+type EventTicketRequest struct {
+}
+
+type EventTicketReply struct {
+	Ticket    string `json:"ticket"`     // Pass as the ticket query parameter of /events/stream
+	ExpiresIn int    `json:"expires_in"` // Seconds left to open the stream; the ticket works once
+}
+
+// Notification Types
+type NotificationListRequest struct {
+	UnreadOnly bool `json:"unread_only,optional"`
+	Page       int  `json:"page,optional"`
+	Size       int  `json:"size,optional"`
+}
+
+type NotificationListReply struct {
+	List        []*NotificationItem `json:"list"`
+	Count       int64               `json:"count"`
+	UnreadCount int64               `json:"unread_count"`
+}
+
+type NotificationItem struct {
+	Identity  string `json:"identity"`
+	Type      string `json:"type"`
+	Title     string `json:"title"`
+	Body      string `json:"body"`
+	Data      string `json:"data"` // JSON payload
+	IsRead    bool   `json:"is_read"`
+	CreatedAt string `json:"created_at"`
+}
+
+type NotificationReadRequest struct {
+	Identities []string `json:"identities"`
+}
+
+type NotificationReadReply struct {
+}
+
+type NotificationReadAllRequest struct {
+}
+
+type NotificationReadAllReply struct {
+}
+
+type NotificationDeleteRequest struct {
+	Identities []string `json:"identities"`
+}
+
+type NotificationDeleteReply struct {
+}
+
+type NotificationPreferenceListRequest struct {
+}
+
+type NotificationPreferenceListReply struct {
+	List []*NotificationPreferenceItem `json:"list"`
+}
+
+type NotificationPreferenceItem struct {
+	Type    string `json:"type"`
+	Channel string `json:"channel"` // in_app, email, off
+}
+
+type NotificationPreferenceUpdateRequest struct {
+	Type    string `json:"type"`
+	Channel string `json:"channel"` // in_app, email, off
+}
+
+type NotificationPreferenceUpdateReply struct {
+}
+
 // Storage Purchase Types
 type StoragePurchaseCreateRequest struct {
 	StorageAmount int64  `json:"storage_amount"`    // Storage capacity in bytes (e.g., 10737418240 for 10GB)
@@ -424,3 +1192,220 @@ type StoragePurchaseSyncReply struct {
 	StorageAmount int64  `json:"storage_amount"` // Storage capacity added
 	Message       string `json:"message"`
 }
+
+type AdminUserItem struct {
+	Identity         string `json:"identity"`
+	Name             string `json:"name"`
+	Email            string `json:"email"`
+	Role             string `json:"role"` // user, support or admin
+	NowVolume        int64  `json:"now_volume"`
+	TotalVolume      int64  `json:"total_volume"`
+	TwoFactorEnabled bool   `json:"two_factor_enabled"`
+	SuspendedAt      string `json:"suspended_at,omitempty"`
+	SuspendReason    string `json:"suspend_reason,omitempty"`
+	DeleteAfter      string `json:"delete_after,omitempty"`
+	CreatedAt        string `json:"created_at"`
+}
+
+type AdminUserListRequest struct {
+	Keyword   string `json:"keyword,optional"`   // Username prefix, email prefix or exact identity
+	Role      string `json:"role,optional"`      // Only users with this role
+	Suspended bool   `json:"suspended,optional"` // Only suspended users
+	Page      int    `json:"page,optional"`
+	Size      int    `json:"size,optional"`
+}
+
+type AdminUserListReply struct {
+	List  []*AdminUserItem `json:"list"`
+	Count int64            `json:"count"`
+}
+
+type AdminUserDetailRequest struct {
+	Identity string `json:"identity"`
+}
+
+type AdminUserDetailReply struct {
+	User         *AdminUserItem `json:"user"`
+	FileCount    int64          `json:"file_count"`
+	ShareCount   int64          `json:"share_count"`
+	SessionCount int64          `json:"session_count"` // Active sessions
+	OrderCount   int64          `json:"order_count"`
+}
+
+type AdminUserVolumeUpdateRequest struct {
+	Identity    string `json:"identity"`
+	TotalVolume int64  `json:"total_volume"` // New quota in bytes
+	Note        string `json:"note,optional"`
+}
+
+type AdminUserVolumeUpdateReply struct {
+	User *AdminUserItem `json:"user"`
+}
+
+type AdminUserSuspendRequest struct {
+	Identity string `json:"identity"`
+	Reason   string `json:"reason"`
+}
+
+type AdminUserSuspendReply struct {
+	User *AdminUserItem `json:"user"`
+}
+
+type AdminUserUnsuspendRequest struct {
+	Identity string `json:"identity"`
+}
+
+type AdminUserUnsuspendReply struct {
+	User *AdminUserItem `json:"user"`
+}
+
+type AdminUserLogoutRequest struct {
+	Identity string `json:"identity"`
+}
+
+type AdminUserLogoutReply struct {
+}
+
+type AdminUserRoleUpdateRequest struct {
+	Identity string `json:"identity"`
+	Role     string `json:"role"` // user, support or admin
+}
+
+type AdminUserRoleUpdateReply struct {
+	User *AdminUserItem `json:"user"`
+}
+
+type AdminShareListRequest struct {
+	UserIdentity string `json:"user_identity,optional"` // Only shares created by this user
+	Page         int    `json:"page,optional"`
+	Size         int    `json:"size,optional"`
+}
+
+type AdminShareListReply struct {
+	List  []*AdminShareItem `json:"list"`
+	Count int64             `json:"count"`
+}
+
+type AdminShareItem struct {
+	Identity           string `json:"identity"`
+	UserIdentity       string `json:"user_identity"`
+	UserName           string `json:"user_name"`
+	Name               string `json:"name"` // File name
+	RepositoryIdentity string `json:"repository_identity"`
+	Size               int64  `json:"size"`
+	ExpiredTime        int    `json:"expired_time"`
+	ClickNum           int    `json:"click_num"`
+	DownloadLimit      int    `json:"download_limit"`
+	DownloadNum        int    `json:"download_num"`
+	CreatedAt          string `json:"created_at"`
+}
+
+type AdminShareTakedownRequest struct {
+	Identity string `json:"identity"`
+	Reason   string `json:"reason"`
+}
+
+type AdminShareTakedownReply struct {
+}
+
+type AdminOrderListRequest struct {
+	UserIdentity string `json:"user_identity,optional"`
+	Status       string `json:"status,optional"` // pending, paid, failed, refunded, or empty for all
+	Page         int    `json:"page,optional"`
+	Size         int    `json:"size,optional"`
+}
+
+type AdminOrderListReply struct {
+	List  []*AdminOrderItem `json:"list"`
+	Count int64             `json:"count"`
+}
+
+type AdminOrderItem struct {
+	Identity              string `json:"identity"`
+	UserIdentity          string `json:"user_identity"`
+	StripeSessionID       string `json:"stripe_session_id"`
+	StripePaymentIntentID string `json:"stripe_payment_intent_id"`
+	StorageAmount         int64  `json:"storage_amount"` // Bytes
+	PriceAmount           int64  `json:"price_amount"`   // Cents
+	Currency              string `json:"currency"`
+	Status                string `json:"status"`
+	CreatedAt             string `json:"created_at"`
+	UpdatedAt             string `json:"updated_at"`
+}
+
+type AdminActionListRequest struct {
+	ActorIdentity  string `json:"actor_identity,optional"`
+	TargetIdentity string `json:"target_identity,optional"`
+	Page           int    `json:"page,optional"`
+	Size           int    `json:"size,optional"`
+}
+
+type AdminActionListReply struct {
+	List  []*AdminActionItem `json:"list"`
+	Count int64              `json:"count"`
+}
+
+type AdminActionItem struct {
+	ActorIdentity  string `json:"actor_identity"`
+	Action         string `json:"action"`
+	TargetType     string `json:"target_type"`
+	TargetIdentity string `json:"target_identity"`
+	Detail         string `json:"detail"` // JSON
+	IP             string `json:"ip"`
+	CreatedAt      string `json:"created_at"`
+}
+
+type AuditLogItem struct {
+	ActorIdentity   string `json:"actor_identity"`
+	SubjectIdentity string `json:"subject_identity"`
+	Action          string `json:"action"`
+	TargetType      string `json:"target_type"`
+	TargetIdentity  string `json:"target_identity"`
+	Success         bool   `json:"success"`
+	Reason          string `json:"reason,omitempty"`
+	Detail          string `json:"detail,omitempty"` // JSON
+	IP              string `json:"ip"`
+	UserAgent       string `json:"user_agent"`
+	CreatedAt       string `json:"created_at"`
+}
+
+type AuditLogListReply struct {
+	List  []*AuditLogItem `json:"list"`
+	Count int64           `json:"count"`
+}
+
+// AuditLogFilter narrows the caller's own audit log
+type AuditLogFilter struct {
+	Action    string `json:"action,optional"`     // e.g. login, file.delete
+	StartDate string `json:"start_date,optional"` // YYYY-MM-DD, inclusive
+	EndDate   string `json:"end_date,optional"`   // YYYY-MM-DD, inclusive
+}
+
+type AuditLogListRequest struct {
+	AuditLogFilter
+	Page int `json:"page,optional"`
+	Size int `json:"size,optional"`
+}
+
+type AuditLogExportRequest struct {
+	AuditLogFilter
+}
+
+// AdminAuditLogFilter narrows the audit log of every account
+type AdminAuditLogFilter struct {
+	AuditLogFilter
+	ActorIdentity   string `json:"actor_identity,optional"`
+	SubjectIdentity string `json:"subject_identity,optional"`
+	TargetIdentity  string `json:"target_identity,optional"`
+	IP              string `json:"ip,optional"`
+}
+
+type AdminAuditLogListRequest struct {
+	AdminAuditLogFilter
+	Page int `json:"page,optional"`
+	Size int `json:"size,optional"`
+}
+
+type AdminAuditLogExportRequest struct {
+	AdminAuditLogFilter
+}
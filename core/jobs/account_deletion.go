@@ -0,0 +1,38 @@
+package jobs
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"cloud-dist/core/account"
+	"cloud-dist/core/helper"
+	"cloud-dist/core/models"
+)
+
+// deleteAccounts removes accounts whose deletion grace period has ended.
+func (r *Runner) deleteAccounts(ctx context.Context) error {
+	var users []string
+	err := r.svcCtx.DB.WithContext(ctx).Model(&models.UserBasic{}).
+		Where("delete_after IS NOT NULL AND delete_after <= ?", time.Now()).
+		Limit(20).
+		Pluck("identity", &users).Error
+	if err != nil {
+		return err
+	}
+	for _, identity := range users {
+		if ctx.Err() != nil {
+			return nil
+		}
+		// Outstanding tokens stop working before the rows disappear
+		if err = r.svcCtx.TokenVersions.Bump(ctx, identity); err != nil {
+			return err
+		}
+		if err = account.Delete(ctx, r.svcCtx.DB, identity, helper.S3Delete); err != nil {
+			log.Printf("[Jobs] Deleting account %s failed: %v", identity, err)
+			continue
+		}
+		log.Printf("[Jobs] Deleted account %s", identity)
+	}
+	return nil
+}
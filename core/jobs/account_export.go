@@ -0,0 +1,129 @@
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"time"
+
+	"cloud-dist/core/account"
+	"cloud-dist/core/define"
+	"cloud-dist/core/helper"
+	"cloud-dist/core/models"
+)
+
+const (
+	exportBatch = 3
+	// exportStaleAfter marks a running export as failed, e.g. after a restart
+	exportStaleAfter = time.Hour
+)
+
+// buildAccountExports builds pending data exports, one archive at a time, and
+// removes archives past their expiry.
+func (r *Runner) buildAccountExports(ctx context.Context) error {
+	db := r.svcCtx.DB.WithContext(ctx)
+	now := time.Now()
+
+	if err := db.Model(&models.AccountExport{}).
+		Where("status = ? AND started_at < ?", define.ExportStatusRunning, now.Add(-exportStaleAfter)).
+		Updates(map[string]interface{}{"status": define.ExportStatusFailed, "error": "interrupted", "finished_at": now}).Error; err != nil {
+		return err
+	}
+
+	var expired []*models.AccountExport
+	if err := db.Where("status = ? AND expires_at < ?", define.ExportStatusReady, now).Find(&expired).Error; err != nil {
+		return err
+	}
+	for _, e := range expired {
+		if err := helper.S3Delete(e.Path); err != nil {
+			log.Printf("[Jobs] Failed to delete expired export %s: %v", e.Identity, err)
+			continue
+		}
+		db.Model(e).Updates(map[string]interface{}{"status": define.ExportStatusExpired, "path": ""})
+	}
+
+	var pending []*models.AccountExport
+	if err := db.Where("status = ?", define.ExportStatusPending).Order("id").Limit(exportBatch).Find(&pending).Error; err != nil {
+		return err
+	}
+	for _, e := range pending {
+		if ctx.Err() != nil {
+			return nil
+		}
+		// Claim the export so another instance does not build it too
+		result := db.Model(&models.AccountExport{}).
+			Where("id = ? AND status = ?", e.ID, define.ExportStatusPending).
+			Updates(map[string]interface{}{"status": define.ExportStatusRunning, "started_at": time.Now()})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			continue
+		}
+		r.buildAccountExport(ctx, e)
+	}
+	return nil
+}
+
+func (r *Runner) buildAccountExport(ctx context.Context, e *models.AccountExport) {
+	db := r.svcCtx.DB.WithContext(ctx)
+	key := fmt.Sprintf("exports/%s/%s.zip", e.UserIdentity, e.Identity)
+	size, err := writeExport(ctx, r, e.UserIdentity, key)
+	now := time.Now()
+	if err != nil {
+		log.Printf("[Jobs] Export %s failed: %v", e.Identity, err)
+		db.Model(e).Updates(map[string]interface{}{"status": define.ExportStatusFailed, "error": err.Error(), "finished_at": now})
+		return
+	}
+	expires := now.Add(define.ExportRetention)
+	db.Model(e).Updates(map[string]interface{}{
+		"status":      define.ExportStatusReady,
+		"path":        key,
+		"size":        size,
+		"finished_at": now,
+		"expires_at":  expires,
+	})
+	log.Printf("[Jobs] Export %s ready (%d bytes)", e.Identity, size)
+
+	user := new(models.UserBasic)
+	if err = db.Where("identity = ?", e.UserIdentity).First(user).Error; err == nil && user.Email != "" {
+		if err = helper.MailSendNotification(user.Email, "Your data export is ready",
+			"Your CloudDist data export can be downloaded from your account settings until "+expires.Format(define.Datetime)+"."); err != nil {
+			log.Printf("[Jobs] Export mail failed: %v", err)
+		}
+	}
+}
+
+// writeExport builds the archive in a temporary file, then uploads it
+func writeExport(ctx context.Context, r *Runner, userIdentity, key string) (int64, error) {
+	f, err := os.CreateTemp("", "account-export-*.zip")
+	if err != nil {
+		return 0, err
+	}
+	defer os.Remove(f.Name())
+	defer f.Close()
+
+	open := func(key string) (io.ReadCloser, error) {
+		out, err := helper.S3GetObject(key, "")
+		if err != nil {
+			return nil, err
+		}
+		return out.Body, nil
+	}
+	if err = account.WriteArchive(ctx, r.svcCtx.DB, userIdentity, f, open); err != nil {
+		return 0, err
+	}
+	size, err := f.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return 0, err
+	}
+	if _, err = f.Seek(0, io.SeekStart); err != nil {
+		return 0, err
+	}
+	if err = helper.S3PutObject(key, f, size, "application/zip"); err != nil {
+		return 0, err
+	}
+	return size, nil
+}
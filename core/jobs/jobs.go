@@ -0,0 +1,95 @@
+// Package jobs runs periodic maintenance tasks next to the HTTP server.
+package jobs
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"cloud-dist/core/svc"
+)
+
+type job struct {
+	name     string
+	interval time.Duration
+	run      func(ctx context.Context) error
+}
+
+// Runner executes each registered job on its own ticker until stopped.
+type Runner struct {
+	svcCtx *svc.ServiceContext
+	jobs   []job
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+func NewRunner(svcCtx *svc.ServiceContext) *Runner {
+	r := &Runner{svcCtx: svcCtx}
+	r.jobs = append(r.jobs, job{
+		name:     "signing-key-rotation",
+		interval: time.Hour,
+		run:      r.rotateSigningKeys,
+	})
+	r.jobs = append(r.jobs, job{
+		name:     "account-export",
+		interval: time.Minute,
+		run:      r.buildAccountExports,
+	}, job{
+		name:     "account-deletion",
+		interval: time.Hour,
+		run:      r.deleteAccounts,
+	})
+	if svcCtx.Config.Share.AccessLogRetentionDays > 0 {
+		r.jobs = append(r.jobs, job{
+			name:     "share-access-log-purge",
+			interval: time.Hour,
+			run:      r.purgeShareAccessLogs,
+		})
+	}
+	return r
+}
+
+// Start launches all jobs in the background. Each job runs once immediately.
+func (r *Runner) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	r.cancel = cancel
+	for _, j := range r.jobs {
+		r.wg.Add(1)
+		go r.loop(ctx, j)
+	}
+}
+
+// Stop cancels running jobs and waits for them to return.
+func (r *Runner) Stop(ctx context.Context) error {
+	if r.cancel != nil {
+		r.cancel()
+	}
+	done := make(chan struct{})
+	go func() {
+		r.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (r *Runner) loop(ctx context.Context, j job) {
+	defer r.wg.Done()
+	ticker := time.NewTicker(j.interval)
+	defer ticker.Stop()
+	for {
+		if err := j.run(ctx); err != nil && ctx.Err() == nil {
+			log.Printf("[Jobs] %s failed: %v", j.name, err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
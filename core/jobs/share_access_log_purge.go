@@ -0,0 +1,25 @@
+package jobs
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"cloud-dist/core/models"
+)
+
+// purgeShareAccessLogs deletes share access events older than the configured retention.
+func (r *Runner) purgeShareAccessLogs(ctx context.Context) error {
+	days := r.svcCtx.Config.Share.AccessLogRetentionDays
+	cutoff := time.Now().AddDate(0, 0, -days)
+	result := r.svcCtx.DB.WithContext(ctx).
+		Where("created_at < ?", cutoff).
+		Delete(&models.ShareAccessLog{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected > 0 {
+		log.Printf("[Jobs] Purged %d share access events older than %d days", result.RowsAffected, days)
+	}
+	return nil
+}
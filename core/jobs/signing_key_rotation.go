@@ -0,0 +1,11 @@
+package jobs
+
+import (
+	"context"
+)
+
+// rotateSigningKeys adds a JWT signing key when the active one is due and
+// reloads keys created by other instances.
+func (r *Runner) rotateSigningKeys(ctx context.Context) error {
+	return r.svcCtx.Keys.Rotate(ctx)
+}
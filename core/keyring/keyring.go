@@ -0,0 +1,370 @@
+// Package keyring holds the Ed25519 keys that sign and verify our JWTs.
+//
+// The newest key signs; every unexpired key verifies, selected by the kid
+// header. Keys are shared by all instances through the signing_key table and
+// rotated on a schedule, and the public halves are published as a JWKS so
+// other services can verify tokens without a shared secret.
+package keyring
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"cloud-dist/core/models"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/golang-jwt/jwt/v5"
+	"gorm.io/gorm"
+)
+
+// Algorithm is the JWS algorithm of every key in the ring
+const Algorithm = "EdDSA"
+
+const (
+	rotateLockKey = "signing_key:rotate"
+	// keepAfterRotation is how long a key still verifies after it stopped
+	// signing; far longer than any token lives, so caches elsewhere can lag
+	keepAfterRotation = 7 * 24 * time.Hour
+	// reloadInterval throttles reloads triggered by unknown kids
+	reloadInterval = 10 * time.Second
+	// prePublish is how long a new key sits in the JWKS before it signs, so
+	// verifiers with a cached key set learn it first
+	prePublish = 2 * time.Hour
+)
+
+var b64 = base64.RawURLEncoding
+
+// Key is one entry of the ring. Private is nil when the key could not be
+// decrypted, which leaves it usable for verification only.
+type Key struct {
+	ID        string
+	Private   ed25519.PrivateKey
+	Public    ed25519.PublicKey
+	CreatedAt time.Time
+	ExpiresAt time.Time
+}
+
+type Ring struct {
+	db          *gorm.DB
+	rdb         *redis.Client
+	sealKey     []byte
+	rotateEvery time.Duration
+
+	mu         sync.RWMutex
+	keys       map[string]*Key
+	active     *Key
+	lastReload time.Time
+}
+
+// New creates a ring backed by the signing_key table. secret protects the
+// private keys at rest. A nil db keeps keys in memory only, for tests.
+func New(db *gorm.DB, rdb *redis.Client, secret string, rotateEvery time.Duration) *Ring {
+	sum := sha256.Sum256([]byte("signing-key:" + secret))
+	return &Ring{
+		db:          db,
+		rdb:         rdb,
+		sealKey:     sum[:],
+		rotateEvery: rotateEvery,
+		keys:        make(map[string]*Key),
+	}
+}
+
+var (
+	defaultMu   sync.RWMutex
+	defaultRing *Ring
+)
+
+// SetDefault installs the ring used by helper.GenerateToken and AnalyzeToken
+func SetDefault(r *Ring) {
+	defaultMu.Lock()
+	defaultRing = r
+	defaultMu.Unlock()
+}
+
+// Default returns the ring installed with SetDefault
+func Default() *Ring {
+	defaultMu.RLock()
+	defer defaultMu.RUnlock()
+	return defaultRing
+}
+
+// Load replaces the in-memory keys with the unexpired keys in the database
+func (r *Ring) Load(ctx context.Context) error {
+	if r.db == nil {
+		return nil
+	}
+	var rows []*models.SigningKey
+	err := r.db.WithContext(ctx).
+		Where("algorithm = ? AND expires_at > ?", Algorithm, time.Now()).
+		Order("created_at ASC").
+		Find(&rows).Error
+	if err != nil {
+		return err
+	}
+
+	keys := make(map[string]*Key, len(rows))
+	for _, row := range rows {
+		k, err := r.decode(row)
+		if err != nil {
+			log.Printf("[Keyring] Skipping key %s: %v", row.Kid, err)
+			continue
+		}
+		keys[k.ID] = k
+	}
+
+	r.mu.Lock()
+	r.keys = keys
+	r.active = pickActive(keys, time.Now())
+	r.lastReload = time.Now()
+	r.mu.Unlock()
+	return nil
+}
+
+// pickActive chooses the newest key that has been published for prePublish.
+// On a fresh ring no key is that old yet, so the newest key is used at once.
+func pickActive(keys map[string]*Key, now time.Time) *Key {
+	var published, newest *Key
+	for _, k := range keys {
+		if k.Private == nil || now.After(k.ExpiresAt) {
+			continue
+		}
+		if newest == nil || k.CreatedAt.After(newest.CreatedAt) {
+			newest = k
+		}
+		if !k.CreatedAt.After(now.Add(-prePublish)) && (published == nil || k.CreatedAt.After(published.CreatedAt)) {
+			published = k
+		}
+	}
+	if published != nil {
+		return published
+	}
+	return newest
+}
+
+// Rotate adds the next signing key when there is none, or when the newest
+// one is due to be replaced within prePublish, then reloads the ring
+func (r *Ring) Rotate(ctx context.Context) error {
+	if err := r.Load(ctx); err != nil {
+		return err
+	}
+	r.mu.RLock()
+	var newest *Key
+	for _, k := range r.keys {
+		if k.Private != nil && (newest == nil || k.CreatedAt.After(newest.CreatedAt)) {
+			newest = k
+		}
+	}
+	r.mu.RUnlock()
+	if newest != nil && time.Since(newest.CreatedAt) < r.rotateEvery-prePublish {
+		return nil
+	}
+
+	// Only one instance rotates at a time; the others pick the key up on
+	// their next load
+	if r.rdb != nil {
+		ok, err := r.rdb.SetNX(ctx, rotateLockKey, "1", time.Minute).Result()
+		if err == nil && !ok {
+			return nil
+		}
+	}
+
+	k, err := Generate(time.Now(), r.rotateEvery+keepAfterRotation)
+	if err != nil {
+		return err
+	}
+	if r.db == nil {
+		r.Add(k)
+		return nil
+	}
+	row, err := r.encode(k)
+	if err != nil {
+		return err
+	}
+	if err = r.db.WithContext(ctx).Create(row).Error; err != nil {
+		return err
+	}
+	log.Printf("[Keyring] Rotated JWT signing key, new kid %s", k.ID)
+	return r.Load(ctx)
+}
+
+// Generate creates a key that verifies for lifetime from now
+func Generate(now time.Time, lifetime time.Duration) (*Key, error) {
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	sum := sha256.Sum256(pub)
+	return &Key{
+		ID:        b64.EncodeToString(sum[:12]),
+		Private:   priv,
+		Public:    pub,
+		CreatedAt: now,
+		ExpiresAt: now.Add(lifetime),
+	}, nil
+}
+
+// Add puts a key into the in-memory ring
+func (r *Ring) Add(k *Key) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.keys[k.ID] = k
+	r.active = pickActive(r.keys, time.Now())
+}
+
+// Remove drops a key, so tokens signed with it no longer verify
+func (r *Ring) Remove(kid string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.keys, kid)
+	r.active = pickActive(r.keys, time.Now())
+}
+
+// Sign signs claims with the active key and sets the kid header
+func (r *Ring) Sign(claims jwt.Claims) (string, error) {
+	r.mu.RLock()
+	active := r.active
+	r.mu.RUnlock()
+	if active == nil {
+		return "", errors.New("no JWT signing key available")
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodEdDSA, claims)
+	token.Header["kid"] = active.ID
+	return token.SignedString(active.Private)
+}
+
+// Keyfunc resolves the verification key from the kid header. An unknown kid
+// may have been added by another instance, so it triggers a throttled reload.
+func (r *Ring) Keyfunc(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+	if kid == "" {
+		return nil, errors.New("token has no key id")
+	}
+	if k := r.lookup(kid); k != nil {
+		return k.Public, nil
+	}
+
+	r.mu.RLock()
+	stale := time.Since(r.lastReload) > reloadInterval
+	r.mu.RUnlock()
+	if stale && r.db != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		defer cancel()
+		if err := r.Load(ctx); err != nil {
+			log.Printf("[Keyring] Reload for kid %s failed: %v", kid, err)
+		}
+		if k := r.lookup(kid); k != nil {
+			return k.Public, nil
+		}
+	}
+	return nil, fmt.Errorf("unknown signing key %s", kid)
+}
+
+func (r *Ring) lookup(kid string) *Key {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	k := r.keys[kid]
+	if k == nil || time.Now().After(k.ExpiresAt) {
+		return nil
+	}
+	return k
+}
+
+// JWK is the public half of a key in RFC 8037 (OKP) form
+type JWK struct {
+	Kty string `json:"kty"`
+	Crv string `json:"crv"`
+	Kid string `json:"kid"`
+	X   string `json:"x"`
+	Alg string `json:"alg"`
+	Use string `json:"use"`
+}
+
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// JWKS lists every key that may still verify a token
+func (r *Ring) JWKS() JWKS {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	set := JWKS{Keys: make([]JWK, 0, len(r.keys))}
+	now := time.Now()
+	for _, k := range r.keys {
+		if now.After(k.ExpiresAt) {
+			continue
+		}
+		set.Keys = append(set.Keys, JWK{
+			Kty: "OKP",
+			Crv: "Ed25519",
+			Kid: k.ID,
+			X:   b64.EncodeToString(k.Public),
+			Alg: Algorithm,
+			Use: "sig",
+		})
+	}
+	return set
+}
+
+func (r *Ring) encode(k *Key) (*models.SigningKey, error) {
+	gcm, err := r.gcm()
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err = rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	sealed := gcm.Seal(nonce, nonce, k.Private.Seed(), []byte(k.ID))
+	return &models.SigningKey{
+		Kid:        k.ID,
+		Algorithm:  Algorithm,
+		PublicKey:  b64.EncodeToString(k.Public),
+		PrivateKey: b64.EncodeToString(sealed),
+		CreatedAt:  k.CreatedAt,
+		ExpiresAt:  k.ExpiresAt,
+	}, nil
+}
+
+// decode restores a stored key. A key sealed under a different secret keeps
+// its public half, so tokens it signed still verify until it expires.
+func (r *Ring) decode(row *models.SigningKey) (*Key, error) {
+	pub, err := b64.DecodeString(row.PublicKey)
+	if err != nil || len(pub) != ed25519.PublicKeySize {
+		return nil, errors.New("invalid public key")
+	}
+	k := &Key{ID: row.Kid, Public: pub, CreatedAt: row.CreatedAt, ExpiresAt: row.ExpiresAt}
+
+	sealed, err := b64.DecodeString(row.PrivateKey)
+	if err != nil {
+		return k, nil
+	}
+	gcm, err := r.gcm()
+	if err != nil || len(sealed) < gcm.NonceSize() {
+		return k, nil
+	}
+	seed, err := gcm.Open(nil, sealed[:gcm.NonceSize()], sealed[gcm.NonceSize():], []byte(row.Kid))
+	if err != nil || len(seed) != ed25519.SeedSize {
+		log.Printf("[Keyring] Key %s was sealed with another secret, using it for verification only", row.Kid)
+		return k, nil
+	}
+	k.Private = ed25519.NewKeyFromSeed(seed)
+	return k, nil
+}
+
+func (r *Ring) gcm() (cipher.AEAD, error) {
+	block, err := aes.NewCipher(r.sealKey)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
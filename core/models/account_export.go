@@ -0,0 +1,25 @@
+package models
+
+import (
+	"time"
+)
+
+// AccountExport is a user's request for an archive of all their data. The
+// archive is built by a background job and kept until ExpiresAt.
+type AccountExport struct {
+	ID           int64      `gorm:"column:id;primaryKey;autoIncrement"`
+	Identity     string     `gorm:"column:identity"`
+	UserIdentity string     `gorm:"column:user_identity"`
+	Status       string     `gorm:"column:status;default:pending"` // pending, running, ready, failed, expired
+	Path         string     `gorm:"column:path"`                   // S3 key of the archive once ready
+	Size         int64      `gorm:"column:size"`
+	Error        string     `gorm:"column:error"`
+	StartedAt    *time.Time `gorm:"column:started_at"`
+	FinishedAt   *time.Time `gorm:"column:finished_at"`
+	ExpiresAt    *time.Time `gorm:"column:expires_at"`
+	CreatedAt    time.Time  `gorm:"column:created_at"`
+}
+
+func (AccountExport) TableName() string {
+	return "account_export"
+}
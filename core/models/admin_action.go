@@ -0,0 +1,22 @@
+package models
+
+import (
+	"time"
+)
+
+// AdminAction records one change made through the admin API, for
+// accountability. Rows are only ever inserted.
+type AdminAction struct {
+	ID             int64     `gorm:"column:id;primaryKey;autoIncrement"`
+	ActorIdentity  string    `gorm:"column:actor_identity"` // The admin or support user who acted
+	Action         string    `gorm:"column:action"`         // e.g. user.suspend, share.takedown
+	TargetType     string    `gorm:"column:target_type"`    // user, share
+	TargetIdentity string    `gorm:"column:target_identity"`
+	Detail         string    `gorm:"column:detail"` // JSON with the action's parameters
+	IP             string    `gorm:"column:ip"`
+	CreatedAt      time.Time `gorm:"column:created_at"`
+}
+
+func (AdminAction) TableName() string {
+	return "admin_action"
+}
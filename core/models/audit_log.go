@@ -0,0 +1,26 @@
+package models
+
+import (
+	"time"
+)
+
+// AuditLog records one security or data event. Rows are append-only: nothing
+// in the service updates or deletes them.
+type AuditLog struct {
+	ID              int64     `gorm:"column:id;primaryKey;autoIncrement"`
+	ActorIdentity   string    `gorm:"column:actor_identity"`   // User who acted, empty for anonymous visitors and payment webhooks
+	SubjectIdentity string    `gorm:"column:subject_identity"` // Account the event concerns, whose audit log shows it
+	Action          string    `gorm:"column:action"`           // e.g. login, file.delete, share.access
+	TargetType      string    `gorm:"column:target_type"`      // user, file, share, session, order
+	TargetIdentity  string    `gorm:"column:target_identity"`
+	Success         bool      `gorm:"column:success"`
+	Reason          string    `gorm:"column:reason"` // Why the action failed, empty on success
+	Detail          string    `gorm:"column:detail"` // JSON with event-specific fields
+	IP              string    `gorm:"column:ip"`
+	UserAgent       string    `gorm:"column:user_agent"`
+	CreatedAt       time.Time `gorm:"column:created_at"`
+}
+
+func (AuditLog) TableName() string {
+	return "audit_log"
+}
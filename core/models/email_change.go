@@ -0,0 +1,24 @@
+package models
+
+import (
+	"time"
+)
+
+// EmailChange records a committed change of UserBasic.Email. The old address
+// gets an undo link, valid until UndoExpiresAt; only its hash is stored.
+type EmailChange struct {
+	ID            int64      `gorm:"column:id;primaryKey;autoIncrement"`
+	Identity      string     `gorm:"column:identity"`
+	UserIdentity  string     `gorm:"column:user_identity"`
+	OldEmail      string     `gorm:"column:old_email"`
+	NewEmail      string     `gorm:"column:new_email"`
+	UndoTokenHash string     `gorm:"column:undo_token_hash"`
+	UndoExpiresAt time.Time  `gorm:"column:undo_expires_at"`
+	UndoneAt      *time.Time `gorm:"column:undone_at"`
+	IP            string     `gorm:"column:ip"`
+	CreatedAt     time.Time  `gorm:"column:created_at"`
+}
+
+func (EmailChange) TableName() string {
+	return "email_change"
+}
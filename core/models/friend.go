@@ -28,7 +28,7 @@ type FriendRequest struct {
 	Identity         string         `gorm:"column:identity"`
 	FromUserIdentity string         `gorm:"column:from_user_identity"`     // User who sent the request
 	ToUserIdentity   string         `gorm:"column:to_user_identity"`       // User who received the request
-	Status           string         `gorm:"column:status;default:pending"` // pending, accepted, rejected
+	Status           string         `gorm:"column:status;default:pending"` // pending, accept, reject, cancelled, blocked
 	Message          string         `gorm:"column:message"`                // Optional message
 	CreatedAt        time.Time      `gorm:"column:created_at"`
 	UpdatedAt        time.Time      `gorm:"column:updated_at"`
@@ -39,16 +39,23 @@ func (FriendRequest) TableName() string {
 	return "friend_request"
 }
 
-// FriendShare represents a file shared with a friend
+// FriendShare represents a file or folder shared with a friend
 type FriendShare struct {
 	ID                     int64          `gorm:"column:id;primaryKey;autoIncrement"`
 	Identity               string         `gorm:"column:identity"`
-	FromUserIdentity       string         `gorm:"column:from_user_identity"`       // User who shared the file
-	ToUserIdentity         string         `gorm:"column:to_user_identity"`         // Friend who received the share
-	RepositoryIdentity     string         `gorm:"column:repository_identity"`      // The shared file
-	UserRepositoryIdentity string         `gorm:"column:user_repository_identity"` // User's file reference
-	Message                string         `gorm:"column:message"`                  // Optional message
-	IsRead                 bool           `gorm:"column:is_read;default:false"`    // Whether the friend has read it
+	FromUserIdentity       string         `gorm:"column:from_user_identity"`            // User who shared the file
+	ToUserIdentity         string         `gorm:"column:to_user_identity"`              // Friend who received the share
+	RepositoryIdentity     string         `gorm:"column:repository_identity"`           // The shared file
+	UserRepositoryIdentity string         `gorm:"column:user_repository_identity"`      // User's file or folder reference
+	Message                string         `gorm:"column:message"`                       // Optional message
+	IsRead                 bool           `gorm:"column:is_read;default:false"`         // Whether the friend has read it
+	Permission             string         `gorm:"column:permission;default:view"`       // view, edit
+	ExpiresAt              *time.Time     `gorm:"column:expires_at"`                    // Nil means the share never expires
+	RecipientState         string         `gorm:"column:recipient_state;default:inbox"` // inbox, archived, dismissed
+	OpenedAt               *time.Time     `gorm:"column:opened_at"`                     // First time the recipient opened it
+	SavedAt                *time.Time     `gorm:"column:saved_at"`                      // First time the recipient saved it
+	InviteIdentity         string         `gorm:"column:invite_identity"`               // Email invite this share came from, if any
+	SpaceIdentity          string         `gorm:"column:space_identity"`                // Team space the shared item belongs to, if any
 	CreatedAt              time.Time      `gorm:"column:created_at"`
 	UpdatedAt              time.Time      `gorm:"column:updated_at"`
 	DeletedAt              gorm.DeletedAt `gorm:"column:deleted_at"`
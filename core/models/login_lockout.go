@@ -0,0 +1,27 @@
+package models
+
+import (
+	"time"
+)
+
+// LoginLockout records each time repeated failed logins locked an account or
+// an IP address. Rows are append-only, apart from marking an account lockout
+// as lifted through the emailed unlock link.
+type LoginLockout struct {
+	ID              int64      `gorm:"column:id;primaryKey;autoIncrement"`
+	Identity        string     `gorm:"column:identity"`
+	Scope           string     `gorm:"column:scope"`         // account, ip
+	UserIdentity    string     `gorm:"column:user_identity"` // Locked account, empty for IP lockouts and unknown accounts
+	Account         string     `gorm:"column:account"`       // Username or email that was tried
+	IP              string     `gorm:"column:ip"`
+	UserAgent       string     `gorm:"column:user_agent"`
+	Failures        int64      `gorm:"column:failures"`
+	LockedUntil     time.Time  `gorm:"column:locked_until"`
+	UnlockTokenHash string     `gorm:"column:unlock_token_hash"` // Hash of the token in the emailed unlock link
+	UnlockedAt      *time.Time `gorm:"column:unlocked_at"`
+	CreatedAt       time.Time  `gorm:"column:created_at"`
+}
+
+func (LoginLockout) TableName() string {
+	return "login_lockout"
+}
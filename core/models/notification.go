@@ -0,0 +1,41 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Notification is an entry in a user's notification center
+type Notification struct {
+	ID           int64          `gorm:"column:id;primaryKey;autoIncrement"`
+	Identity     string         `gorm:"column:identity"`
+	UserIdentity string         `gorm:"column:user_identity"`
+	Type         string         `gorm:"column:type"` // Same as the event type, e.g. friend_share.received
+	Title        string         `gorm:"column:title"`
+	Body         string         `gorm:"column:body"`
+	Data         string         `gorm:"column:data"` // JSON payload for the client
+	IsRead       bool           `gorm:"column:is_read;default:false"`
+	CreatedAt    time.Time      `gorm:"column:created_at"`
+	UpdatedAt    time.Time      `gorm:"column:updated_at"`
+	DeletedAt    gorm.DeletedAt `gorm:"column:deleted_at"`
+}
+
+func (Notification) TableName() string {
+	return "notification"
+}
+
+// NotificationPreference overrides how one notification type is delivered to a
+// user. Types without a row are delivered in-app.
+type NotificationPreference struct {
+	ID           int64     `gorm:"column:id;primaryKey;autoIncrement"`
+	UserIdentity string    `gorm:"column:user_identity"`
+	Type         string    `gorm:"column:type"`
+	Channel      string    `gorm:"column:channel"` // in_app, email (in-app and email), off
+	CreatedAt    time.Time `gorm:"column:created_at"`
+	UpdatedAt    time.Time `gorm:"column:updated_at"`
+}
+
+func (NotificationPreference) TableName() string {
+	return "notification_preference"
+}
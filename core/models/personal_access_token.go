@@ -0,0 +1,25 @@
+package models
+
+import (
+	"time"
+)
+
+// PersonalAccessToken is a long-lived API token for scripts and CI. Only the
+// SHA-256 hash of the token is stored; Prefix is kept to tell tokens apart.
+type PersonalAccessToken struct {
+	ID           int64      `gorm:"column:id;primaryKey;autoIncrement"`
+	Identity     string     `gorm:"column:identity"`
+	UserIdentity string     `gorm:"column:user_identity"`
+	Name         string     `gorm:"column:name"`
+	TokenHash    string     `gorm:"column:token_hash"`
+	Prefix       string     `gorm:"column:prefix"`     // First characters of the token, e.g. cdp_ab12cd34
+	Scopes       string     `gorm:"column:scopes"`     // Comma-separated, e.g. files:read,files:write
+	ExpiresAt    *time.Time `gorm:"column:expires_at"` // Nil when the token never expires
+	LastUsedAt   *time.Time `gorm:"column:last_used_at"`
+	RevokedAt    *time.Time `gorm:"column:revoked_at"`
+	CreatedAt    time.Time  `gorm:"column:created_at"`
+}
+
+func (PersonalAccessToken) TableName() string {
+	return "personal_access_token"
+}
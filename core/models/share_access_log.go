@@ -0,0 +1,25 @@
+package models
+
+import (
+	"time"
+)
+
+// ShareAccessLog records a single access to a public share link.
+// Rows are append-only and removed by the retention purge.
+type ShareAccessLog struct {
+	ID            int64     `gorm:"column:id;primaryKey;autoIncrement"`
+	Identity      string    `gorm:"column:identity"`
+	ShareIdentity string    `gorm:"column:share_identity"`
+	OwnerIdentity string    `gorm:"column:owner_identity"` // User who created the share
+	UserIdentity  string    `gorm:"column:user_identity"`  // Authenticated visitor, empty for anonymous access
+	Event         string    `gorm:"column:event"`          // view, download
+	Success       bool      `gorm:"column:success"`
+	Reason        string    `gorm:"column:reason"` // Why the access was refused, empty on success
+	IP            string    `gorm:"column:ip"`
+	UserAgent     string    `gorm:"column:user_agent"`
+	CreatedAt     time.Time `gorm:"column:created_at"`
+}
+
+func (ShareAccessLog) TableName() string {
+	return "share_access_log"
+}
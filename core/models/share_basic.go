@@ -14,6 +14,8 @@ type ShareBasic struct {
 	RepositoryIdentity     string         `gorm:"column:repository_identity"`
 	ExpiredTime            int            `gorm:"column:expired_time"`
 	ClickNum               int            `gorm:"column:click_num"`
+	DownloadLimit          int            `gorm:"column:download_limit"` // 0 means unlimited
+	DownloadNum            int            `gorm:"column:download_num"`
 	CreatedAt              time.Time      `gorm:"column:created_at"`
 	UpdatedAt              time.Time      `gorm:"column:updated_at"`
 	DeletedAt              gorm.DeletedAt `gorm:"column:deleted_at"`
@@ -0,0 +1,31 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ShareInvite is a file shared to an email address that may not have an account yet.
+// When the address registers, the invite becomes a FriendShare.
+type ShareInvite struct {
+	ID                     int64          `gorm:"column:id;primaryKey;autoIncrement"`
+	Identity               string         `gorm:"column:identity"`
+	FromUserIdentity       string         `gorm:"column:from_user_identity"` // User who sent the invite
+	Email                  string         `gorm:"column:email"`              // Recipient address
+	RepositoryIdentity     string         `gorm:"column:repository_identity"`
+	UserRepositoryIdentity string         `gorm:"column:user_repository_identity"`
+	Message                string         `gorm:"column:message"`
+	Permission             string         `gorm:"column:permission;default:view"` // Granted to the share on acceptance: view, edit
+	Status                 string         `gorm:"column:status;default:pending"`  // pending, accepted, revoked
+	ToUserIdentity         string         `gorm:"column:to_user_identity"`        // Set once the invite is accepted
+	FriendShareIdentity    string         `gorm:"column:friend_share_identity"`   // Share created on acceptance
+	ExpiresAt              time.Time      `gorm:"column:expires_at"`
+	CreatedAt              time.Time      `gorm:"column:created_at"`
+	UpdatedAt              time.Time      `gorm:"column:updated_at"`
+	DeletedAt              gorm.DeletedAt `gorm:"column:deleted_at"`
+}
+
+func (ShareInvite) TableName() string {
+	return "share_invite"
+}
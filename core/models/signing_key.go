@@ -0,0 +1,21 @@
+package models
+
+import (
+	"time"
+)
+
+// SigningKey is one Ed25519 key of the JWT key ring. The private key is
+// encrypted with a key derived from the configured JWT secret.
+type SigningKey struct {
+	ID         int64     `gorm:"column:id;primaryKey;autoIncrement"`
+	Kid        string    `gorm:"column:kid"`
+	Algorithm  string    `gorm:"column:algorithm"`   // EdDSA
+	PublicKey  string    `gorm:"column:public_key"`  // base64url
+	PrivateKey string    `gorm:"column:private_key"` // AES-GCM sealed seed, base64url
+	CreatedAt  time.Time `gorm:"column:created_at"`  // Newest key signs new tokens
+	ExpiresAt  time.Time `gorm:"column:expires_at"`  // Tokens signed with the key stop verifying after this
+}
+
+func (SigningKey) TableName() string {
+	return "signing_key"
+}
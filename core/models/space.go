@@ -0,0 +1,59 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Space is a team folder shared by several members. Its files are stored in
+// user_repository with user_identity set to the space identity, and their size
+// is billed to the owner's quota.
+type Space struct {
+	ID            int64          `gorm:"column:id;primaryKey;autoIncrement"`
+	Identity      string         `gorm:"column:identity"`
+	Name          string         `gorm:"column:name"`
+	OwnerIdentity string         `gorm:"column:owner_identity"` // User whose quota the space uses
+	CreatedAt     time.Time      `gorm:"column:created_at"`
+	UpdatedAt     time.Time      `gorm:"column:updated_at"`
+	DeletedAt     gorm.DeletedAt `gorm:"column:deleted_at"`
+}
+
+func (Space) TableName() string {
+	return "space"
+}
+
+// SpaceMember grants a user access to a space
+type SpaceMember struct {
+	ID            int64          `gorm:"column:id;primaryKey;autoIncrement"`
+	Identity      string         `gorm:"column:identity"`
+	SpaceIdentity string         `gorm:"column:space_identity"`
+	UserIdentity  string         `gorm:"column:user_identity"`
+	Role          string         `gorm:"column:role"` // owner, editor, viewer
+	CreatedAt     time.Time      `gorm:"column:created_at"`
+	UpdatedAt     time.Time      `gorm:"column:updated_at"`
+	DeletedAt     gorm.DeletedAt `gorm:"column:deleted_at"`
+}
+
+func (SpaceMember) TableName() string {
+	return "space_member"
+}
+
+// SpaceInvite asks a user to join a space. They become a member only once
+// they accept it.
+type SpaceInvite struct {
+	ID              int64          `gorm:"column:id;primaryKey;autoIncrement"`
+	Identity        string         `gorm:"column:identity"`
+	SpaceIdentity   string         `gorm:"column:space_identity"`
+	InviterIdentity string         `gorm:"column:inviter_identity"`
+	UserIdentity    string         `gorm:"column:user_identity"` // Invited user
+	Role            string         `gorm:"column:role"`          // Granted on acceptance: editor, viewer
+	ExpiresAt       time.Time      `gorm:"column:expires_at"`
+	CreatedAt       time.Time      `gorm:"column:created_at"`
+	UpdatedAt       time.Time      `gorm:"column:updated_at"`
+	DeletedAt       gorm.DeletedAt `gorm:"column:deleted_at"`
+}
+
+func (SpaceInvite) TableName() string {
+	return "space_invite"
+}
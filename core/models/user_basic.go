@@ -7,16 +7,26 @@ import (
 )
 
 type UserBasic struct {
-	ID          int64          `gorm:"column:id;primaryKey;autoIncrement"`
-	Identity    string         `gorm:"column:identity"`
-	Name        string         `gorm:"column:name"`
-	Password    string         `gorm:"column:password"`
-	Email       string         `gorm:"column:email"`
-	NowVolume   int64          `gorm:"column:now_volume"`
-	TotalVolume int64          `gorm:"column:total_volume"`
-	CreatedAt   time.Time      `gorm:"column:created_at"`
-	UpdatedAt   time.Time      `gorm:"column:updated_at"`
-	DeletedAt   gorm.DeletedAt `gorm:"column:deleted_at"`
+	ID                  int64          `gorm:"column:id;primaryKey;autoIncrement"`
+	Identity            string         `gorm:"column:identity"`
+	Name                string         `gorm:"column:name"`
+	Password            string         `gorm:"column:password"`
+	Email               string         `gorm:"column:email"`
+	NowVolume           int64          `gorm:"column:now_volume"`
+	TotalVolume         int64          `gorm:"column:total_volume"`
+	DiscoverableByName  bool           `gorm:"column:discoverable_by_name;default:true"`   // Others can find the user by username
+	DiscoverableByEmail bool           `gorm:"column:discoverable_by_email;default:false"` // Others can find the user by exact email
+	TOTPSecret          string         `gorm:"column:totp_secret"`                         // Base32 secret, set when enrollment starts
+	TOTPEnabled         bool           `gorm:"column:totp_enabled"`                        // Set once the first code is confirmed
+	TOTPLastCounter     int64          `gorm:"column:totp_last_counter"`                   // Last accepted time step, so a code works only once
+	TokenVersion        int64          `gorm:"column:token_version"`                       // Bumped to invalidate every issued token
+	DeleteAfter         *time.Time     `gorm:"column:delete_after"`                        // Account deletion is scheduled for this time
+	Role                string         `gorm:"column:role;default:user"`                   // user, support or admin
+	SuspendedAt         *time.Time     `gorm:"column:suspended_at"`                        // Set while an admin has suspended the account
+	SuspendReason       string         `gorm:"column:suspend_reason"`
+	CreatedAt           time.Time      `gorm:"column:created_at"`
+	UpdatedAt           time.Time      `gorm:"column:updated_at"`
+	DeletedAt           gorm.DeletedAt `gorm:"column:deleted_at"`
 }
 
 func (UserBasic) TableName() string {
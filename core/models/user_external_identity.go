@@ -0,0 +1,21 @@
+package models
+
+import (
+	"time"
+)
+
+// UserExternalIdentity links an account to a subject at an external identity
+// provider. An (issuer, subject) pair belongs to at most one account.
+type UserExternalIdentity struct {
+	ID           int64      `gorm:"column:id;primaryKey;autoIncrement"`
+	UserIdentity string     `gorm:"column:user_identity"`
+	Issuer       string     `gorm:"column:issuer"`
+	Subject      string     `gorm:"column:subject"`
+	Email        string     `gorm:"column:email"` // As reported at the last login
+	CreatedAt    time.Time  `gorm:"column:created_at"`
+	LastLoginAt  *time.Time `gorm:"column:last_login_at"`
+}
+
+func (UserExternalIdentity) TableName() string {
+	return "user_external_identity"
+}
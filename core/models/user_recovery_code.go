@@ -0,0 +1,19 @@
+package models
+
+import (
+	"time"
+)
+
+// UserRecoveryCode is a one-time code that stands in for a TOTP code when the
+// authenticator is lost. Only the SHA-256 hash is stored.
+type UserRecoveryCode struct {
+	ID           int64      `gorm:"column:id;primaryKey;autoIncrement"`
+	UserIdentity string     `gorm:"column:user_identity"`
+	CodeHash     string     `gorm:"column:code_hash"`
+	UsedAt       *time.Time `gorm:"column:used_at"`
+	CreatedAt    time.Time  `gorm:"column:created_at"`
+}
+
+func (UserRecoveryCode) TableName() string {
+	return "user_recovery_code"
+}
@@ -0,0 +1,28 @@
+package models
+
+import (
+	"time"
+)
+
+// UserSession is one signed-in device. Every refresh rotation stays in the
+// same session; RefreshTokenID is the jti of the only refresh token that is
+// still valid for it.
+type UserSession struct {
+	ID             int64      `gorm:"column:id;primaryKey;autoIncrement"`
+	Identity       string     `gorm:"column:identity"`
+	UserIdentity   string     `gorm:"column:user_identity"`
+	DeviceName     string     `gorm:"column:device_name"`
+	IP             string     `gorm:"column:ip"`
+	UserAgent      string     `gorm:"column:user_agent"`
+	RefreshTokenID string     `gorm:"column:refresh_token_id"`
+	LastUsedAt     time.Time  `gorm:"column:last_used_at"`
+	ExpiresAt      time.Time  `gorm:"column:expires_at"`
+	RevokedAt      *time.Time `gorm:"column:revoked_at"`
+	RevokeReason   string     `gorm:"column:revoke_reason"` // logout, revoked, refresh_reuse, password_change, password_reset, account_locked, logout_all
+	CreatedAt      time.Time  `gorm:"column:created_at"`
+	UpdatedAt      time.Time  `gorm:"column:updated_at"`
+}
+
+func (UserSession) TableName() string {
+	return "user_session"
+}
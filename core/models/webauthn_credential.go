@@ -0,0 +1,26 @@
+package models
+
+import (
+	"time"
+)
+
+// WebAuthnCredential is a passkey registered to a user
+type WebAuthnCredential struct {
+	ID             int64      `gorm:"column:id;primaryKey;autoIncrement"`
+	Identity       string     `gorm:"column:identity"`
+	UserIdentity   string     `gorm:"column:user_identity"`
+	Name           string     `gorm:"column:name"`
+	CredentialID   string     `gorm:"column:credential_id"` // base64url, unique
+	PublicKey      []byte     `gorm:"column:public_key"`    // COSE_Key
+	Algorithm      int        `gorm:"column:algorithm"`     // COSE algorithm, e.g. -7 for ES256
+	SignCount      uint32     `gorm:"column:sign_count"`    // Must grow on every use unless it stays 0
+	AAGUID         string     `gorm:"column:aaguid"`        // Authenticator model, hex
+	Transports     string     `gorm:"column:transports"`    // Comma-separated hints, e.g. internal,hybrid
+	BackupEligible bool       `gorm:"column:backup_eligible"`
+	LastUsedAt     *time.Time `gorm:"column:last_used_at"`
+	CreatedAt      time.Time  `gorm:"column:created_at"`
+}
+
+func (WebAuthnCredential) TableName() string {
+	return "webauthn_credential"
+}
@@ -0,0 +1,131 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"cloud-dist/core/define"
+	"cloud-dist/core/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-redis/redis/v8"
+	"gorm.io/gorm"
+)
+
+// ratePlanTTL is how long a user's plan is cached; buying storage lifts the
+// limits within this time
+const ratePlanTTL = 10 * time.Minute
+
+// Allower counts a request against a budget; *Limiter keeps the budgets in
+// Redis
+type Allower interface {
+	Allow(ctx context.Context, key string, p Policy) (*Result, error)
+}
+
+// Middleware applies per-route policies to Gin requests
+type Middleware struct {
+	DB      *gorm.DB
+	RDB     *redis.Client
+	Limiter Allower
+	Default Policy
+	Routes  map[string]Policy // Keyed by route path as registered
+}
+
+func NewMiddleware(db *gorm.DB, rdb *redis.Client, def Policy, routes map[string]Policy) *Middleware {
+	return &Middleware{DB: db, RDB: rdb, Limiter: New(rdb), Default: def, Routes: routes}
+}
+
+// Handle counts the request against the caller's budget for the route, or the
+// shared default budget when the route has none of its own. Signed-in callers
+// are counted per user, everyone else per IP, so it must run after
+// authentication. When Redis is unavailable requests are let through rather
+// than failing the whole API.
+func (m *Middleware) Handle(c *gin.Context) {
+	route := c.FullPath()
+	policy, ok := m.Routes[route]
+	if !ok {
+		policy, route = m.Default, "*"
+	}
+
+	var key, plan string
+	if identity := c.GetString("UserIdentity"); identity != "" {
+		key, plan = "user:"+identity, m.plan(c, identity)
+	} else {
+		key, plan = "ip:"+c.ClientIP(), define.RatePlanAnonymous
+	}
+	policy = policy.ForPlan(plan)
+
+	res, err := m.Limiter.Allow(c.Request.Context(), route+":"+key, policy)
+	if err != nil {
+		log.Printf("[RateLimit] Failed to check %s for %s: %v", route, key, err)
+		c.Next()
+		return
+	}
+	if policy.Limit <= 0 {
+		c.Next()
+		return
+	}
+
+	c.Header("RateLimit-Limit", strconv.Itoa(res.Limit))
+	c.Header("RateLimit-Remaining", strconv.Itoa(res.Remaining))
+	c.Header("RateLimit-Reset", strconv.Itoa(ceilSeconds(res.ResetAfter)))
+	c.Header("RateLimit-Policy", fmt.Sprintf("%d;w=%d", policy.Limit, ceilSeconds(policy.Window)))
+	if !res.Allowed {
+		retry := ceilSeconds(res.RetryAfter)
+		c.Header("Retry-After", strconv.Itoa(retry))
+		c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{
+			"error": fmt.Sprintf("too many requests, try again in %d seconds", retry),
+		})
+		return
+	}
+	c.Next()
+}
+
+// plan returns the user's rate limit plan, cached in Redis when there is a
+// client. Lookup errors, or having no database, fall back to the free plan.
+func (m *Middleware) plan(c *gin.Context, identity string) string {
+	ctx := c.Request.Context()
+	cacheKey := "ratelimit:plan:" + identity
+	if m.RDB != nil {
+		plan, err := m.RDB.Get(ctx, cacheKey).Result()
+		if err == nil {
+			return plan
+		}
+		if !errors.Is(err, redis.Nil) {
+			return define.RatePlanFree
+		}
+	}
+	if m.DB == nil {
+		return define.RatePlanFree
+	}
+
+	var paid int64
+	err := m.DB.WithContext(ctx).Model(&models.StorageOrder{}).
+		Where("user_identity = ? AND status = ?", identity, "paid").
+		Count(&paid).Error
+	if err != nil {
+		log.Printf("[RateLimit] Failed to look up plan for %s: %v", identity, err)
+		return define.RatePlanFree
+	}
+	plan := define.RatePlanFree
+	if paid > 0 {
+		plan = define.RatePlanPaid
+	}
+	if m.RDB != nil {
+		if err = m.RDB.Set(ctx, cacheKey, plan, ratePlanTTL).Err(); err != nil {
+			log.Printf("[RateLimit] Failed to cache plan for %s: %v", identity, err)
+		}
+	}
+	return plan
+}
+
+// ceilSeconds rounds d up to whole seconds, as the headers carry
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
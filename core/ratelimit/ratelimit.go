@@ -0,0 +1,104 @@
+// Package ratelimit limits request rates with a token bucket kept in Redis,
+// so every instance of the service shares the same budget per client.
+//
+// The bucket is stored as the time it will next be full (the GCRA form of a
+// token bucket), which takes one key per client and one round trip per check.
+// Middleware applies it to Gin routes.
+package ratelimit
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// Policy allows Limit requests per Window, in bursts of up to Limit.
+// Plans overrides Limit for callers on the named plan.
+type Policy struct {
+	Limit  int
+	Window time.Duration
+	Plans  map[string]int
+}
+
+// ForPlan returns the policy that applies to a caller on plan
+func (p Policy) ForPlan(plan string) Policy {
+	if limit, ok := p.Plans[plan]; ok {
+		p.Limit = limit
+	}
+	p.Plans = nil
+	return p
+}
+
+// Result describes the bucket after a request was counted
+type Result struct {
+	Allowed    bool
+	Limit      int
+	Remaining  int
+	RetryAfter time.Duration // How long until the next request is allowed, when refused
+	ResetAfter time.Duration // How long until the bucket is full again
+}
+
+// gcra takes one token from the bucket in KEYS[1] if it has one.
+// ARGV: limit, window in milliseconds. The server clock is used so that
+// instances with skewed clocks still agree.
+// Returns allowed (0/1), remaining, retry after ms, reset after ms.
+var gcra = redis.NewScript(`
+-- Needed before Redis 5 to write after reading the clock
+redis.replicate_commands()
+
+local limit = tonumber(ARGV[1])
+local window = tonumber(ARGV[2])
+local interval = window / limit
+
+local t = redis.call("TIME")
+local now = tonumber(t[1]) * 1000 + math.floor(tonumber(t[2]) / 1000)
+
+local tat = tonumber(redis.call("GET", KEYS[1]) or now)
+if tat < now then
+	tat = now
+end
+
+local newTat = tat + interval
+local allowAt = newTat - window
+if now < allowAt then
+	local remaining = math.floor((now - (tat - window)) / interval)
+	return {0, remaining, math.ceil(allowAt - now), math.ceil(tat - now)}
+end
+
+redis.call("SET", KEYS[1], newTat, "PX", math.ceil(newTat - now))
+local remaining = math.floor((now - (newTat - window)) / interval)
+return {1, remaining, 0, math.ceil(newTat - now)}
+`)
+
+type Limiter struct {
+	rdb    *redis.Client
+	prefix string
+}
+
+func New(rdb *redis.Client) *Limiter {
+	return &Limiter{rdb: rdb, prefix: "ratelimit:"}
+}
+
+// Allow counts one request against key under p
+func (l *Limiter) Allow(ctx context.Context, key string, p Policy) (*Result, error) {
+	window := p.Window.Milliseconds()
+	if p.Limit <= 0 || window <= 0 {
+		return &Result{Allowed: true, Limit: p.Limit, Remaining: p.Limit}, nil
+	}
+	v, err := gcra.Run(ctx, l.rdb, []string{l.prefix + key}, p.Limit, window).Int64Slice()
+	if err != nil {
+		return nil, err
+	}
+	r := &Result{
+		Allowed:    v[0] == 1,
+		Limit:      p.Limit,
+		Remaining:  int(v[1]),
+		RetryAfter: time.Duration(v[2]) * time.Millisecond,
+		ResetAfter: time.Duration(v[3]) * time.Millisecond,
+	}
+	if r.Remaining < 0 {
+		r.Remaining = 0
+	}
+	return r, nil
+}
@@ -3,14 +3,32 @@ package router
 import (
 	"net/http"
 
+	"cloud-dist/core/define"
 	"cloud-dist/core/internal/handler"
+	"cloud-dist/core/internal/middleware"
 	"cloud-dist/core/svc"
 
 	"github.com/gin-gonic/gin"
 )
 
+// NewEngine returns an engine without middleware. Forwarding headers set the
+// client IP only on requests from trustedProxies; with none, the peer address
+// is used, so clients cannot pick their own IP.
+func NewEngine(trustedProxies []string) (*gin.Engine, error) {
+	engine := gin.New()
+	if err := engine.SetTrustedProxies(trustedProxies); err != nil {
+		return nil, err
+	}
+	return engine, nil
+}
+
 // Register wires all HTTP routes.
 func Register(r *gin.Engine, serviceName string, svcCtx *svc.ServiceContext) {
+	r.Use(middleware.ClientMeta)
+
+	// Public halves of the JWT signing keys, for services that verify our tokens
+	r.GET("/.well-known/jwks.json", handler.JwksHandler(svcCtx))
+
 	r.GET("/health", func(c *gin.Context) {
 		c.JSON(http.StatusOK, gin.H{
 			"service": serviceName,
@@ -18,54 +36,166 @@ func Register(r *gin.Engine, serviceName string, svcCtx *svc.ServiceContext) {
 		})
 	})
 
-	r.POST("/user/login", handler.UserLoginHandler(svcCtx))
-	r.POST("/user/logout", handler.UserLogoutHandler(svcCtx))
-	r.POST("/mail/code/send/register", handler.MailCodeSendRegisterHandler(svcCtx))
-	r.POST("/user/register", handler.UserRegisterHandler(svcCtx))
-	r.POST("/mail/code/send/password-reset", handler.MailCodeSendPasswordResetHandler(svcCtx))
-	r.POST("/user/password/reset", handler.UserPasswordResetHandler(svcCtx))
-	r.GET("/share/basic/detail", handler.ShareBasicDetailHandler(svcCtx))
+	// Every route below the health checks is rate limited by svcCtx.RateLimit,
+	// which must run after authentication so it can key on the user.
+	public := r.Group("/")
+	public.Use(svcCtx.RateLimit)
+	public.POST("/user/login", handler.UserLoginHandler(svcCtx))
+	public.POST("/user/login/2fa", handler.UserLoginTwoFactorHandler(svcCtx))
+	public.POST("/user/login/email", handler.UserLoginEmailHandler(svcCtx))
+	public.POST("/user/login/unlock", handler.UserLoginUnlockHandler(svcCtx))
+	public.POST("/user/login/passkey/begin", handler.PasskeyLoginBeginHandler(svcCtx))
+	public.POST("/user/login/passkey/finish", handler.PasskeyLoginFinishHandler(svcCtx))
+	public.POST("/user/oidc/start", handler.OIDCLoginStartHandler(svcCtx))
+	public.POST("/user/oidc/callback", handler.OIDCLoginCallbackHandler(svcCtx))
+	public.POST("/user/logout", handler.UserLogoutHandler(svcCtx))
+	// The refresh token is checked by the handler itself, AuthMiddleware only accepts access tokens
+	public.POST("/refresh/authorization", handler.RefreshAuthorizationHandler(svcCtx))
+	public.POST("/mail/code/send/register", handler.MailCodeSendRegisterHandler(svcCtx))
+	public.POST("/user/register", handler.UserRegisterHandler(svcCtx))
+	public.POST("/mail/code/send/password-reset", handler.MailCodeSendPasswordResetHandler(svcCtx))
+	public.POST("/mail/code/send/login", handler.MailCodeSendLoginHandler(svcCtx))
+	public.POST("/user/password/reset", handler.UserPasswordResetHandler(svcCtx))
+	public.POST("/user/email/change/undo", handler.UserEmailChangeUndoHandler(svcCtx))
+	r.GET("/share/basic/detail", svcCtx.OptionalAuth, svcCtx.RateLimit, handler.ShareBasicDetailHandler(svcCtx))
+	r.GET("/share/basic/download", svcCtx.OptionalAuth, svcCtx.RateLimit, handler.ShareBasicDownloadHandler(svcCtx))
+
+	// Event stream (EventSource cannot send headers, so browsers pass a
+	// single-use ticket from /events/ticket in the query)
+	r.GET("/events/stream", svcCtx.StreamAuth, svcCtx.SessionOnly, svcCtx.RateLimit, handler.EventStreamHandler(svcCtx))
 
-	// Stripe webhook (public, no auth required - Stripe verifies via signature)
+	// Stripe webhook (public, no auth required - Stripe verifies via signature, not rate limited)
 	// Note: This route uses /api prefix to match Stripe CLI forwarding path
 	r.POST("/api/storage/purchase/webhook", handler.StoragePurchaseWebhookHandler(svcCtx))
 
+	// File APIs act on the caller's drive, or on a team space selected with the
+	// X-Space-Identity header when wrapped in svcCtx.Space(role).
+	// Every route states the personal access token scope it needs with
+	// svcCtx.Scope, or refuses tokens with svcCtx.SessionOnly.
 	auth := r.Group("/")
-	auth.Use(svcCtx.Auth)
+	auth.Use(svcCtx.Auth, svcCtx.RateLimit)
 	{
-		auth.POST("/user/detail", handler.UserDetailHandler(svcCtx))
-		auth.POST("/mail/code/send/password-update", handler.MailCodeSendPasswordUpdateHandler(svcCtx))
-		auth.POST("/user/password/update", handler.UserPasswordUpdateHandler(svcCtx))
-		auth.POST("/file/upload", handler.FileUploadHandler(svcCtx))
-		auth.GET("/file/download", handler.FileDownloadHandler(svcCtx))
-		auth.POST("/user/repository/save", handler.UserRepositorySaveHandler(svcCtx))
-		auth.POST("/user/file/list", handler.UserFileListHandler(svcCtx))
-		auth.POST("/user/file/search", handler.UserFileSearchHandler(svcCtx))
-		auth.POST("/user/folder/list", handler.UserFolderListHandler(svcCtx))
-		auth.POST("/user/file/name/update", handler.UserFileNameUpdateHandler(svcCtx))
-		auth.POST("/user/folder/create", handler.UserFolderCreateHandler(svcCtx))
-		auth.DELETE("/user/file/delete", handler.UserFileDeleteHandler(svcCtx))
-		auth.PUT("/user/file/move", handler.UserFileMoveHandler(svcCtx))
-		auth.POST("/share/basic/create", handler.ShareBasicCreateHandler(svcCtx))
-		auth.POST("/share/basic/save", handler.ShareBasicSaveHandler(svcCtx))
-		auth.POST("/refresh/authorization", handler.RefreshAuthorizationHandler(svcCtx))
-		auth.POST("/file/upload/prepare", handler.FileUploadPrepareHandler(svcCtx))
-		auth.POST("/file/upload/chunk", handler.FileUploadChunkHandler(svcCtx))
-		auth.POST("/file/upload/chunk/complete", handler.FileUploadChunkCompleteHandler(svcCtx))
+		auth.POST("/user/detail", svcCtx.Scope(define.ScopeFilesRead), handler.UserDetailHandler(svcCtx))
+		auth.POST("/user/search", svcCtx.Scope(define.ScopeFriends), handler.UserSearchHandler(svcCtx))
+		auth.POST("/user/suggestion/list", svcCtx.Scope(define.ScopeFriends), handler.UserSuggestionListHandler(svcCtx))
+		auth.POST("/user/privacy/update", svcCtx.SessionOnly, handler.UserPrivacyUpdateHandler(svcCtx))
+		auth.POST("/mail/code/send/password-update", svcCtx.SessionOnly, handler.MailCodeSendPasswordUpdateHandler(svcCtx))
+		auth.POST("/mail/code/send/reauth", svcCtx.SessionOnly, handler.MailCodeSendReauthHandler(svcCtx))
+		auth.POST("/user/password/update", svcCtx.SessionOnly, handler.UserPasswordUpdateHandler(svcCtx))
+		auth.POST("/user/email/change", svcCtx.SessionOnly, handler.UserEmailChangeHandler(svcCtx))
+		auth.POST("/user/email/change/confirm", svcCtx.SessionOnly, handler.UserEmailChangeConfirmHandler(svcCtx))
+		auth.POST("/user/export/create", svcCtx.SessionOnly, handler.AccountExportCreateHandler(svcCtx))
+		auth.POST("/user/export/list", svcCtx.SessionOnly, handler.AccountExportListHandler(svcCtx))
+		auth.POST("/user/export/download", svcCtx.SessionOnly, handler.AccountExportDownloadHandler(svcCtx))
+		auth.POST("/user/audit/list", svcCtx.SessionOnly, handler.AuditLogListHandler(svcCtx))
+		auth.POST("/user/audit/export", svcCtx.SessionOnly, handler.AuditLogExportHandler(svcCtx))
+		auth.POST("/user/delete", svcCtx.SessionOnly, handler.AccountDeleteHandler(svcCtx))
+		auth.POST("/user/delete/cancel", svcCtx.SessionOnly, handler.AccountDeleteCancelHandler(svcCtx))
+		auth.POST("/user/2fa/setup", svcCtx.SessionOnly, handler.TwoFactorSetupHandler(svcCtx))
+		auth.POST("/user/2fa/enable", svcCtx.SessionOnly, handler.TwoFactorEnableHandler(svcCtx))
+		auth.POST("/user/2fa/disable", svcCtx.SessionOnly, handler.TwoFactorDisableHandler(svcCtx))
+		auth.POST("/user/2fa/recovery/regenerate", svcCtx.SessionOnly, handler.TwoFactorRecoveryRegenerateHandler(svcCtx))
+		auth.POST("/user/passkey/register/begin", svcCtx.SessionOnly, handler.PasskeyRegisterBeginHandler(svcCtx))
+		auth.POST("/user/passkey/register/finish", svcCtx.SessionOnly, handler.PasskeyRegisterFinishHandler(svcCtx))
+		auth.POST("/user/passkey/list", svcCtx.SessionOnly, handler.PasskeyListHandler(svcCtx))
+		auth.POST("/user/passkey/rename", svcCtx.SessionOnly, handler.PasskeyRenameHandler(svcCtx))
+		auth.POST("/user/passkey/delete", svcCtx.SessionOnly, handler.PasskeyDeleteHandler(svcCtx))
+		auth.POST("/file/upload", svcCtx.Scope(define.ScopeFilesWrite), svcCtx.Space(define.SpaceRoleEditor), handler.FileUploadHandler(svcCtx))
+		auth.GET("/file/download", svcCtx.Scope(define.ScopeFilesRead), svcCtx.Space(define.SpaceRoleViewer), handler.FileDownloadHandler(svcCtx))
+		auth.POST("/user/repository/save", svcCtx.Scope(define.ScopeFilesWrite), svcCtx.Space(define.SpaceRoleEditor), handler.UserRepositorySaveHandler(svcCtx))
+		auth.POST("/user/file/list", svcCtx.Scope(define.ScopeFilesRead), svcCtx.Space(define.SpaceRoleViewer), handler.UserFileListHandler(svcCtx))
+		auth.POST("/user/file/search", svcCtx.Scope(define.ScopeFilesRead), svcCtx.Space(define.SpaceRoleViewer), handler.UserFileSearchHandler(svcCtx))
+		auth.POST("/user/folder/list", svcCtx.Scope(define.ScopeFilesRead), svcCtx.Space(define.SpaceRoleViewer), handler.UserFolderListHandler(svcCtx))
+		auth.POST("/user/file/name/update", svcCtx.Scope(define.ScopeFilesWrite), svcCtx.Space(define.SpaceRoleEditor), handler.UserFileNameUpdateHandler(svcCtx))
+		auth.POST("/user/folder/create", svcCtx.Scope(define.ScopeFilesWrite), svcCtx.Space(define.SpaceRoleEditor), handler.UserFolderCreateHandler(svcCtx))
+		auth.DELETE("/user/file/delete", svcCtx.Scope(define.ScopeFilesWrite), svcCtx.Space(define.SpaceRoleEditor), handler.UserFileDeleteHandler(svcCtx))
+		auth.PUT("/user/file/move", svcCtx.Scope(define.ScopeFilesWrite), svcCtx.Space(define.SpaceRoleEditor), handler.UserFileMoveHandler(svcCtx))
+		auth.POST("/share/basic/create", svcCtx.Scope(define.ScopeSharesManage), svcCtx.Space(define.SpaceRoleEditor), handler.ShareBasicCreateHandler(svcCtx))
+		auth.POST("/share/basic/save", svcCtx.Scope(define.ScopeSharesManage, define.ScopeFilesWrite), svcCtx.Space(define.SpaceRoleEditor), handler.ShareBasicSaveHandler(svcCtx))
+		auth.POST("/share/basic/revoke", svcCtx.Scope(define.ScopeSharesManage), svcCtx.Space(define.SpaceRoleViewer), handler.ShareBasicRevokeHandler(svcCtx))
+		auth.POST("/share/basic/access/list", svcCtx.Scope(define.ScopeSharesManage), svcCtx.Space(define.SpaceRoleViewer), handler.ShareBasicAccessListHandler(svcCtx))
+		auth.POST("/user/session/list", svcCtx.SessionOnly, handler.UserSessionListHandler(svcCtx))
+		auth.POST("/user/session/revoke", svcCtx.SessionOnly, handler.UserSessionRevokeHandler(svcCtx))
+		auth.POST("/user/session/revoke/others", svcCtx.SessionOnly, handler.UserSessionRevokeOthersHandler(svcCtx))
+		auth.POST("/user/logout/all", svcCtx.SessionOnly, handler.UserLogoutAllHandler(svcCtx))
+		auth.POST("/user/token/create", svcCtx.SessionOnly, handler.AccessTokenCreateHandler(svcCtx))
+		auth.POST("/user/token/list", svcCtx.SessionOnly, handler.AccessTokenListHandler(svcCtx))
+		auth.POST("/user/token/revoke", svcCtx.SessionOnly, handler.AccessTokenRevokeHandler(svcCtx))
+		auth.POST("/file/upload/prepare", svcCtx.Scope(define.ScopeFilesWrite), svcCtx.Space(define.SpaceRoleEditor), handler.FileUploadPrepareHandler(svcCtx))
+		auth.POST("/file/upload/chunk", svcCtx.Scope(define.ScopeFilesWrite), svcCtx.Space(define.SpaceRoleEditor), handler.FileUploadChunkHandler(svcCtx))
+		auth.POST("/file/upload/chunk/complete", svcCtx.Scope(define.ScopeFilesWrite), svcCtx.Space(define.SpaceRoleEditor), handler.FileUploadChunkCompleteHandler(svcCtx))
 
 		// Friend system endpoints
-		auth.POST("/friend/request/send", handler.FriendRequestSendHandler(svcCtx))
-		auth.POST("/friend/request/list", handler.FriendRequestListHandler(svcCtx))
-		auth.POST("/friend/request/respond", handler.FriendRequestRespondHandler(svcCtx))
-		auth.POST("/friend/list", handler.FriendListHandler(svcCtx))
-		auth.POST("/friend/share/create", handler.FriendShareCreateHandler(svcCtx))
-		auth.POST("/friend/share/list", handler.FriendShareListHandler(svcCtx))
-		auth.POST("/friend/share/mark-read", handler.FriendShareMarkReadHandler(svcCtx))
-		auth.GET("/friend/share/download", handler.FriendShareDownloadHandler(svcCtx))
-		auth.POST("/friend/share/save", handler.FriendShareSaveHandler(svcCtx))
+		auth.POST("/friend/request/send", svcCtx.Scope(define.ScopeFriends), handler.FriendRequestSendHandler(svcCtx))
+		auth.POST("/friend/request/list", svcCtx.Scope(define.ScopeFriends), handler.FriendRequestListHandler(svcCtx))
+		auth.POST("/friend/request/respond", svcCtx.Scope(define.ScopeFriends), handler.FriendRequestRespondHandler(svcCtx))
+		auth.POST("/friend/list", svcCtx.Scope(define.ScopeFriends), handler.FriendListHandler(svcCtx))
+		auth.POST("/friend/remove", svcCtx.Scope(define.ScopeFriends), handler.FriendRemoveHandler(svcCtx))
+		auth.POST("/friend/block", svcCtx.Scope(define.ScopeFriends), handler.FriendBlockHandler(svcCtx))
+		auth.POST("/friend/unblock", svcCtx.Scope(define.ScopeFriends), handler.FriendUnblockHandler(svcCtx))
+		auth.POST("/friend/block/list", svcCtx.Scope(define.ScopeFriends), handler.FriendBlockListHandler(svcCtx))
+		auth.POST("/friend/share/create", svcCtx.Scope(define.ScopeSharesManage), svcCtx.Space(define.SpaceRoleEditor), handler.FriendShareCreateHandler(svcCtx))
+		auth.POST("/friend/share/list", svcCtx.Scope(define.ScopeSharesManage), handler.FriendShareListHandler(svcCtx))
+		auth.POST("/friend/share/mark-read", svcCtx.Scope(define.ScopeSharesManage), handler.FriendShareMarkReadHandler(svcCtx))
+		auth.GET("/friend/share/download", svcCtx.Scope(define.ScopeFilesRead), handler.FriendShareDownloadHandler(svcCtx))
+		auth.POST("/friend/share/save", svcCtx.Scope(define.ScopeSharesManage, define.ScopeFilesWrite), svcCtx.Space(define.SpaceRoleEditor), handler.FriendShareSaveHandler(svcCtx))
+		auth.POST("/friend/share/folder/list", svcCtx.Scope(define.ScopeSharesManage), handler.FriendShareFolderListHandler(svcCtx))
+		auth.POST("/friend/share/folder/create", svcCtx.Scope(define.ScopeSharesManage, define.ScopeFilesWrite), handler.FriendShareFolderCreateHandler(svcCtx))
+		auth.POST("/friend/share/file/save", svcCtx.Scope(define.ScopeSharesManage, define.ScopeFilesWrite), handler.FriendShareFileSaveHandler(svcCtx))
+		auth.POST("/friend/share/file/rename", svcCtx.Scope(define.ScopeSharesManage, define.ScopeFilesWrite), handler.FriendShareFileRenameHandler(svcCtx))
+		auth.POST("/friend/share/file/delete", svcCtx.Scope(define.ScopeSharesManage, define.ScopeFilesWrite), handler.FriendShareFileDeleteHandler(svcCtx))
+		auth.POST("/friend/share/permission/update", svcCtx.Scope(define.ScopeSharesManage), handler.FriendSharePermissionUpdateHandler(svcCtx))
+		auth.POST("/friend/share/expiry/update", svcCtx.Scope(define.ScopeSharesManage), handler.FriendShareExpiryUpdateHandler(svcCtx))
+		auth.POST("/friend/share/state/update", svcCtx.Scope(define.ScopeSharesManage), handler.FriendShareStateUpdateHandler(svcCtx))
+		auth.POST("/friend/share/revoke", svcCtx.Scope(define.ScopeSharesManage), handler.FriendShareRevokeHandler(svcCtx))
+		auth.POST("/friend/share/invite/create", svcCtx.Scope(define.ScopeSharesManage), handler.FriendShareInviteCreateHandler(svcCtx))
+		auth.POST("/friend/share/invite/list", svcCtx.Scope(define.ScopeSharesManage), handler.FriendShareInviteListHandler(svcCtx))
+		auth.POST("/friend/share/invite/revoke", svcCtx.Scope(define.ScopeSharesManage), handler.FriendShareInviteRevokeHandler(svcCtx))
+		auth.POST("/friend/share/invite/received", svcCtx.Scope(define.ScopeSharesManage), handler.FriendShareInviteReceivedHandler(svcCtx))
+		auth.POST("/friend/share/invite/accept", svcCtx.Scope(define.ScopeSharesManage), handler.FriendShareInviteAcceptHandler(svcCtx))
+
+		// Team space endpoints
+		auth.POST("/space/create", svcCtx.Scope(define.ScopeFilesWrite), handler.SpaceCreateHandler(svcCtx))
+		auth.POST("/space/list", svcCtx.Scope(define.ScopeFilesRead), handler.SpaceListHandler(svcCtx))
+		auth.POST("/space/member/list", svcCtx.Scope(define.ScopeFilesRead), handler.SpaceMemberListHandler(svcCtx))
+		auth.POST("/space/member/add", svcCtx.Scope(define.ScopeFilesWrite), handler.SpaceMemberAddHandler(svcCtx))
+		auth.POST("/space/member/update", svcCtx.Scope(define.ScopeFilesWrite), handler.SpaceMemberUpdateHandler(svcCtx))
+		auth.POST("/space/member/remove", svcCtx.Scope(define.ScopeFilesWrite), handler.SpaceMemberRemoveHandler(svcCtx))
+		auth.POST("/space/delete", svcCtx.Scope(define.ScopeFilesWrite), handler.SpaceDeleteHandler(svcCtx))
+		auth.POST("/space/invite/list", svcCtx.Scope(define.ScopeFilesRead), handler.SpaceInviteListHandler(svcCtx))
+		auth.POST("/space/invite/respond", svcCtx.Scope(define.ScopeFilesWrite), handler.SpaceInviteRespondHandler(svcCtx))
+
+		// Notification center endpoints
+		auth.POST("/events/ticket", svcCtx.SessionOnly, handler.EventTicketHandler(svcCtx))
+		auth.POST("/notification/list", svcCtx.SessionOnly, handler.NotificationListHandler(svcCtx))
+		auth.POST("/notification/read", svcCtx.SessionOnly, handler.NotificationReadHandler(svcCtx))
+		auth.POST("/notification/read/all", svcCtx.SessionOnly, handler.NotificationReadAllHandler(svcCtx))
+		auth.POST("/notification/delete", svcCtx.SessionOnly, handler.NotificationDeleteHandler(svcCtx))
+		auth.POST("/notification/preference/list", svcCtx.SessionOnly, handler.NotificationPreferenceListHandler(svcCtx))
+		auth.POST("/notification/preference/update", svcCtx.SessionOnly, handler.NotificationPreferenceUpdateHandler(svcCtx))
 
 		// Storage purchase endpoints
-		auth.POST("/storage/purchase/create", handler.StoragePurchaseCreateHandler(svcCtx))
-		auth.POST("/storage/order/list", handler.StorageOrderListHandler(svcCtx))
+		auth.POST("/storage/purchase/create", svcCtx.SessionOnly, handler.StoragePurchaseCreateHandler(svcCtx))
+		auth.POST("/storage/order/list", svcCtx.SessionOnly, handler.StorageOrderListHandler(svcCtx))
+	}
+
+	// Admin API. Support staff can look accounts up and log users out; changes
+	// to accounts and shares need the admin role on top.
+	admin := r.Group("/admin")
+	admin.Use(svcCtx.Auth, svcCtx.RateLimit, svcCtx.SessionOnly, svcCtx.Role(define.UserRoleSupport))
+	{
+		admin.POST("/user/list", handler.AdminUserListHandler(svcCtx))
+		admin.POST("/user/detail", handler.AdminUserDetailHandler(svcCtx))
+		admin.POST("/user/logout", handler.AdminUserLogoutHandler(svcCtx))
+		admin.POST("/user/volume/update", svcCtx.Role(define.UserRoleAdmin), handler.AdminUserVolumeUpdateHandler(svcCtx))
+		admin.POST("/user/suspend", svcCtx.Role(define.UserRoleAdmin), handler.AdminUserSuspendHandler(svcCtx))
+		admin.POST("/user/unsuspend", svcCtx.Role(define.UserRoleAdmin), handler.AdminUserUnsuspendHandler(svcCtx))
+		admin.POST("/user/role/update", svcCtx.Role(define.UserRoleAdmin), handler.AdminUserRoleUpdateHandler(svcCtx))
+		admin.POST("/share/list", handler.AdminShareListHandler(svcCtx))
+		admin.POST("/share/takedown", svcCtx.Role(define.UserRoleAdmin), handler.AdminShareTakedownHandler(svcCtx))
+		admin.POST("/order/list", handler.AdminOrderListHandler(svcCtx))
+		admin.POST("/action/list", svcCtx.Role(define.UserRoleAdmin), handler.AdminActionListHandler(svcCtx))
+		admin.POST("/audit/list", svcCtx.Role(define.UserRoleAdmin), handler.AdminAuditLogListHandler(svcCtx))
+		admin.POST("/audit/export", svcCtx.Role(define.UserRoleAdmin), handler.AdminAuditLogExportHandler(svcCtx))
 	}
 }
@@ -3,10 +3,18 @@ package svc
 import (
 	"context"
 	"fmt"
+	"net/url"
+	"time"
 
 	"cloud-dist/core/define"
+	"cloud-dist/core/events"
 	"cloud-dist/core/internal/middleware"
+	"cloud-dist/core/keyring"
 	"cloud-dist/core/models"
+	"cloud-dist/core/oidc"
+	"cloud-dist/core/ratelimit"
+	"cloud-dist/core/tokenversion"
+	"cloud-dist/core/webauthn"
 	appcfg "cloud-dist/internal/config"
 
 	"github.com/gin-gonic/gin"
@@ -15,10 +23,23 @@ import (
 )
 
 type ServiceContext struct {
-	Config appcfg.Config
-	DB     *gorm.DB
-	RDB    *redis.Client
-	Auth   gin.HandlerFunc
+	Config        appcfg.Config
+	DB            *gorm.DB
+	RDB           *redis.Client
+	Auth          gin.HandlerFunc
+	OptionalAuth  gin.HandlerFunc
+	StreamAuth    gin.HandlerFunc                                // Auth that also takes a single-use stream ticket
+	Revalidate    func(context.Context, *define.UserClaim) error // Re-checks a token on long-lived connections
+	Space         func(role string) gin.HandlerFunc
+	Role          func(role string) gin.HandlerFunc      // Account role needed for the admin API
+	Scope         func(scopes ...string) gin.HandlerFunc // Scopes a personal access token needs
+	SessionOnly   gin.HandlerFunc                        // Refuses personal access tokens
+	RateLimit     gin.HandlerFunc                        // Per-route request budgets, after authentication
+	Events        *events.Bus
+	TokenVersions *tokenversion.Store
+	Keys          *keyring.Ring
+	OIDC          *oidc.Provider // nil when SSO is not configured
+	WebAuthn      *webauthn.RelyingParty
 }
 
 func NewServiceContext(c appcfg.Config) (*ServiceContext, error) {
@@ -44,8 +65,27 @@ func NewServiceContext(c appcfg.Config) (*ServiceContext, error) {
 		c.SendGrid.FromEmail,
 	)
 
+	// Initialize frontend links (environment variables take precedence)
+	define.InitFrontendConfig(c.Frontend.BaseURL)
+
 	// Initialize JWT configuration from config file (environment variables take precedence)
-	define.InitJWTConfig(c.JWT.Key)
+	if err = define.InitJWTConfig(c.JWT.Key, c.JWT.RotationDays, c.Development()); err != nil {
+		return nil, err
+	}
+
+	// Load the JWT signing keys, creating the first one on a fresh database
+	keys := keyring.New(db, rdb, define.JwtKey, define.JwtKeyRotation)
+	if err = keys.Rotate(context.Background()); err != nil {
+		return nil, fmt.Errorf("init signing keys: %w", err)
+	}
+	keyring.SetDefault(keys)
+
+	// Initialize share download configuration (environment variables take precedence)
+	define.InitShareConfig(
+		c.Share.DownloadMode,
+		c.Share.DownloadTokenSecret,
+		c.Share.DownloadTokenTTLSeconds,
+	)
 
 	// Initialize Stripe configuration from config file (environment variables take precedence)
 	define.InitStripeConfig(
@@ -53,18 +93,97 @@ func NewServiceContext(c appcfg.Config) (*ServiceContext, error) {
 		c.Stripe.WebhookSecret,
 	)
 
+	// SSO provider metadata is fetched on first use
+	scopes := c.OIDC.Scopes
+	if len(scopes) == 0 {
+		scopes = []string{"openid", "profile", "email"}
+	}
+	oidcProvider := oidc.New(oidc.Config{
+		Issuer:       c.OIDC.Issuer,
+		ClientID:     c.OIDC.ClientID,
+		ClientSecret: c.OIDC.ClientSecret,
+		RedirectURL:  c.OIDC.RedirectURL,
+		Scopes:       scopes,
+	}, nil)
+
+	// Passkeys are scoped to the frontend's domain unless configured otherwise
+	rpID, origins := c.WebAuthn.RPID, c.WebAuthn.Origins
+	if len(origins) == 0 {
+		origins = []string{define.FrontendBaseURL}
+	}
+	if rpID == "" {
+		if u, err := url.Parse(define.FrontendBaseURL); err == nil {
+			rpID = u.Hostname()
+		}
+	}
+	relyingParty := webauthn.New(webauthn.Config{RPID: rpID, RPName: "CloudDist", Origins: origins})
+
+	rateLimit := func(c *gin.Context) { c.Next() }
+	if !c.RateLimit.Disabled {
+		def, routes := rateLimitPolicies(c.RateLimit)
+		rateLimit = ratelimit.NewMiddleware(db, rdb, def, routes).Handle
+	}
+
 	// Create auth middleware with Redis client for token blacklist
 	authMiddleware := middleware.NewAuthMiddleware()
 	authMiddleware.SetRedisClient(rdb)
+	authMiddleware.SetDB(db)
+	tokenVersions := tokenversion.NewStore(db, rdb)
+	authMiddleware.SetTokenVersions(tokenVersions)
 
 	return &ServiceContext{
-		Config: c,
-		DB:     db,
-		RDB:    rdb,
-		Auth:   authMiddleware.Handle,
+		Config:        c,
+		DB:            db,
+		RDB:           rdb,
+		Auth:          authMiddleware.Handle,
+		OptionalAuth:  authMiddleware.HandleOptional,
+		StreamAuth:    authMiddleware.HandleStreamTicket,
+		Revalidate:    authMiddleware.Revalidate,
+		Space:         middleware.NewSpaceMiddleware(db).Require,
+		Role:          middleware.NewRoleMiddleware(db).Require,
+		Scope:         middleware.RequireScope,
+		SessionOnly:   middleware.RequireSession,
+		RateLimit:     rateLimit,
+		Events:        events.NewBus(rdb),
+		TokenVersions: tokenVersions,
+		Keys:          keys,
+		OIDC:          oidcProvider,
+		WebAuthn:      relyingParty,
 	}, nil
 }
 
+// defaultRateLimitRoutes guard the routes that cost the most to serve when
+// none are configured
+var defaultRateLimitRoutes = map[string]appcfg.RateLimitPolicy{
+	"/file/upload/prepare": {Limit: 30, Plans: map[string]int{define.RatePlanPaid: 120}},
+	"/user/file/search":    {Limit: 60, Plans: map[string]int{define.RatePlanPaid: 240}},
+	"/share/basic/detail":  {Limit: 60, Plans: map[string]int{define.RatePlanPaid: 240}},
+}
+
+func rateLimitPolicies(c appcfg.RateLimitConfig) (ratelimit.Policy, map[string]ratelimit.Policy) {
+	policy := func(p appcfg.RateLimitPolicy) ratelimit.Policy {
+		window := time.Minute
+		if p.WindowSeconds > 0 {
+			window = time.Duration(p.WindowSeconds) * time.Second
+		}
+		return ratelimit.Policy{Limit: p.Limit, Window: window, Plans: p.Plans}
+	}
+
+	def := c.Default
+	if def.Limit == 0 {
+		def.Limit = 600
+	}
+	configured := c.Routes
+	if len(configured) == 0 {
+		configured = defaultRateLimitRoutes
+	}
+	routes := make(map[string]ratelimit.Policy, len(configured))
+	for path, p := range configured {
+		routes[path] = policy(p)
+	}
+	return policy(def), routes
+}
+
 func (s *ServiceContext) Close(ctx context.Context) error {
 	if s.DB != nil {
 		sqlDB, err := s.DB.DB()
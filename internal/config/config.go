@@ -2,18 +2,78 @@ package config
 
 // Config defines runtime settings for the Gin application.
 type Config struct {
-	Name     string         `mapstructure:"Name"`
-	Host     string         `mapstructure:"Host"`
-	Port     int            `mapstructure:"Port"`
-	MaxBytes int64          `mapstructure:"MaxBytes"`
-	Mysql    MysqlConfig    `mapstructure:"Mysql"`
-	Redis    RedisConfig    `mapstructure:"Redis"`
-	Log      LogConfig      `mapstructure:"Log"`
-	HTTP     HTTPSettings   `mapstructure:"HTTP"`
-	S3       S3Config       `mapstructure:"S3"`
-	SendGrid SendGridConfig `mapstructure:"SendGrid"`
-	JWT      JWTConfig      `mapstructure:"JWT"`
-	Stripe   StripeConfig   `mapstructure:"Stripe"`
+	Name      string          `mapstructure:"Name"`
+	Mode      string          `mapstructure:"Mode"` // development relaxes startup checks such as the default JWT key
+	Host      string          `mapstructure:"Host"`
+	Port      int             `mapstructure:"Port"`
+	MaxBytes  int64           `mapstructure:"MaxBytes"`
+	Frontend  FrontendConfig  `mapstructure:"Frontend"`
+	Mysql     MysqlConfig     `mapstructure:"Mysql"`
+	Redis     RedisConfig     `mapstructure:"Redis"`
+	Log       LogConfig       `mapstructure:"Log"`
+	HTTP      HTTPSettings    `mapstructure:"HTTP"`
+	S3        S3Config        `mapstructure:"S3"`
+	SendGrid  SendGridConfig  `mapstructure:"SendGrid"`
+	JWT       JWTConfig       `mapstructure:"JWT"`
+	Stripe    StripeConfig    `mapstructure:"Stripe"`
+	Share     ShareConfig     `mapstructure:"Share"`
+	OIDC      OIDCConfig      `mapstructure:"OIDC"`
+	WebAuthn  WebAuthnConfig  `mapstructure:"WebAuthn"`
+	RateLimit RateLimitConfig `mapstructure:"RateLimit"`
+}
+
+// Development reports whether the service runs in development mode
+func (c Config) Development() bool {
+	return c.Mode == "development"
+}
+
+// ShareConfig carries share link settings.
+type ShareConfig struct {
+	AccessLogRetentionDays  int    `mapstructure:"AccessLogRetentionDays"`  // 0 keeps access events forever
+	DownloadMode            string `mapstructure:"DownloadMode"`            // proxy (default) or presigned
+	DownloadTokenSecret     string `mapstructure:"DownloadTokenSecret"`     // HMAC key for download tokens, defaults to the JWT key
+	DownloadTokenTTLSeconds int    `mapstructure:"DownloadTokenTTLSeconds"` // Lifetime of a download token, default 900
+}
+
+// OIDCConfig carries the OpenID Connect single sign-on registration.
+// Leaving Issuer empty disables SSO login.
+type OIDCConfig struct {
+	Issuer        string   `mapstructure:"Issuer"` // e.g. https://login.example.com/realms/staff
+	ClientID      string   `mapstructure:"ClientID"`
+	ClientSecret  string   `mapstructure:"ClientSecret"`
+	RedirectURL   string   `mapstructure:"RedirectURL"`   // Frontend page that receives code and state
+	Scopes        []string `mapstructure:"Scopes"`        // Default openid, profile, email
+	DisableSignup bool     `mapstructure:"DisableSignup"` // Only existing or linked accounts may sign in
+}
+
+// WebAuthnConfig carries the passkey relying party. Both fields default to
+// the frontend base URL.
+type WebAuthnConfig struct {
+	RPID    string   `mapstructure:"RPID"`    // Registrable domain, e.g. example.com
+	Origins []string `mapstructure:"Origins"` // Allowed origins, e.g. https://app.example.com
+}
+
+// RateLimitConfig carries request rate limits. Routes maps a route path, as
+// registered in the router, to its own budget; every other route shares the
+// Default budget. Viper lowercases map keys, which is harmless as all route
+// paths are lowercase.
+type RateLimitConfig struct {
+	Disabled bool                       `mapstructure:"Disabled"`
+	Default  RateLimitPolicy            `mapstructure:"Default"` // Default 600 requests per minute
+	Routes   map[string]RateLimitPolicy `mapstructure:"Routes"`  // Defaults cover upload prepare, file search and share detail
+}
+
+// RateLimitPolicy allows Limit requests per WindowSeconds. Plans overrides
+// Limit per plan: anonymous, free or paid.
+type RateLimitPolicy struct {
+	Limit         int            `mapstructure:"Limit"`
+	WindowSeconds int            `mapstructure:"WindowSeconds"` // Default 60
+	Plans         map[string]int `mapstructure:"Plans"`
+}
+
+// FrontendConfig carries settings for links that point back to the web app.
+type FrontendConfig struct {
+	BaseURL string `mapstructure:"BaseURL"` // e.g. https://app.example.com, default http://localhost:3000
 }
 
 // StripeConfig carries Stripe payment configuration.
@@ -24,7 +84,8 @@ type StripeConfig struct {
 
 // JWTConfig carries JWT configuration.
 type JWTConfig struct {
-	Key string `mapstructure:"Key"`
+	Key          string `mapstructure:"Key"`          // Encrypts the signing keys at rest, required outside development mode
+	RotationDays int    `mapstructure:"RotationDays"` // Signing key lifetime before rotation, default 30
 }
 
 // MysqlConfig carries datasource info for the ORM layer.
@@ -48,9 +109,12 @@ type LogConfig struct {
 }
 
 // HTTPSettings allows future Gin-specific tuning; optional in config files.
+// The client IP, which rate limits and login lockouts key on, is read from
+// X-Forwarded-For only when the request comes from one of TrustedProxies.
 type HTTPSettings struct {
-	ReadTimeoutSeconds  int `mapstructure:"ReadTimeoutSeconds"`
-	WriteTimeoutSeconds int `mapstructure:"WriteTimeoutSeconds"`
+	ReadTimeoutSeconds  int      `mapstructure:"ReadTimeoutSeconds"`
+	WriteTimeoutSeconds int      `mapstructure:"WriteTimeoutSeconds"`
+	TrustedProxies      []string `mapstructure:"TrustedProxies"` // IPs or CIDRs of the load balancers in front, default none
 }
 
 // S3Config carries AWS S3 configuration.
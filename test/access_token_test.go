@@ -0,0 +1,26 @@
+package test
+
+import (
+	"strings"
+	"testing"
+
+	"cloud-dist/core/define"
+	"cloud-dist/core/helper"
+)
+
+func TestAccessTokenFormat(t *testing.T) {
+	a, err := helper.NewAccessToken()
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, _ := helper.NewAccessToken()
+	if !strings.HasPrefix(a, define.AccessTokenPrefix) {
+		t.Fatalf("token %q lacks the %s prefix", a, define.AccessTokenPrefix)
+	}
+	if a == b {
+		t.Fatal("two tokens are equal")
+	}
+	if helper.HashAccessToken(a) != helper.HashAccessToken(a) || helper.HashAccessToken(a) == helper.HashAccessToken(b) {
+		t.Fatal("hash is not a stable function of the token")
+	}
+}
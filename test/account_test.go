@@ -0,0 +1,284 @@
+package test
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+
+	"cloud-dist/core/account"
+
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+	"gorm.io/gorm/schema"
+)
+
+func TestAccountPseudonym(t *testing.T) {
+	a := account.Pseudonym("user-a")
+	if a != account.Pseudonym("user-a") {
+		t.Fatal("pseudonym is not stable")
+	}
+	if !strings.HasPrefix(a, "deleted:") || strings.Contains(a, "user-a") {
+		t.Fatalf("pseudonym %q leaks the identity or lacks the prefix", a)
+	}
+	if a == account.Pseudonym("user-b") {
+		t.Fatal("different users share a pseudonym")
+	}
+}
+
+func TestAccountReleaseBlobsKeepsReferencedBlobs(t *testing.T) {
+	f := &fakeSQL{query: func(q string, args []driver.NamedValue) ([]string, [][]driver.Value) {
+		switch {
+		case strings.Contains(q, "count(*)"):
+			if args[0].Value == "blob-shared" {
+				return []string{"count"}, [][]driver.Value{{int64(1)}}
+			}
+			return []string{"count"}, [][]driver.Value{{int64(0)}}
+		case strings.Contains(q, "FROM `repository_pool`"):
+			path := "files/" + args[0].Value.(string)
+			if args[0].Value == "blob-url" {
+				path = "https://old.example.com/file"
+			}
+			return []string{"id", "identity", "path"}, [][]driver.Value{{int64(1), args[0].Value, path}}
+		}
+		return []string{"id"}, nil
+	}}
+
+	var removed []string
+	err := account.ReleaseBlobs(context.Background(), newFakeGorm(t, f),
+		[]string{"blob-own", "blob-shared", "blob-own", "", "blob-url"},
+		func(key string) error {
+			removed = append(removed, key)
+			return nil
+		})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(removed) != 1 || removed[0] != "files/blob-own" {
+		t.Fatalf("removed %v, want only files/blob-own", removed)
+	}
+	if n := f.count("count(*)"); n != 3 {
+		t.Fatalf("counted references %d times, want once per distinct blob", n)
+	}
+	if n := f.count("FROM `repository_pool`"); n != 2 {
+		t.Fatalf("loaded %d pool rows, want 2: the shared blob must not be touched", n)
+	}
+}
+
+func TestAccountDeleteCascade(t *testing.T) {
+	f := &fakeSQL{query: func(q string, args []driver.NamedValue) ([]string, [][]driver.Value) {
+		switch {
+		case strings.Contains(q, "FROM `space`"):
+			return []string{"identity"}, [][]driver.Value{{"space-1"}}
+		case strings.Contains(q, "count(*)"):
+			if args[0].Value == "blob-shared" {
+				return []string{"count"}, [][]driver.Value{{int64(1)}}
+			}
+			return []string{"count"}, [][]driver.Value{{int64(0)}}
+		case strings.Contains(q, "FROM `user_repository`"):
+			return []string{"repository_identity"}, [][]driver.Value{{"blob-own"}, {"blob-shared"}}
+		case strings.Contains(q, "FROM `account_export`"):
+			return []string{"path"}, [][]driver.Value{{"exports/user-1.zip"}}
+		case strings.Contains(q, "FROM `share_basic`"):
+			return []string{"identity"}, [][]driver.Value{{"share-1"}}
+		case strings.Contains(q, "FROM `repository_pool`"):
+			return []string{"id", "identity", "path"}, [][]driver.Value{{int64(1), args[0].Value, "files/" + args[0].Value.(string)}}
+		}
+		return []string{"id"}, nil
+	}}
+
+	var removed []string
+	err := account.Delete(context.Background(), newFakeGorm(t, f), "user-1", func(key string) error {
+		removed = append(removed, key)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, table := range []string{
+		"space_member", "space_invite", "space", "share_access_log", "user_repository", "share_basic",
+		"friend", "friend_request", "friend_share", "share_invite", "notification",
+		"notification_preference", "user_session", "personal_access_token",
+		"webauthn_credential", "user_recovery_code", "user_external_identity",
+		"email_change", "login_lockout", "account_export", "user_basic",
+	} {
+		if f.count("DELETE FROM `"+table+"`") == 0 {
+			t.Errorf("nothing deleted from %s", table)
+		}
+	}
+
+	// Steps share a transaction but must not share conditions
+	users := f.execsMatching("DELETE FROM `user_basic`")
+	if len(users) != 1 || users[0].q != "DELETE FROM `user_basic` WHERE identity = ?" {
+		t.Errorf("user row deleted with %v", users)
+	}
+
+	orders := f.execsMatching("UPDATE `storage_orders`")
+	if len(orders) != 1 || !hasArg(orders[0].args, account.Pseudonym("user-1")) {
+		t.Errorf("storage orders were not moved to the pseudonym: %v", orders)
+	}
+
+	want := []string{"exports/user-1.zip", "files/blob-own"}
+	if strings.Join(removed, ",") != strings.Join(want, ",") {
+		t.Fatalf("removed %v, want %v", removed, want)
+	}
+}
+
+func TestAccountDeleteSpace(t *testing.T) {
+	f := &fakeSQL{query: func(q string, args []driver.NamedValue) ([]string, [][]driver.Value) {
+		switch {
+		case strings.HasPrefix(q, "SELECT `identity` FROM `share_basic`"):
+			return []string{"identity"}, [][]driver.Value{{"share-1"}}
+		case strings.Contains(q, "count(*)"):
+			return []string{"count"}, [][]driver.Value{{int64(0)}}
+		case strings.Contains(q, "FROM `user_repository`"):
+			return []string{"repository_identity"}, [][]driver.Value{{"blob-space"}}
+		case strings.Contains(q, "FROM `repository_pool`"):
+			return []string{"id", "identity", "path"}, [][]driver.Value{{int64(1), args[0].Value, "files/" + args[0].Value.(string)}}
+		}
+		return []string{"id"}, nil
+	}}
+
+	var removed []string
+	err := account.DeleteSpace(context.Background(), newFakeGorm(t, f), "space-1", func(key string) error {
+		removed = append(removed, key)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Links made by any member from the space's files go with the space
+	links := f.execsMatching("DELETE FROM `share_basic`")
+	if len(links) != 1 || !hasArg(links[0].args, "share-1") {
+		t.Errorf("share links deleted with %v", links)
+	}
+	for _, table := range []string{"share_access_log", "friend_share", "space_invite", "space_member", "user_repository", "space"} {
+		if f.count("DELETE FROM `"+table+"`") == 0 {
+			t.Errorf("nothing deleted from %s", table)
+		}
+	}
+	if f.count("user_basic") > 0 {
+		t.Error("deleting a space touched user accounts")
+	}
+	if len(removed) != 1 || removed[0] != "files/blob-space" {
+		t.Fatalf("removed %v, want files/blob-space", removed)
+	}
+}
+
+// fakeSQL is a database/sql driver that records statements and answers
+// queries from a callback, enough to run GORM code without MySQL
+type fakeSQL struct {
+	mu      sync.Mutex
+	queries []string
+	execs   []fakeExec
+	query   func(q string, args []driver.NamedValue) ([]string, [][]driver.Value)
+}
+
+type fakeExec struct {
+	q    string
+	args []driver.NamedValue
+}
+
+func newFakeGorm(t *testing.T, f *fakeSQL) *gorm.DB {
+	db, err := gorm.Open(mysql.New(mysql.Config{Conn: sql.OpenDB(f), SkipInitializeWithVersion: true}), &gorm.Config{
+		Logger:         logger.Default.LogMode(logger.Silent),
+		NamingStrategy: schema.NamingStrategy{SingularTable: true},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+// count returns how many statements of either kind contain s
+func (f *fakeSQL) count(s string) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	n := 0
+	for _, q := range f.queries {
+		if strings.Contains(q, s) {
+			n++
+		}
+	}
+	for _, e := range f.execs {
+		if strings.Contains(e.q, s) {
+			n++
+		}
+	}
+	return n
+}
+
+func (f *fakeSQL) execsMatching(s string) []fakeExec {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var out []fakeExec
+	for _, e := range f.execs {
+		if strings.Contains(e.q, s) {
+			out = append(out, e)
+		}
+	}
+	return out
+}
+
+func hasArg(args []driver.NamedValue, v any) bool {
+	for _, a := range args {
+		if a.Value == v {
+			return true
+		}
+	}
+	return false
+}
+
+func (f *fakeSQL) Connect(context.Context) (driver.Conn, error) { return &fakeConn{f}, nil }
+func (f *fakeSQL) Driver() driver.Driver                        { return fakeDriver{f} }
+
+type fakeDriver struct{ f *fakeSQL }
+
+func (d fakeDriver) Open(string) (driver.Conn, error) { return &fakeConn{d.f}, nil }
+
+type fakeConn struct{ f *fakeSQL }
+
+func (c *fakeConn) Prepare(string) (driver.Stmt, error) { return nil, driver.ErrSkip }
+func (c *fakeConn) Close() error                        { return nil }
+func (c *fakeConn) Begin() (driver.Tx, error)           { return c, nil }
+func (c *fakeConn) Commit() error                       { return nil }
+func (c *fakeConn) Rollback() error                     { return nil }
+
+func (c *fakeConn) ExecContext(_ context.Context, q string, args []driver.NamedValue) (driver.Result, error) {
+	c.f.mu.Lock()
+	c.f.execs = append(c.f.execs, fakeExec{q: q, args: args})
+	c.f.mu.Unlock()
+	return driver.RowsAffected(1), nil
+}
+
+func (c *fakeConn) QueryContext(_ context.Context, q string, args []driver.NamedValue) (driver.Rows, error) {
+	c.f.mu.Lock()
+	c.f.queries = append(c.f.queries, q)
+	c.f.mu.Unlock()
+	cols, rows := c.f.query(q, args)
+	return &fakeRows{cols: cols, rows: rows}, nil
+}
+
+type fakeRows struct {
+	cols []string
+	rows [][]driver.Value
+	next int
+}
+
+func (r *fakeRows) Columns() []string { return r.cols }
+func (r *fakeRows) Close() error      { return nil }
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if r.next >= len(r.rows) {
+		return io.EOF
+	}
+	copy(dest, r.rows[r.next])
+	r.next++
+	return nil
+}
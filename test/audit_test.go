@@ -0,0 +1,39 @@
+package test
+
+import (
+	"context"
+	"database/sql/driver"
+	"encoding/json"
+	"testing"
+
+	"cloud-dist/core/audit"
+	"cloud-dist/core/helper"
+)
+
+func TestAuditRecordMergesContextDetail(t *testing.T) {
+	f := &fakeSQL{query: func(string, []driver.NamedValue) ([]string, [][]driver.Value) {
+		return []string{"id"}, nil
+	}}
+	ctx := helper.WithClientMeta(context.Background(), helper.ClientMeta{UserIdentity: "friend-1"})
+	ctx = audit.WithDetail(ctx, map[string]interface{}{"friend_share": "fs-1"})
+	audit.Record(ctx, newFakeGorm(t, f), audit.Entry{Subject: "owner-1", Action: audit.ActionFileDelete,
+		TargetType: audit.TargetFile, Target: "file-1", Detail: map[string]interface{}{"name": "a.txt"}})
+
+	inserts := f.execsMatching("INSERT INTO `audit_log`")
+	if len(inserts) != 1 {
+		t.Fatalf("got %d inserts into audit_log, want 1", len(inserts))
+	}
+	args := inserts[0].args
+	if !hasArg(args, "friend-1") || !hasArg(args, "owner-1") {
+		t.Errorf("entry recorded with %v, want actor friend-1 and subject owner-1", args)
+	}
+	var detail map[string]interface{}
+	for _, a := range args {
+		if s, ok := a.Value.(string); ok && json.Unmarshal([]byte(s), &detail) == nil {
+			break
+		}
+	}
+	if detail["friend_share"] != "fs-1" || detail["name"] != "a.txt" {
+		t.Errorf("detail is %v, want both the context and the entry keys", detail)
+	}
+}
@@ -0,0 +1,39 @@
+package test
+
+import (
+	"testing"
+
+	"cloud-dist/core/events"
+)
+
+func TestEventIDOrdering(t *testing.T) {
+	cases := []struct {
+		a, b string
+		want bool
+	}{
+		{"1700000000000-1", "", true},
+		{"1700000000000-1", "1700000000000-0", true},
+		{"1700000000000-0", "1700000000000-1", false},
+		{"1700000000001-0", "1700000000000-9", true},
+		{"1700000000000-10", "1700000000000-9", true},
+		{"1700000000000-0", "1700000000000-0", false},
+	}
+	for _, c := range cases {
+		if got := events.After(c.a, c.b); got != c.want {
+			t.Errorf("After(%q, %q) = %v, want %v", c.a, c.b, got, c.want)
+		}
+	}
+}
+
+func TestEventIDValidation(t *testing.T) {
+	for _, id := range []string{"1700000000000-0", "0-1"} {
+		if !events.ValidID(id) {
+			t.Errorf("ValidID(%q) = false", id)
+		}
+	}
+	for _, id := range []string{"", "abc", "1700000000000", "-1", "1-x"} {
+		if events.ValidID(id) {
+			t.Errorf("ValidID(%q) = true", id)
+		}
+	}
+}
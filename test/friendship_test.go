@@ -0,0 +1,35 @@
+package test
+
+import (
+	"database/sql/driver"
+	"strings"
+	"testing"
+
+	"cloud-dist/core/friendship"
+)
+
+// B blocks A after A already blocked B: B's own row goes, A's block must stay
+func TestFriendshipSeverKeepsTheOtherSidesBlock(t *testing.T) {
+	f := &fakeSQL{query: func(string, []driver.NamedValue) ([]string, [][]driver.Value) {
+		return []string{"id"}, nil
+	}}
+	if err := friendship.Sever(newFakeGorm(t, f), "user-b", "user-a"); err != nil {
+		t.Fatal(err)
+	}
+
+	deletes := f.execsMatching("`friend`")
+	if len(deletes) != 2 {
+		t.Fatalf("got %d statements on friend, want 2: %v", len(deletes), deletes)
+	}
+	own, other := deletes[0], deletes[1]
+	if !hasArg(own.args, "user-b") || strings.Contains(own.q, "status") {
+		t.Errorf("caller's row removed with %q %v, want it gone in any state", own.q, own.args)
+	}
+	if !strings.Contains(other.q, "status <> ?") || !hasArg(other.args, friendship.StatusBlocked) {
+		t.Errorf("other user's row removed with %q %v, want their block kept", other.q, other.args)
+	}
+	// Rows are soft-deleted, so the first argument is deleted_at
+	if other.args[1].Value != "user-a" || other.args[2].Value != "user-b" {
+		t.Errorf("other user's row picked with %v, want user_identity user-a", other.args)
+	}
+}
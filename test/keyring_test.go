@@ -0,0 +1,72 @@
+package test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"cloud-dist/core/define"
+	"cloud-dist/core/helper"
+	"cloud-dist/core/keyring"
+)
+
+func newTestRing(t *testing.T) *keyring.Ring {
+	ring := keyring.New(nil, nil, "test-secret", 30*24*time.Hour)
+	if err := ring.Rotate(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	keyring.SetDefault(ring)
+	return ring
+}
+
+func TestKeyringSignAndVerify(t *testing.T) {
+	newTestRing(t)
+	token, err := helper.GenerateClaimsToken(define.UserClaim{Identity: "user-1", Name: "alice"}, 60)
+	if err != nil {
+		t.Fatal(err)
+	}
+	uc, err := helper.AnalyzeToken(token)
+	if err != nil {
+		t.Fatalf("valid token rejected: %v", err)
+	}
+	if uc.Identity != "user-1" {
+		t.Fatalf("identity = %q", uc.Identity)
+	}
+}
+
+func TestKeyringRemovedKeyStopsVerifying(t *testing.T) {
+	ring := newTestRing(t)
+	token, err := helper.GenerateToken(1, "user-1", "alice", 60)
+	if err != nil {
+		t.Fatal(err)
+	}
+	jwks := ring.JWKS()
+	if len(jwks.Keys) != 1 || jwks.Keys[0].Kty != "OKP" || jwks.Keys[0].Crv != "Ed25519" {
+		t.Fatalf("unexpected JWKS %+v", jwks)
+	}
+	ring.Remove(jwks.Keys[0].Kid)
+	if _, err = helper.AnalyzeToken(token); err == nil {
+		t.Fatal("token verified after its key was removed")
+	}
+}
+
+func TestKeyringNewKeyIsPublishedBeforeSigning(t *testing.T) {
+	ring := newTestRing(t)
+	old := ring.JWKS().Keys[0].Kid
+
+	// Pretend the current key was created long ago, then add a fresh one
+	k, _ := keyring.Generate(time.Now().Add(-29*24*time.Hour), 40*24*time.Hour)
+	ring.Remove(old)
+	ring.Add(k)
+	next, _ := keyring.Generate(time.Now(), 40*24*time.Hour)
+	ring.Add(next)
+
+	if len(ring.JWKS().Keys) != 2 {
+		t.Fatal("new key is not published")
+	}
+	token, _ := helper.GenerateToken(1, "user-1", "alice", 60)
+	ring.Remove(next.ID)
+	if _, err := helper.AnalyzeToken(token); err != nil {
+		t.Fatal("token was signed with the key that is not yet active")
+	}
+}
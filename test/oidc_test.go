@@ -0,0 +1,135 @@
+package test
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"cloud-dist/core/oidc"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// mockProvider is a minimal OpenID provider: discovery, JWKS and a token
+// endpoint that checks PKCE and returns an ID token with the given claims
+type mockProvider struct {
+	srv       *httptest.Server
+	key       *rsa.PrivateKey
+	challenge string
+	claims    jwt.MapClaims
+}
+
+func newMockProvider(t *testing.T) *mockProvider {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := &mockProvider{key: key}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]string{
+			"issuer":                 m.srv.URL,
+			"authorization_endpoint": m.srv.URL + "/authorize",
+			"token_endpoint":         m.srv.URL + "/token",
+			"jwks_uri":               m.srv.URL + "/jwks",
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		enc := base64.RawURLEncoding
+		json.NewEncoder(w).Encode(map[string]any{"keys": []map[string]string{{
+			"kty": "RSA", "kid": "k1", "use": "sig",
+			"n": enc.EncodeToString(key.N.Bytes()),
+			"e": enc.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		}}})
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		sum := sha256.Sum256([]byte(r.PostForm.Get("code_verifier")))
+		if r.PostForm.Get("code") != "good-code" || base64.RawURLEncoding.EncodeToString(sum[:]) != m.challenge {
+			http.Error(w, `{"error":"invalid_grant"}`, http.StatusBadRequest)
+			return
+		}
+		tok := jwt.NewWithClaims(jwt.SigningMethodRS256, m.claims)
+		tok.Header["kid"] = "k1"
+		signed, _ := tok.SignedString(key)
+		json.NewEncoder(w).Encode(map[string]any{"access_token": "at", "token_type": "Bearer", "id_token": signed})
+	})
+	m.srv = httptest.NewServer(mux)
+	t.Cleanup(m.srv.Close)
+	return m
+}
+
+func (m *mockProvider) login(t *testing.T, p *oidc.Provider, nonce string) (*oidc.Claims, error) {
+	ctx := context.Background()
+	verifier, challenge, err := oidc.NewPKCE()
+	if err != nil {
+		t.Fatal(err)
+	}
+	authURL, err := p.AuthCodeURL(ctx, "state-1", nonce, challenge)
+	if err != nil {
+		t.Fatal(err)
+	}
+	u, _ := url.Parse(authURL)
+	if u.Query().Get("code_challenge_method") != "S256" || !strings.Contains(u.Query().Get("scope"), "openid") {
+		t.Fatalf("unexpected authorization URL %s", authURL)
+	}
+	m.challenge = u.Query().Get("code_challenge")
+
+	token, err := p.Exchange(ctx, "good-code", verifier)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return p.VerifyIDToken(ctx, token.IDToken, nonce)
+}
+
+func (m *mockProvider) idClaims(nonce string) jwt.MapClaims {
+	now := time.Now()
+	return jwt.MapClaims{
+		"iss": m.srv.URL, "aud": "client-1", "sub": "sub-42", "nonce": nonce,
+		"iat": now.Unix(), "exp": now.Add(time.Hour).Unix(),
+		"email": "alice@example.com", "email_verified": true, "preferred_username": "alice",
+	}
+}
+
+func TestOIDCCodeFlow(t *testing.T) {
+	m := newMockProvider(t)
+	p := oidc.New(oidc.Config{Issuer: m.srv.URL, ClientID: "client-1", ClientSecret: "s", RedirectURL: "http://app/callback"}, m.srv.Client())
+	m.claims = m.idClaims("n-1")
+
+	claims, err := m.login(t, p, "n-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if claims.Subject != "sub-42" || claims.Email != "alice@example.com" || !claims.EmailVerified || claims.PreferredUsername != "alice" {
+		t.Fatalf("unexpected claims %+v", claims)
+	}
+}
+
+func TestOIDCRejectsBadIDTokens(t *testing.T) {
+	m := newMockProvider(t)
+	p := oidc.New(oidc.Config{Issuer: m.srv.URL, ClientID: "client-1", RedirectURL: "http://app/callback"}, m.srv.Client())
+
+	cases := map[string]func(jwt.MapClaims){
+		"nonce":    func(c jwt.MapClaims) { c["nonce"] = "other" },
+		"audience": func(c jwt.MapClaims) { c["aud"] = "someone-else" },
+		"issuer":   func(c jwt.MapClaims) { c["iss"] = "https://evil.example.com" },
+		"expired":  func(c jwt.MapClaims) { c["exp"] = time.Now().Add(-time.Hour).Unix() },
+	}
+	for name, mutate := range cases {
+		m.claims = m.idClaims("n-1")
+		mutate(m.claims)
+		if _, err := m.login(t, p, "n-1"); err == nil {
+			t.Errorf("%s: bad ID token accepted", name)
+		}
+	}
+}
@@ -0,0 +1,279 @@
+package test
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"cloud-dist/core/define"
+	"cloud-dist/core/ratelimit"
+	"cloud-dist/core/router"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestRateLimitPolicyForPlan(t *testing.T) {
+	p := ratelimit.Policy{Limit: 30, Window: time.Minute, Plans: map[string]int{define.RatePlanPaid: 120}}
+
+	if got := p.ForPlan(define.RatePlanPaid); got.Limit != 120 || got.Window != time.Minute {
+		t.Errorf("paid plan got %d per %v, want 120 per 1m", got.Limit, got.Window)
+	}
+	if got := p.ForPlan(define.RatePlanFree); got.Limit != 30 {
+		t.Errorf("free plan got %d, want the route limit 30", got.Limit)
+	}
+	if got := p.ForPlan(define.RatePlanAnonymous); got.Limit != 30 {
+		t.Errorf("anonymous plan got %d, want the route limit 30", got.Limit)
+	}
+}
+
+func TestRateLimiterUnlimitedPolicySkipsRedis(t *testing.T) {
+	res, err := ratelimit.New(nil).Allow(context.Background(), "k", ratelimit.Policy{})
+	if err != nil || !res.Allowed {
+		t.Fatalf("unlimited policy refused: %v %v", res, err)
+	}
+}
+
+// The limiter tests below run the GCRA script and need the Redis server
+// from redis_test.go
+func testRedis(t *testing.T) {
+	if err := rdb.Ping(ctx).Err(); err != nil {
+		t.Skipf("redis is not available: %v", err)
+	}
+}
+
+func TestRateLimiterBurstAndRefill(t *testing.T) {
+	testRedis(t)
+	l := ratelimit.New(rdb)
+	key := "test:burst:" + strconv.FormatInt(time.Now().UnixNano(), 10)
+	t.Cleanup(func() { rdb.Del(ctx, "ratelimit:"+key) })
+	p := ratelimit.Policy{Limit: 3, Window: 600 * time.Millisecond}
+
+	for i := 0; i < 3; i++ {
+		res, err := l.Allow(ctx, key, p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !res.Allowed || res.Remaining != 2-i {
+			t.Fatalf("request %d: allowed=%v remaining=%d, want allowed with %d left", i+1, res.Allowed, res.Remaining, 2-i)
+		}
+	}
+
+	res, err := l.Allow(ctx, key, p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Allowed || res.Remaining != 0 {
+		t.Fatalf("request over the burst allowed=%v remaining=%d", res.Allowed, res.Remaining)
+	}
+	if res.RetryAfter <= 0 || res.RetryAfter > 200*time.Millisecond {
+		t.Fatalf("retry after %v, want up to one interval of 200ms", res.RetryAfter)
+	}
+	if res.ResetAfter <= 0 || res.ResetAfter > p.Window {
+		t.Fatalf("reset after %v, want within the window", res.ResetAfter)
+	}
+
+	// One token comes back per interval
+	time.Sleep(res.RetryAfter + 20*time.Millisecond)
+	if res, err = l.Allow(ctx, key, p); err != nil || !res.Allowed {
+		t.Fatalf("no token after waiting: %v %v", res, err)
+	}
+	if res, err = l.Allow(ctx, key, p); err != nil || res.Allowed {
+		t.Fatalf("more than one token after one interval: %v %v", res, err)
+	}
+}
+
+func TestRateLimiterKeysAreIndependent(t *testing.T) {
+	testRedis(t)
+	l := ratelimit.New(rdb)
+	suffix := strconv.FormatInt(time.Now().UnixNano(), 10)
+	a, b := "test:a:"+suffix, "test:b:"+suffix
+	t.Cleanup(func() { rdb.Del(ctx, "ratelimit:"+a, "ratelimit:"+b) })
+	p := ratelimit.Policy{Limit: 1, Window: time.Minute}
+
+	if res, err := l.Allow(ctx, a, p); err != nil || !res.Allowed {
+		t.Fatalf("first request for a refused: %v", err)
+	}
+	if res, err := l.Allow(ctx, a, p); err != nil || res.Allowed {
+		t.Fatalf("second request for a allowed: %v", err)
+	}
+	if res, err := l.Allow(ctx, b, p); err != nil || !res.Allowed {
+		t.Fatalf("b was limited by a's requests: %v", err)
+	}
+}
+
+// fakeAllower allows the first Limit requests per key and refuses the rest
+type fakeAllower struct {
+	counts   map[string]int
+	policies map[string]ratelimit.Policy
+	err      error
+}
+
+func (f *fakeAllower) Allow(_ context.Context, key string, p ratelimit.Policy) (*ratelimit.Result, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	f.counts[key]++
+	f.policies[key] = p
+	n := f.counts[key]
+	if n > p.Limit {
+		return &ratelimit.Result{Limit: p.Limit, RetryAfter: 1500 * time.Millisecond, ResetAfter: p.Window}, nil
+	}
+	return &ratelimit.Result{Allowed: true, Limit: p.Limit, Remaining: p.Limit - n, ResetAfter: 2500 * time.Millisecond}, nil
+}
+
+func newRateLimitRouter(m *ratelimit.Middleware) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	// Stands in for the auth middleware that runs before the limiter
+	setUser := func(c *gin.Context) {
+		if user := c.GetHeader("X-Test-User"); user != "" {
+			c.Set("UserIdentity", user)
+		}
+	}
+	ok := func(c *gin.Context) { c.String(http.StatusOK, "ok") }
+	r.GET("/share/basic/detail", setUser, m.Handle, ok)
+	r.GET("/other", setUser, m.Handle, ok)
+	return r
+}
+
+func rateLimitRequest(r *gin.Engine, path, user string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	req.RemoteAddr = "192.0.2.1:1234"
+	if user != "" {
+		req.Header.Set("X-Test-User", user)
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestRateLimitMiddlewareHeadersAndRefusal(t *testing.T) {
+	fake := &fakeAllower{counts: map[string]int{}, policies: map[string]ratelimit.Policy{}}
+	r := newRateLimitRouter(&ratelimit.Middleware{
+		Limiter: fake,
+		Default: ratelimit.Policy{Limit: 100, Window: time.Minute},
+		Routes: map[string]ratelimit.Policy{
+			"/share/basic/detail": {Limit: 2, Window: time.Minute},
+		},
+	})
+
+	w := rateLimitRequest(r, "/share/basic/detail", "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("first request got %d", w.Code)
+	}
+	for name, want := range map[string]string{
+		"RateLimit-Limit":     "2",
+		"RateLimit-Remaining": "1",
+		"RateLimit-Reset":     "3",
+		"RateLimit-Policy":    "2;w=60",
+	} {
+		if got := w.Header().Get(name); got != want {
+			t.Errorf("%s = %q, want %q", name, got, want)
+		}
+	}
+	if w.Header().Get("Retry-After") != "" {
+		t.Error("Retry-After set on an allowed request")
+	}
+
+	rateLimitRequest(r, "/share/basic/detail", "")
+	w = rateLimitRequest(r, "/share/basic/detail", "")
+	if w.Code != http.StatusTooManyRequests {
+		t.Fatalf("request over the limit got %d", w.Code)
+	}
+	if got := w.Header().Get("Retry-After"); got != "2" {
+		t.Errorf("Retry-After = %q, want 2", got)
+	}
+	if got := w.Header().Get("RateLimit-Remaining"); got != "0" {
+		t.Errorf("RateLimit-Remaining = %q on refusal, want 0", got)
+	}
+	if !strings.Contains(w.Body.String(), "try again in 2 seconds") {
+		t.Errorf("body %q", w.Body.String())
+	}
+}
+
+func TestRateLimitMiddlewareKeys(t *testing.T) {
+	fake := &fakeAllower{counts: map[string]int{}, policies: map[string]ratelimit.Policy{}}
+	paidUser := &fakeSQL{query: func(q string, args []driver.NamedValue) ([]string, [][]driver.Value) {
+		if strings.Contains(q, "FROM `storage_orders`") && args[0].Value == "user-paid" {
+			return []string{"count"}, [][]driver.Value{{int64(1)}}
+		}
+		return []string{"count"}, [][]driver.Value{{int64(0)}}
+	}}
+	r := newRateLimitRouter(&ratelimit.Middleware{
+		DB:      newFakeGorm(t, paidUser),
+		Limiter: fake,
+		Default: ratelimit.Policy{Limit: 100, Window: time.Minute},
+		Routes: map[string]ratelimit.Policy{
+			"/share/basic/detail": {Limit: 2, Window: time.Minute, Plans: map[string]int{define.RatePlanPaid: 8}},
+		},
+	})
+
+	rateLimitRequest(r, "/share/basic/detail", "")
+	rateLimitRequest(r, "/share/basic/detail", "user-free")
+	rateLimitRequest(r, "/share/basic/detail", "user-paid")
+	rateLimitRequest(r, "/other", "")
+
+	want := map[string]int{
+		"/share/basic/detail:ip:192.0.2.1":   2,
+		"/share/basic/detail:user:user-free": 2,
+		"/share/basic/detail:user:user-paid": 8,
+		"*:ip:192.0.2.1":                     100,
+	}
+	for key, limit := range want {
+		p, ok := fake.policies[key]
+		if !ok {
+			t.Errorf("no request counted under %s; keys: %v", key, fake.policies)
+			continue
+		}
+		if p.Limit != limit {
+			t.Errorf("%s limited to %d, want %d", key, p.Limit, limit)
+		}
+	}
+}
+
+func TestRateLimitMiddlewareFailsOpen(t *testing.T) {
+	r := newRateLimitRouter(&ratelimit.Middleware{
+		Limiter: &fakeAllower{err: errors.New("redis is down")},
+		Default: ratelimit.Policy{Limit: 1, Window: time.Minute},
+	})
+	for i := 0; i < 3; i++ {
+		if w := rateLimitRequest(r, "/other", ""); w.Code != http.StatusOK {
+			t.Fatalf("request %d got %d while the limiter was failing", i+1, w.Code)
+		}
+	}
+}
+
+func TestRateLimitKeyIgnoresForwardedForFromUntrustedPeers(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	for _, tc := range []struct {
+		name    string
+		proxies []string
+		wantKey string
+	}{
+		{"no trusted proxies", nil, "*:ip:192.0.2.1"},
+		{"request from a trusted proxy", []string{"192.0.2.0/24"}, "*:ip:203.0.113.9"},
+	} {
+		fake := &fakeAllower{counts: map[string]int{}, policies: map[string]ratelimit.Policy{}}
+		m := &ratelimit.Middleware{Limiter: fake, Default: ratelimit.Policy{Limit: 100, Window: time.Minute}}
+		r, err := router.NewEngine(tc.proxies)
+		if err != nil {
+			t.Fatal(err)
+		}
+		r.GET("/other", m.Handle, func(c *gin.Context) { c.String(http.StatusOK, "ok") })
+
+		req := httptest.NewRequest(http.MethodGet, "/other", nil)
+		req.RemoteAddr = "192.0.2.1:1234"
+		req.Header.Set("X-Forwarded-For", "203.0.113.9")
+		r.ServeHTTP(httptest.NewRecorder(), req)
+
+		if _, ok := fake.policies[tc.wantKey]; !ok || len(fake.policies) != 1 {
+			t.Errorf("%s: counted under %v, want %s", tc.name, fake.policies, tc.wantKey)
+		}
+	}
+}
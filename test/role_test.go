@@ -0,0 +1,26 @@
+package test
+
+import (
+	"testing"
+
+	"cloud-dist/core/define"
+)
+
+func TestUserRoleAllows(t *testing.T) {
+	cases := []struct {
+		role, required string
+		want           bool
+	}{
+		{define.UserRoleAdmin, define.UserRoleSupport, true},
+		{define.UserRoleSupport, define.UserRoleSupport, true},
+		{define.UserRoleSupport, define.UserRoleAdmin, false},
+		{define.UserRoleUser, define.UserRoleSupport, false},
+		{"", define.UserRoleUser, false},
+		{"root", define.UserRoleUser, false},
+	}
+	for _, c := range cases {
+		if got := define.UserRoleAllows(c.role, c.required); got != c.want {
+			t.Errorf("UserRoleAllows(%q, %q) = %v, want %v", c.role, c.required, got, c.want)
+		}
+	}
+}
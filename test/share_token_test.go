@@ -0,0 +1,28 @@
+package test
+
+import (
+	"testing"
+	"time"
+
+	"cloud-dist/core/define"
+	"cloud-dist/core/helper"
+)
+
+func TestShareTokenRoundTrip(t *testing.T) {
+	define.ShareDownloadTokenSecret = "test-secret"
+	token := helper.SignShareToken("share-1", time.Now().Add(time.Minute))
+	if err := helper.VerifyShareToken("share-1", token); err != nil {
+		t.Fatalf("valid token rejected: %v", err)
+	}
+	if err := helper.VerifyShareToken("share-2", token); err == nil {
+		t.Fatal("token accepted for a different share")
+	}
+}
+
+func TestShareTokenExpired(t *testing.T) {
+	define.ShareDownloadTokenSecret = "test-secret"
+	token := helper.SignShareToken("share-1", time.Now().Add(-time.Second))
+	if err := helper.VerifyShareToken("share-1", token); err == nil {
+		t.Fatal("expired token accepted")
+	}
+}
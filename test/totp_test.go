@@ -0,0 +1,58 @@
+package test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"cloud-dist/core/helper"
+)
+
+// base32 of the RFC 6238 SHA1 test key "12345678901234567890"
+const rfc6238Secret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func TestTOTPCodeRFC6238(t *testing.T) {
+	// RFC 6238 appendix B, truncated to six digits
+	vectors := map[int64]string{
+		59:          "287082",
+		1111111109:  "081804",
+		1111111111:  "050471",
+		1234567890:  "005924",
+		2000000000:  "279037",
+		20000000000: "353130",
+	}
+	for unix, want := range vectors {
+		got, err := helper.TOTPCode(rfc6238Secret, helper.TOTPCounter(time.Unix(unix, 0)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != want {
+			t.Errorf("T=%d: got %s, want %s", unix, got, want)
+		}
+	}
+}
+
+func TestTOTPVerifySkew(t *testing.T) {
+	now := time.Unix(1111111111, 0)
+	code, _ := helper.TOTPCode(rfc6238Secret, helper.TOTPCounter(now)-1)
+	if _, ok := helper.TOTPVerify(rfc6238Secret, code, now); !ok {
+		t.Fatal("code from the previous step rejected")
+	}
+	if _, ok := helper.TOTPVerify(rfc6238Secret, code, now.Add(3*helper.TOTPPeriod*time.Second)); ok {
+		t.Fatal("stale code accepted")
+	}
+}
+
+func TestRecoveryCodeHash(t *testing.T) {
+	codes, err := helper.RecoveryCodes(10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(codes) != 10 {
+		t.Fatalf("got %d codes", len(codes))
+	}
+	c := codes[0]
+	if helper.HashRecoveryCode(c) != helper.HashRecoveryCode(strings.ToUpper(strings.ReplaceAll(c, "-", ""))) {
+		t.Fatal("hash depends on case or dashes")
+	}
+}
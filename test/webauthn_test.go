@@ -0,0 +1,181 @@
+package test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"cloud-dist/core/webauthn"
+)
+
+// softAuthenticator is a software passkey: one P-256 key and a counter
+type softAuthenticator struct {
+	rpID    string
+	origin  string
+	id      []byte
+	key     *ecdsa.PrivateKey
+	counter uint32
+	flags   byte
+}
+
+func newSoftAuthenticator(t *testing.T, rpID, origin string) *softAuthenticator {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &softAuthenticator{rpID: rpID, origin: origin, id: []byte("cred-1"), key: key, flags: 0x01 | 0x04}
+}
+
+// cborHead encodes a CBOR major type with a small argument
+func cborHead(major byte, n int) []byte {
+	switch {
+	case n < 24:
+		return []byte{major<<5 | byte(n)}
+	case n < 256:
+		return []byte{major<<5 | 24, byte(n)}
+	default:
+		return []byte{major<<5 | 25, byte(n >> 8), byte(n)}
+	}
+}
+
+func cborInt(n int) []byte {
+	if n < 0 {
+		return cborHead(1, -1-n)
+	}
+	return cborHead(0, n)
+}
+
+func cborBytes(b []byte) []byte {
+	return append(cborHead(2, len(b)), b...)
+}
+
+func cborText(s string) []byte {
+	return append(cborHead(3, len(s)), s...)
+}
+
+func (a *softAuthenticator) coseKey() []byte {
+	x := a.key.PublicKey.X.FillBytes(make([]byte, 32))
+	y := a.key.PublicKey.Y.FillBytes(make([]byte, 32))
+	out := cborHead(5, 5)
+	out = append(append(out, cborInt(1)...), cborInt(2)...)    // kty: EC2
+	out = append(append(out, cborInt(3)...), cborInt(-7)...)   // alg: ES256
+	out = append(append(out, cborInt(-1)...), cborInt(1)...)   // crv: P-256
+	out = append(append(out, cborInt(-2)...), cborBytes(x)...) // x
+	out = append(append(out, cborInt(-3)...), cborBytes(y)...) // y
+	return out
+}
+
+func (a *softAuthenticator) authData(attested bool) []byte {
+	rpHash := sha256.Sum256([]byte(a.rpID))
+	out := append([]byte(nil), rpHash[:]...)
+	flags := a.flags
+	if attested {
+		flags |= 0x40
+	}
+	out = append(out, flags)
+	out = binary.BigEndian.AppendUint32(out, a.counter)
+	if attested {
+		out = append(out, make([]byte, 16)...) // AAGUID
+		out = binary.BigEndian.AppendUint16(out, uint16(len(a.id)))
+		out = append(out, a.id...)
+		out = append(out, a.coseKey()...)
+	}
+	return out
+}
+
+func (a *softAuthenticator) clientData(typ, challenge string) []byte {
+	b, _ := json.Marshal(map[string]string{"type": typ, "challenge": challenge, "origin": a.origin})
+	return b
+}
+
+func (a *softAuthenticator) create(challenge string) (clientData, attestation []byte) {
+	att := cborHead(5, 3)
+	att = append(append(att, cborText("fmt")...), cborText("none")...)
+	att = append(append(att, cborText("attStmt")...), cborHead(5, 0)...)
+	att = append(append(att, cborText("authData")...), cborBytes(a.authData(true))...)
+	return a.clientData("webauthn.create", challenge), att
+}
+
+func (a *softAuthenticator) get(t *testing.T, challenge string) *webauthn.Assertion {
+	a.counter++
+	cd := a.clientData("webauthn.get", challenge)
+	ad := a.authData(false)
+	hash := sha256.Sum256(cd)
+	digest := sha256.Sum256(append(append([]byte(nil), ad...), hash[:]...))
+	sig, err := ecdsa.SignASN1(rand.Reader, a.key, digest[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &webauthn.Assertion{CredentialID: a.id, ClientDataJSON: cd, AuthenticatorData: ad, Signature: sig}
+}
+
+func TestWebAuthnRegisterAndLogin(t *testing.T) {
+	rp := webauthn.New(webauthn.Config{RPID: "example.com", Origins: []string{"https://app.example.com"}})
+	auth := newSoftAuthenticator(t, "example.com", "https://app.example.com")
+
+	challenge, _ := webauthn.NewChallenge()
+	cd, att := auth.create(challenge)
+	cred, err := rp.VerifyRegistration(challenge, cd, att, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(cred.ID) != "cred-1" || cred.Algorithm != webauthn.AlgES256 || !cred.UserVerified {
+		t.Fatalf("unexpected credential %+v", cred)
+	}
+
+	challenge, _ = webauthn.NewChallenge()
+	count, err := rp.VerifyAssertion(challenge, auth.get(t, challenge), cred.PublicKey, cred.SignCount, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 1 {
+		t.Fatalf("counter = %d", count)
+	}
+
+	// Replaying an older counter looks like a cloned authenticator
+	challenge, _ = webauthn.NewChallenge()
+	if _, err = rp.VerifyAssertion(challenge, auth.get(t, challenge), cred.PublicKey, 5, true); !errors.Is(err, webauthn.ErrCounterRegressed) {
+		t.Fatalf("expected counter error, got %v", err)
+	}
+}
+
+func TestWebAuthnRejectsForeignResponses(t *testing.T) {
+	rp := webauthn.New(webauthn.Config{RPID: "example.com", Origins: []string{"https://app.example.com"}})
+	auth := newSoftAuthenticator(t, "example.com", "https://app.example.com")
+	challenge, _ := webauthn.NewChallenge()
+	cd, att := auth.create(challenge)
+	cred, err := rp.VerifyRegistration(challenge, cd, att, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	other, _ := webauthn.NewChallenge()
+	if _, err = rp.VerifyAssertion(other, auth.get(t, challenge), cred.PublicKey, 0, true); err == nil {
+		t.Error("assertion for another challenge accepted")
+	}
+
+	phish := newSoftAuthenticator(t, "example.com", "https://evil.example.net")
+	phish.key = auth.key
+	if _, err = rp.VerifyAssertion(challenge, phish.get(t, challenge), cred.PublicKey, 0, true); err == nil {
+		t.Error("assertion from another origin accepted")
+	}
+
+	auth.flags = 0x01 // present but not verified
+	if _, err = rp.VerifyAssertion(challenge, auth.get(t, challenge), cred.PublicKey, 0, true); err == nil {
+		t.Error("assertion without user verification accepted")
+	}
+	if _, err = rp.VerifyAssertion(challenge, auth.get(t, challenge), cred.PublicKey, 0, false); err != nil {
+		t.Errorf("presence-only assertion rejected for second factor: %v", err)
+	}
+
+	a := auth.get(t, challenge)
+	a.Signature[len(a.Signature)-1] ^= 0xff
+	if _, err = rp.VerifyAssertion(challenge, a, cred.PublicKey, 0, false); err == nil {
+		t.Error("tampered signature accepted")
+	}
+}